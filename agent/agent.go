@@ -0,0 +1,77 @@
+// Package agent implements an in-memory SLH-DSA signing agent and its client,
+// speaking a length-prefixed protocol over a Unix domain socket that is
+// modelled on ssh-agent (draft-miller-ssh-agent).
+package agent
+
+import (
+	"errors"
+	"time"
+
+	"github.com/skuuzie/go-slhdsa/ssh"
+)
+
+var (
+	ErrLocked      = errors.New("agent: agent is locked")
+	ErrKeyNotFound = errors.New("agent: key not found")
+	ErrRefused     = errors.New("agent: request refused")
+	ErrBadPassword = errors.New("agent: incorrect passphrase")
+)
+
+// Identity held by the agent
+type Key struct {
+	// SSH wire encoding of the public key
+	Blob []byte
+
+	// SHA256 fingerprint as printed by ssh-keygen
+	Fingerprint string
+
+	Comment string
+}
+
+// Private key with its usage constraints
+type AddedKey struct {
+	PrivateKey *ssh.PrivateKey
+
+	// Evict the key after this duration, kept forever when zero
+	Lifetime time.Duration
+
+	// Ask for confirmation before every signature
+	Confirm bool
+
+	// Evict the key after this many signatures, unlimited when zero
+	MaxSignatures uint32
+}
+
+// Operations offered by both the in-memory keyring and the socket client
+type Agent interface {
+	// List the identities, empty while locked
+	List() ([]Key, error)
+
+	// Sign data with the key of the given wire encoding, returns an SSH signature blob
+	Sign(key, data []byte) ([]byte, error)
+
+	Add(key AddedKey) error
+	Remove(key []byte) error
+	RemoveAll() error
+
+	// Refuse all operations except Unlock until unlocked with the same passphrase
+	Lock(passphrase []byte) error
+	Unlock(passphrase []byte) error
+}
+
+// Sign with the key of the given SHA256 fingerprint
+func SignWithFingerprint(a Agent, fingerprint string, data []byte) ([]byte, error) {
+	keys, err := a.List()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		if k.Fingerprint == fingerprint {
+			return a.Sign(k.Blob, data)
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
@@ -0,0 +1,245 @@
+package agent_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/agent"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+	"github.com/skuuzie/go-slhdsa/ssh"
+)
+
+func newKey(t *testing.T) (*ssh.PrivateKey, *ssh.PublicKey) {
+	paramSet := slhdsa.ParameterSet.SLHDSA_SHA2_128f
+	sk, _ := testkey.New(t, paramSet)
+
+	k := &ssh.PrivateKey{ParameterSet: paramSet, Key: sk, Comment: "user@host"}
+	pub, err := k.Public()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k, pub
+}
+
+// Client connected to a keyring served over an in-memory pipe
+func pipeClient(t *testing.T, keyring *agent.Keyring) agent.Agent {
+	c1, c2 := net.Pipe()
+	t.Cleanup(func() { c1.Close(); c2.Close() })
+
+	go agent.ServeAgent(keyring, c2)
+
+	return agent.NewClient(c1)
+}
+
+func TestSignOverSocket(t *testing.T) {
+	k, pub := newKey(t)
+	path := filepath.Join(t.TempDir(), "agent.sock")
+
+	go agent.ListenAndServe(path, &agent.Keyring{})
+
+	var (
+		a      agent.Agent
+		closer interface{ Close() error }
+		err    error
+	)
+
+	for range 50 {
+		if a, closer, err = agent.Dial(path); err == nil {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer closer.Close()
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("socket mode %v", info.Mode())
+	}
+
+	if err := a.Add(agent.AddedKey{PrivateKey: k}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := a.List()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 1 || keys[0].Fingerprint != pub.Fingerprint() || keys[0].Comment != "user@host" {
+		t.Fatalf("unexpected keys %+v", keys)
+	}
+
+	msg := []byte("message")
+	sig, err := agent.SignWithFingerprint(a, pub.Fingerprint(), msg)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pub.Verify(msg, sig); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := agent.SignWithFingerprint(a, "SHA256:unknown", msg); !errors.Is(err, agent.ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	if err := a.Remove(pub.Marshal()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Sign(pub.Marshal(), msg); err == nil {
+		t.Error("signed with a removed key")
+	}
+}
+
+func TestLock(t *testing.T) {
+	k, pub := newKey(t)
+	a := pipeClient(t, &agent.Keyring{})
+
+	if err := a.Add(agent.AddedKey{PrivateKey: k}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Lock([]byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	if keys, _ := a.List(); len(keys) != 0 {
+		t.Error("keys listed while locked")
+	}
+
+	if _, err := a.Sign(pub.Marshal(), []byte("message")); err == nil {
+		t.Error("signed while locked")
+	}
+
+	if err := a.Unlock([]byte("wrong")); err == nil {
+		t.Error("unlocked with a wrong passphrase")
+	}
+
+	if err := a.Unlock([]byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Sign(pub.Marshal(), []byte("message")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLifetime(t *testing.T) {
+	k, pub := newKey(t)
+	now := time.Now()
+	keyring := &agent.Keyring{Now: func() time.Time { return now }}
+	a := pipeClient(t, keyring)
+
+	if err := a.Add(agent.AddedKey{PrivateKey: k, Lifetime: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Sign(pub.Marshal(), []byte("message")); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Hour)
+
+	if keys, _ := a.List(); len(keys) != 0 {
+		t.Error("expired key still listed")
+	}
+
+	if _, err := keyring.Sign(pub.Marshal(), []byte("message")); !errors.Is(err, agent.ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	// Lifetimes under a second are rounded up rather than sent as none
+	if err := a.Add(agent.AddedKey{PrivateKey: k, Lifetime: 500 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Second)
+
+	if keys, _ := a.List(); len(keys) != 0 {
+		t.Error("key with a sub-second lifetime never expires")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	k, pub := newKey(t)
+	allow := false
+	var asked agent.Key
+	a := pipeClient(t, &agent.Keyring{Confirm: func(k agent.Key) bool { asked = k; return allow }})
+
+	if err := a.Add(agent.AddedKey{PrivateKey: k, Confirm: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Sign(pub.Marshal(), []byte("message")); err == nil {
+		t.Error("signed without confirmation")
+	}
+
+	if asked.Fingerprint != pub.Fingerprint() {
+		t.Errorf("confirmation asked for %q", asked.Fingerprint)
+	}
+
+	allow = true
+
+	if _, err := a.Sign(pub.Marshal(), []byte("message")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMaxSignatures(t *testing.T) {
+	k, pub := newKey(t)
+	keyring := &agent.Keyring{}
+	a := pipeClient(t, keyring)
+
+	if err := a.Add(agent.AddedKey{PrivateKey: k, MaxSignatures: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if _, err := a.Sign(pub.Marshal(), []byte("message")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := keyring.Sign(pub.Marshal(), []byte("message")); !errors.Is(err, agent.ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	// Refused requests do not use up the budget
+	allow := false
+	keyring.Confirm = func(agent.Key) bool { return allow }
+
+	if err := a.Add(agent.AddedKey{PrivateKey: k, Confirm: true, MaxSignatures: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := a.Sign(pub.Marshal(), []byte("message")); err == nil {
+		t.Fatal("signed without confirmation")
+	}
+
+	allow = true
+
+	if _, err := a.Sign(pub.Marshal(), []byte("message")); err != nil {
+		t.Errorf("budget used by a refused request: %v", err)
+	}
+}
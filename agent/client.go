@@ -0,0 +1,149 @@
+package agent
+
+import (
+	"io"
+	"net"
+	"sync"
+
+	"github.com/skuuzie/go-slhdsa/ssh"
+)
+
+// Agent client over a connection to an agent socket
+type client struct {
+	mu   sync.Mutex
+	conn io.ReadWriter
+}
+
+// Create a client speaking to the agent on `conn`
+func NewClient(conn io.ReadWriter) Agent {
+	return &client{conn: conn}
+}
+
+// Connect to the agent listening on a Unix socket
+func Dial(path string) (Agent, io.Closer, error) {
+	conn, err := net.Dial("unix", path)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return NewClient(conn), conn, nil
+}
+
+func (c *client) call(req []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := writeMessage(c.conn, req); err != nil {
+		return nil, err
+	}
+
+	reply, err := readMessage(c.conn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reply[0] == msgFailure {
+		return nil, ErrRefused
+	}
+
+	return reply, nil
+}
+
+func (c *client) simpleCall(req []byte) error {
+	reply, err := c.call(req)
+
+	if err != nil {
+		return err
+	}
+
+	if reply[0] != msgSuccess {
+		return ErrMalformed
+	}
+
+	return nil
+}
+
+func (c *client) List() ([]Key, error) {
+	reply, err := c.call([]byte{msgRequestIdentities})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reply[0] != msgIdentitiesAnswer {
+		return nil, ErrMalformed
+	}
+
+	r := &reader{data: reply[1:]}
+	n := r.uint32()
+
+	var keys []Key
+
+	for range n {
+		blob := r.string()
+		comment := string(r.string())
+
+		if r.err != nil {
+			return nil, ErrMalformed
+		}
+
+		k := Key{Blob: blob, Comment: comment}
+
+		if pub, err := ssh.ParsePublicKey(blob); err == nil {
+			k.Fingerprint = pub.Fingerprint()
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+func (c *client) Sign(key, data []byte) ([]byte, error) {
+	req := appendString([]byte{msgSignRequest}, key)
+	req = appendString(req, data)
+	req = append(req, 0, 0, 0, 0)
+
+	reply, err := c.call(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{data: reply[1:]}
+	sig := r.string()
+
+	if reply[0] != msgSignResponse || r.err != nil {
+		return nil, ErrMalformed
+	}
+
+	return sig, nil
+}
+
+func (c *client) Add(key AddedKey) error {
+	req, err := marshalAddedKey(key)
+
+	if err != nil {
+		return err
+	}
+
+	return c.simpleCall(req)
+}
+
+func (c *client) Remove(key []byte) error {
+	return c.simpleCall(appendString([]byte{msgRemoveIdentity}, key))
+}
+
+func (c *client) RemoveAll() error {
+	return c.simpleCall([]byte{msgRemoveAll})
+}
+
+func (c *client) Lock(passphrase []byte) error {
+	return c.simpleCall(appendString([]byte{msgLock}, passphrase))
+}
+
+func (c *client) Unlock(passphrase []byte) error {
+	return c.simpleCall(appendString([]byte{msgUnlock}, passphrase))
+}
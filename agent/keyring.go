@@ -0,0 +1,249 @@
+package agent
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"crypto/subtle"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/skuuzie/go-slhdsa/ssh"
+)
+
+type privKey struct {
+	key      *ssh.PrivateKey
+	pub      *ssh.PublicKey
+	blob     []byte
+	expires  time.Time
+	confirm  bool
+	maxSigs  uint32
+	sigCount uint32
+}
+
+// In-memory Agent
+type Keyring struct {
+	// Asked before signing with a key added with the Confirm constraint,
+	// confirmation is refused when nil
+	Confirm func(k Key) bool
+
+	// Clock used for lifetimes, defaults to time.Now
+	Now func() time.Time
+
+	mu     sync.Mutex
+	keys   []*privKey
+	locked bool
+	passwd [sha512.Size]byte
+}
+
+func (r *Keyring) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+
+	return time.Now()
+}
+
+// Drop expired keys, called with the lock held
+func (r *Keyring) evict() {
+	now := r.now()
+	kept := r.keys[:0]
+
+	for _, k := range r.keys {
+		if !k.expires.IsZero() && !now.Before(k.expires) {
+			continue
+		}
+
+		kept = append(kept, k)
+	}
+
+	clear(r.keys[len(kept):])
+	r.keys = kept
+}
+
+func (r *Keyring) find(blob []byte) int {
+	for i, k := range r.keys {
+		if bytes.Equal(k.blob, blob) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (r *Keyring) List() ([]Key, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.locked {
+		return nil, nil
+	}
+
+	r.evict()
+
+	out := make([]Key, 0, len(r.keys))
+	for _, k := range r.keys {
+		out = append(out, Key{Blob: k.blob, Fingerprint: k.pub.Fingerprint(), Comment: k.key.Comment})
+	}
+
+	return out, nil
+}
+
+func (r *Keyring) Sign(key, data []byte) ([]byte, error) {
+	r.mu.Lock()
+
+	if r.locked {
+		r.mu.Unlock()
+		return nil, ErrLocked
+	}
+
+	r.evict()
+
+	i := r.find(key)
+	if i < 0 {
+		r.mu.Unlock()
+		return nil, ErrKeyNotFound
+	}
+
+	k := r.keys[i]
+	r.mu.Unlock()
+
+	// Confirmation may block on user interaction, do not hold the lock
+	if k.confirm && (r.Confirm == nil || !r.Confirm(Key{Blob: k.blob, Fingerprint: k.pub.Fingerprint(), Comment: k.key.Comment})) {
+		return nil, ErrRefused
+	}
+
+	// Only confirmed signatures count, the key may have gone meanwhile
+	r.mu.Lock()
+
+	if r.locked {
+		r.mu.Unlock()
+		return nil, ErrLocked
+	}
+
+	r.evict()
+
+	i = slices.Index(r.keys, k)
+	if i < 0 {
+		r.mu.Unlock()
+		return nil, ErrKeyNotFound
+	}
+
+	if k.maxSigs != 0 {
+		k.sigCount++
+
+		if k.sigCount >= k.maxSigs {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+		}
+	}
+
+	r.mu.Unlock()
+
+	return k.key.Sign(data)
+}
+
+func (r *Keyring) Add(key AddedKey) error {
+	pub, err := key.PrivateKey.Public()
+
+	if err != nil {
+		return err
+	}
+
+	k := &privKey{
+		key:     key.PrivateKey,
+		pub:     pub,
+		blob:    pub.Marshal(),
+		confirm: key.Confirm,
+		maxSigs: key.MaxSignatures,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.locked {
+		return ErrLocked
+	}
+
+	if key.Lifetime > 0 {
+		k.expires = r.now().Add(key.Lifetime)
+
+		// Also evict on a timer so the key does not linger in memory while idle
+		time.AfterFunc(key.Lifetime, func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.evict()
+		})
+	}
+
+	if i := r.find(k.blob); i >= 0 {
+		r.keys[i] = k
+	} else {
+		r.keys = append(r.keys, k)
+	}
+
+	return nil
+}
+
+func (r *Keyring) Remove(key []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.locked {
+		return ErrLocked
+	}
+
+	i := r.find(key)
+	if i < 0 {
+		return ErrKeyNotFound
+	}
+
+	r.keys = append(r.keys[:i], r.keys[i+1:]...)
+
+	return nil
+}
+
+func (r *Keyring) RemoveAll() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.locked {
+		return ErrLocked
+	}
+
+	r.keys = nil
+
+	return nil
+}
+
+func (r *Keyring) Lock(passphrase []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.locked {
+		return ErrLocked
+	}
+
+	r.locked = true
+	r.passwd = sha512.Sum512(passphrase)
+
+	return nil
+}
+
+func (r *Keyring) Unlock(passphrase []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.locked {
+		return ErrRefused
+	}
+
+	h := sha512.Sum512(passphrase)
+	if subtle.ConstantTimeCompare(h[:], r.passwd[:]) != 1 {
+		return ErrBadPassword
+	}
+
+	r.locked = false
+	r.passwd = [sha512.Size]byte{}
+
+	return nil
+}
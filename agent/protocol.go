@@ -0,0 +1,219 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/ssh"
+)
+
+// Message numbers (draft-miller-ssh-agent Section 6.1)
+const (
+	msgFailure           = 5
+	msgSuccess           = 6
+	msgRequestIdentities = 11
+	msgIdentitiesAnswer  = 12
+	msgSignRequest       = 13
+	msgSignResponse      = 14
+	msgAddIdentity       = 17
+	msgRemoveIdentity    = 18
+	msgRemoveAll         = 19
+	msgLock              = 22
+	msgUnlock            = 23
+	msgAddIDConstrained  = 25
+)
+
+// Key constraints
+const (
+	constrainLifetime  = 1
+	constrainConfirm   = 2
+	constrainExtension = 255
+)
+
+// Extension constraint limiting the number of signatures
+const extMaxSignatures = "max-signatures@go-slhdsa"
+
+// Largest accepted message, SLH-DSA-256f signatures are about 50 KB
+const maxMessageSize = 1 << 20
+
+var ErrMalformed = errors.New("agent: malformed message")
+
+func readMessage(r io.Reader) ([]byte, error) {
+	var hdr [4]byte
+
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(hdr[:])
+	if n == 0 || n > maxMessageSize {
+		return nil, ErrMalformed
+	}
+
+	msg := make([]byte, n)
+
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func writeMessage(w io.Writer, msg []byte) error {
+	_, err := w.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(msg))), msg...))
+	return err
+}
+
+func appendString(b, s []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// Sequential reader that records the first error
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil || len(r.data) < 1 {
+		r.err = ErrMalformed
+		return 0
+	}
+
+	b := r.data[0]
+	r.data = r.data[1:]
+
+	return b
+}
+
+func (r *reader) uint32() uint32 {
+	if r.err != nil || len(r.data) < 4 {
+		r.err = ErrMalformed
+		return 0
+	}
+
+	v := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+
+	return v
+}
+
+func (r *reader) string() []byte {
+	n := r.uint32()
+
+	if r.err != nil || uint64(n) > uint64(len(r.data)) {
+		r.err = ErrMalformed
+		return nil
+	}
+
+	s := r.data[:n]
+	r.data = r.data[n:]
+
+	return s
+}
+
+// Body of an add identity request, constrained when any constraint is set
+func marshalAddedKey(key AddedKey) ([]byte, error) {
+	pub, err := key.PrivateKey.Public()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var constraints []byte
+
+	if key.Lifetime > 0 {
+		constraints = append(constraints, constrainLifetime)
+		// Whole seconds rounded up, zero would mean no lifetime at all
+		secs := key.Lifetime / time.Second
+		if key.Lifetime%time.Second != 0 {
+			secs++
+		}
+
+		constraints = binary.BigEndian.AppendUint32(constraints, uint32(min(secs, math.MaxUint32)))
+	}
+
+	if key.Confirm {
+		constraints = append(constraints, constrainConfirm)
+	}
+
+	if key.MaxSignatures > 0 {
+		constraints = append(constraints, constrainExtension)
+		constraints = appendString(constraints, []byte(extMaxSignatures))
+		constraints = appendString(constraints, binary.BigEndian.AppendUint32(nil, key.MaxSignatures))
+	}
+
+	msg := []byte{msgAddIdentity}
+	if constraints != nil {
+		msg[0] = msgAddIDConstrained
+	}
+
+	msg = appendString(msg, []byte(pub.Type()))
+	msg = appendString(msg, pub.Key.KeyBytes)
+	msg = appendString(msg, key.PrivateKey.Key.KeyBytes)
+	msg = appendString(msg, []byte(key.PrivateKey.Comment))
+
+	return append(msg, constraints...), nil
+}
+
+func parseAddedKey(r *reader, constrained bool) (AddedKey, error) {
+	keyType := string(r.string())
+	pk := r.string()
+	sk := r.string()
+	comment := string(r.string())
+
+	if r.err != nil {
+		return AddedKey{}, ErrMalformed
+	}
+
+	paramSet, err := ssh.ParameterSet(keyType)
+
+	if err != nil {
+		return AddedKey{}, err
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	priv, err := ctx.GetPrivateKeyFromBytes(sk)
+
+	if err != nil {
+		return AddedKey{}, err
+	}
+
+	if !bytes.Equal(sk[len(sk)/2:], pk) {
+		return AddedKey{}, ErrMalformed
+	}
+
+	key := AddedKey{PrivateKey: &ssh.PrivateKey{ParameterSet: paramSet, Key: priv, Comment: comment}}
+
+	for constrained && len(r.data) > 0 {
+		switch r.byte() {
+		case constrainLifetime:
+			key.Lifetime = time.Duration(r.uint32()) * time.Second
+		case constrainConfirm:
+			key.Confirm = true
+		case constrainExtension:
+			name := string(r.string())
+			data := r.string()
+
+			if name != extMaxSignatures || len(data) != 4 {
+				return AddedKey{}, ErrMalformed
+			}
+
+			key.MaxSignatures = binary.BigEndian.Uint32(data)
+		default:
+			return AddedKey{}, ErrMalformed
+		}
+	}
+
+	if r.err != nil || len(r.data) != 0 {
+		return AddedKey{}, ErrMalformed
+	}
+
+	return key, nil
+}
@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// Serve agent requests on a connection until it is closed
+func ServeAgent(a Agent, rw io.ReadWriter) error {
+	for {
+		msg, err := readMessage(rw)
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		reply := handle(a, msg)
+
+		if err := writeMessage(rw, reply); err != nil {
+			return err
+		}
+	}
+}
+
+func status(err error) []byte {
+	if err != nil {
+		return []byte{msgFailure}
+	}
+
+	return []byte{msgSuccess}
+}
+
+func handle(a Agent, msg []byte) []byte {
+	r := &reader{data: msg[1:]}
+
+	switch msg[0] {
+	case msgRequestIdentities:
+		keys, err := a.List()
+
+		if err != nil {
+			return status(err)
+		}
+
+		reply := binary.BigEndian.AppendUint32([]byte{msgIdentitiesAnswer}, uint32(len(keys)))
+		for _, k := range keys {
+			reply = appendString(reply, k.Blob)
+			reply = appendString(reply, []byte(k.Comment))
+		}
+
+		return reply
+
+	case msgSignRequest:
+		key := r.string()
+		data := r.string()
+		r.uint32()
+
+		if r.err != nil {
+			return status(r.err)
+		}
+
+		sig, err := a.Sign(key, data)
+
+		if err != nil {
+			return status(err)
+		}
+
+		return appendString([]byte{msgSignResponse}, sig)
+
+	case msgAddIdentity, msgAddIDConstrained:
+		key, err := parseAddedKey(r, msg[0] == msgAddIDConstrained)
+
+		if err != nil {
+			return status(err)
+		}
+
+		return status(a.Add(key))
+
+	case msgRemoveIdentity:
+		key := r.string()
+
+		if r.err != nil {
+			return status(r.err)
+		}
+
+		return status(a.Remove(key))
+
+	case msgRemoveAll:
+		return status(a.RemoveAll())
+
+	case msgLock, msgUnlock:
+		passphrase := r.string()
+
+		if r.err != nil {
+			return status(r.err)
+		}
+
+		if msg[0] == msgLock {
+			return status(a.Lock(passphrase))
+		}
+
+		return status(a.Unlock(passphrase))
+	}
+
+	return status(ErrMalformed)
+}
+
+// Listen on a Unix socket readable only by the current user and serve each connection
+//
+// The socket is bound in a fresh private directory and only moved to `path`
+// once its mode is 0600, so other users never see it with umask permissions.
+// Blocks until the listener fails, the socket file is removed on return.
+func ListenAndServe(path string, a Agent) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".slhdsa-agent-")
+
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(dir)
+
+	l, err := net.Listen("unix", filepath.Join(dir, "agent.sock"))
+
+	if err != nil {
+		return err
+	}
+
+	defer l.Close()
+
+	if err := os.Chmod(filepath.Join(dir, "agent.sock"), 0o600); err != nil {
+		return err
+	}
+
+	if err := os.Rename(filepath.Join(dir, "agent.sock"), path); err != nil {
+		return err
+	}
+
+	defer os.Remove(path)
+
+	return Serve(l, a)
+}
+
+// Serve each connection accepted on the listener
+func Serve(l net.Listener, a Agent) error {
+	for {
+		conn, err := l.Accept()
+
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+			ServeAgent(a, conn)
+		}()
+	}
+}
@@ -0,0 +1,168 @@
+// Package batch signs many messages with a single SLH-DSA signature over the
+// root of a Merkle tree, giving each message a compact inclusion proof.
+//
+// Leaves and nodes are hashed with SHA-256 and domain separated as in
+// RFC 6962. The signed root is bound to the tree size.
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/internal/merkle"
+)
+
+// SLH-DSA context binding signatures to batch roots
+var signatureContext = []byte("go-slhdsa batch v1")
+
+// Largest audit path, enough for 2^64 leaves
+const maxPathLength = 64
+
+var (
+	ErrEmptyBatch       = errors.New("batch: no messages to sign")
+	ErrMalformedProof   = errors.New("batch: malformed proof")
+	ErrInvalidProof     = errors.New("batch: message not included in the signed root")
+	ErrInvalidSignature = errors.New("batch: invalid root signature")
+)
+
+// Inclusion proof of one message in a signed batch
+type Proof struct {
+	// Position of the message in the batch
+	Index uint64
+
+	// Number of messages in the batch
+	TreeSize uint64
+
+	// Sibling hashes from the leaf up to the root
+	Path [][]byte
+
+	// SLH-DSA signature over the root, shared by every proof of the batch
+	RootSignature []byte
+}
+
+// Message signed by SLH-DSA, the tree size followed by the root hash
+func signedRoot(size uint64, root merkle.Hash) []byte {
+	return append(binary.BigEndian.AppendUint64(nil, size), root[:]...)
+}
+
+// Sign a batch of messages, returns the proof of each message in order
+func SignBatch(paramSet string, sk slhdsa.PrivateKey, messages [][]byte) ([]*Proof, error) {
+	ctx, err := slhdsa.New(paramSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(messages) == 0 {
+		return nil, ErrEmptyBatch
+	}
+
+	leaves := make([]merkle.Hash, len(messages))
+	for i, m := range messages {
+		leaves[i] = merkle.LeafHash(m)
+	}
+
+	paths := make([][]merkle.Hash, len(messages))
+	root := tree(leaves, paths)
+	size := uint64(len(messages))
+
+	sig, err := ctx.GenerateSignature(sk, signedRoot(size, root), signatureContext, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]*Proof, len(messages))
+	for i := range messages {
+		path := make([][]byte, len(paths[i]))
+		for j, h := range paths[i] {
+			path[j] = h[:]
+		}
+
+		proofs[i] = &Proof{Index: uint64(i), TreeSize: size, Path: path, RootSignature: sig}
+	}
+
+	return proofs, nil
+}
+
+// Verify that the message is part of a batch signed by the public key
+func Verify(paramSet string, pk slhdsa.PublicKey, message []byte, p *Proof) error {
+	ctx, err := slhdsa.New(paramSet)
+
+	if err != nil {
+		return err
+	}
+
+	path := make([]merkle.Hash, len(p.Path))
+	for i, h := range p.Path {
+		if len(h) != sha256.Size {
+			return ErrMalformedProof
+		}
+
+		path[i] = merkle.Hash(h)
+	}
+
+	root, ok := merkle.RootFromInclusionProof(merkle.LeafHash(message), p.Index, p.TreeSize, path)
+
+	if !ok {
+		return ErrInvalidProof
+	}
+
+	valid, err := ctx.VerifySignature(pk, signedRoot(p.TreeSize, root), p.RootSignature, signatureContext, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil || !valid {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Binary encoding: index, tree size, path length as uint64/uint64/uint8,
+// the path hashes, then the root signature
+func (p *Proof) MarshalBinary() ([]byte, error) {
+	if len(p.Path) > maxPathLength {
+		return nil, ErrMalformedProof
+	}
+
+	out := binary.BigEndian.AppendUint64(nil, p.Index)
+	out = binary.BigEndian.AppendUint64(out, p.TreeSize)
+	out = append(out, byte(len(p.Path)))
+
+	for _, h := range p.Path {
+		if len(h) != sha256.Size {
+			return nil, ErrMalformedProof
+		}
+
+		out = append(out, h...)
+	}
+
+	return append(out, p.RootSignature...), nil
+}
+
+func (p *Proof) UnmarshalBinary(data []byte) error {
+	if len(data) < 17 {
+		return ErrMalformedProof
+	}
+
+	n := int(data[16])
+	rest := data[17:]
+
+	if n > maxPathLength || len(rest) < n*sha256.Size {
+		return ErrMalformedProof
+	}
+
+	p.Index = binary.BigEndian.Uint64(data)
+	p.TreeSize = binary.BigEndian.Uint64(data[8:])
+	p.Path = make([][]byte, n)
+
+	for i := range p.Path {
+		p.Path[i] = append([]byte{}, rest[:sha256.Size]...)
+		rest = rest[sha256.Size:]
+	}
+
+	p.RootSignature = append([]byte{}, rest...)
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package batch_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/batch"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+)
+
+var paramSet = slhdsa.ParameterSet.SLHDSA_SHA2_128f
+
+func messages(n int) [][]byte {
+	out := make([][]byte, n)
+	for i := range out {
+		out[i] = fmt.Appendf(nil, "record %d", i)
+	}
+
+	return out
+}
+
+func TestSignBatch(t *testing.T) {
+	sk, pk := testkey.New(t, paramSet)
+
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		msgs := messages(n)
+		proofs, err := batch.SignBatch(paramSet, sk, msgs)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, p := range proofs {
+			if err := batch.Verify(paramSet, pk, msgs[i], p); err != nil {
+				t.Errorf("size %d, leaf %d: %v", n, i, err)
+			}
+
+			if err := batch.Verify(paramSet, pk, []byte("forged"), p); err == nil {
+				t.Errorf("size %d, leaf %d: verified another message", n, i)
+			}
+		}
+
+		// Moving a proof to another index must fail
+		if n > 1 {
+			moved := *proofs[0]
+			moved.Index = 1
+
+			if err := batch.Verify(paramSet, pk, msgs[0], &moved); err == nil {
+				t.Errorf("size %d: verified at another index", n)
+			}
+
+			resized := *proofs[0]
+			resized.TreeSize++
+
+			if err := batch.Verify(paramSet, pk, msgs[0], &resized); err == nil {
+				t.Errorf("size %d: verified with another tree size", n)
+			}
+		}
+	}
+}
+
+func TestProofEncoding(t *testing.T) {
+	sk, pk := testkey.New(t, paramSet)
+	msgs := messages(6)
+
+	proofs, err := batch.SignBatch(paramSet, sk, msgs)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := proofs[4].MarshalBinary()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var p batch.Proof
+
+	if err := p.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := batch.Verify(paramSet, pk, msgs[4], &p); err != nil {
+		t.Error(err)
+	}
+
+	if err := p.UnmarshalBinary(data[:20]); !errors.Is(err, batch.ErrMalformedProof) {
+		t.Errorf("expected ErrMalformedProof, got %v", err)
+	}
+
+	if _, err := batch.SignBatch(paramSet, sk, nil); !errors.Is(err, batch.ErrEmptyBatch) {
+		t.Errorf("expected ErrEmptyBatch, got %v", err)
+	}
+}
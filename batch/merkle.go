@@ -0,0 +1,25 @@
+package batch
+
+import "github.com/skuuzie/go-slhdsa/internal/merkle"
+
+// Root of the tree over leaf hashes (RFC 6962 Section 2.1), appending to
+// paths[i] the audit path of leaf i ordered from the leaf upwards
+func tree(leaves []merkle.Hash, paths [][]merkle.Hash) merkle.Hash {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+
+	k := int(merkle.Split(uint64(len(leaves))))
+	left := tree(leaves[:k], paths[:k])
+	right := tree(leaves[k:], paths[k:])
+
+	for i := range paths[:k] {
+		paths[i] = append(paths[i], right)
+	}
+
+	for i := range paths[k:] {
+		paths[k+i] = append(paths[k+i], left)
+	}
+
+	return merkle.NodeHash(left, right)
+}
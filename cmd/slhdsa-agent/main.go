@@ -0,0 +1,105 @@
+// Command slhdsa-agent holds SLH-DSA private keys in memory and serves sign
+// requests on a Unix domain socket.
+//
+//	slhdsa-agent [-a socket] [-t lifetime] [-c] [-n max-signatures] [key ...]
+//
+// Each key argument is an openssh-key-v1 private key file loaded at startup.
+// The socket path is printed as SLHDSA_AUTH_SOCK for use by clients. Keys
+// added with the confirm constraint are only used when the program named by
+// SLHDSA_ASKPASS, run with a prompt argument, exits successfully.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/skuuzie/go-slhdsa/agent"
+	"github.com/skuuzie/go-slhdsa/ssh"
+)
+
+func main() {
+	socket := flag.String("a", "", "socket path (default a new private directory in $XDG_RUNTIME_DIR or temp dir)")
+	lifetime := flag.Duration("t", 0, "lifetime of loaded keys, 0 keeps them forever")
+	confirm := flag.Bool("c", false, "confirm each signature with loaded keys through $SLHDSA_ASKPASS")
+	maxSigs := flag.Uint("n", 0, "evict loaded keys after this many signatures, 0 is unlimited")
+	flag.Parse()
+
+	// Wrapping would turn a signing budget into 0, which is unlimited
+	if *maxSigs > math.MaxUint32 {
+		fmt.Fprintf(os.Stderr, "slhdsa-agent: -n must be at most %d\n", uint32(math.MaxUint32))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	keyring := &agent.Keyring{Confirm: askpass}
+
+	for _, path := range flag.Args() {
+		data, err := os.ReadFile(path)
+
+		if err != nil {
+			fatal(err)
+		}
+
+		k, err := ssh.ParsePrivateKey(data)
+
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", path, err))
+		}
+
+		err = keyring.Add(agent.AddedKey{
+			PrivateKey:    k,
+			Lifetime:      *lifetime,
+			Confirm:       *confirm,
+			MaxSignatures: uint32(*maxSigs),
+		})
+
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	// Private directory of the default socket, removed on exit
+	var socketDir string
+
+	if *socket == "" {
+		dir, err := os.MkdirTemp(os.Getenv("XDG_RUNTIME_DIR"), "slhdsa-agent-")
+
+		if err != nil {
+			fatal(err)
+		}
+
+		socketDir = dir
+		*socket = filepath.Join(dir, fmt.Sprintf("agent.%d.sock", os.Getpid()))
+	}
+
+	fmt.Printf("SLHDSA_AUTH_SOCK=%s; export SLHDSA_AUTH_SOCK;\n", *socket)
+
+	err := agent.ListenAndServe(*socket, keyring)
+
+	if socketDir != "" {
+		os.RemoveAll(socketDir)
+	}
+
+	fatal(err)
+}
+
+// Ask the user through the SLHDSA_ASKPASS program, refused when unset
+func askpass(k agent.Key) bool {
+	prog := os.Getenv("SLHDSA_ASKPASS")
+	if prog == "" {
+		return false
+	}
+
+	prompt := fmt.Sprintf("Allow use of key %s %s?", k.Fingerprint, k.Comment)
+
+	return exec.Command(prog, prompt).Run() == nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "slhdsa-agent:", err)
+	os.Exit(1)
+}
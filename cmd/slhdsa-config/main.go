@@ -0,0 +1,223 @@
+// Command slhdsa-config signs and verifies configuration files for
+// package signedconfig, with keys stored as JWK files.
+//
+//	slhdsa-config keygen [-alg parameter-set] -key private.jwk -pub public.jwk
+//	slhdsa-config sign -key private.jwk [-context config:v1] [-embed [-prefix "# "]] file
+//	slhdsa-config verify -pub public.jwk [-pub ...] [-context config:v1] file
+//
+// sign writes file.sig, or with -embed appends the signature block to the
+// file itself. verify prints the signed content.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/jose"
+	"github.com/skuuzie/go-slhdsa/signedconfig"
+)
+
+// Context string of configuration signatures unless overridden
+const defaultContext = "config:v1"
+
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		keygen(os.Args[2:])
+	case "sign":
+		sign(os.Args[2:])
+	case "verify":
+		verify(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: slhdsa-config keygen|sign|verify [flags] ...")
+	os.Exit(2)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "slhdsa-config:", err)
+	os.Exit(1)
+}
+
+func readJWK(path string) *jose.JWK {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	k, err := jose.ParseJWK(data)
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	return k
+}
+
+func writeJWK(path string, k *jose.JWK, mode os.FileMode) {
+	data, err := json.MarshalIndent(k, "", "  ")
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), mode); err != nil {
+		fatal(err)
+	}
+}
+
+func keygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	alg := fs.String("alg", slhdsa.ParameterSet.SLHDSA_SHA2_128s, "SLH-DSA parameter set")
+	keyPath := fs.String("key", "", "private key output")
+	pubPath := fs.String("pub", "", "public key output")
+	fs.Parse(args)
+
+	if *keyPath == "" || *pubPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ctx, err := slhdsa.New(*alg)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	sk, _, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	priv, err := jose.NewPrivateJWK(*alg, sk)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	priv.Kid = priv.Thumbprint()
+
+	writeJWK(*keyPath, priv, 0o600)
+	writeJWK(*pubPath, priv.Public(), 0o644)
+}
+
+func sign(args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "private key")
+	context := fs.String("context", defaultContext, "SLH-DSA context string")
+	embed := fs.Bool("embed", false, "append the signature block to the file instead of writing file.sig")
+	prefix := fs.String("prefix", "# ", "line prefix of an embedded signature block")
+	fs.Parse(args)
+
+	if *keyPath == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	path := fs.Arg(0)
+
+	k := readJWK(*keyPath)
+	sk, err := k.PrivateKey()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	s := &signedconfig.Signer{ParameterSet: k.Alg, PrivateKey: sk, Context: *context}
+
+	if *embed {
+		signed, err := s.Embed(content, *prefix)
+
+		if err != nil {
+			fatal(err)
+		}
+
+		if err := os.WriteFile(path, signed, info.Mode().Perm()); err != nil {
+			fatal(err)
+		}
+
+		return
+	}
+
+	sig, err := s.Sign(content)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := os.WriteFile(path+signedconfig.SignatureSuffix, sig, 0o644); err != nil {
+		fatal(err)
+	}
+}
+
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	context := fs.String("context", defaultContext, "SLH-DSA context string")
+
+	var pubPaths list
+	fs.Var(&pubPaths, "pub", "trusted public key, repeatable")
+	fs.Parse(args)
+
+	if len(pubPaths) == 0 || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	l := &signedconfig.Loader{Context: *context}
+
+	for _, path := range pubPaths {
+		k := readJWK(path)
+		pk, err := k.PublicKey()
+
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", path, err))
+		}
+
+		l.Keys = append(l.Keys, slhdsa.TrustedKey{ParameterSet: k.Alg, PublicKey: pk})
+	}
+
+	content, err := l.Load(fs.Arg(0))
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", fs.Arg(0), err))
+	}
+
+	os.Stdout.Write(content)
+}
@@ -0,0 +1,262 @@
+// Command slhdsa-dnssec signs and validates DNS zone files with SLH-DSA keys
+// stored as JWK files, using the PRIVATEOID algorithm.
+//
+//	slhdsa-dnssec keygen [-alg parameter-set] -key private.jwk
+//	slhdsa-dnssec sign -zone origin -ksk private.jwk [-zsk private.jwk ...] [-validity duration] zonefile
+//	slhdsa-dnssec validate -zone origin -anchor anchors.zone zonefile
+//	slhdsa-dnssec stats [-zone origin]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/dnssec"
+	"github.com/skuuzie/go-slhdsa/jose"
+)
+
+// Repeatable string flag
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		keygen(os.Args[2:])
+	case "sign":
+		sign(os.Args[2:])
+	case "validate":
+		validate(os.Args[2:])
+	case "stats":
+		stats(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: slhdsa-dnssec keygen|sign|validate|stats [flags] ...")
+	os.Exit(2)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "slhdsa-dnssec:", err)
+	os.Exit(1)
+}
+
+func fqdn(name string) string {
+	if !strings.HasSuffix(name, ".") {
+		return name + "."
+	}
+
+	return name
+}
+
+func readZone(path, origin string) []dnssec.RR {
+	f, err := os.Open(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	defer f.Close()
+
+	rrs, err := dnssec.ParseZone(f, origin)
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	return rrs
+}
+
+func keygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	alg := fs.String("alg", slhdsa.ParameterSet.SLHDSA_SHA2_128s, "SLH-DSA parameter set")
+	keyPath := fs.String("key", "", "private key output")
+	fs.Parse(args)
+
+	if *keyPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ctx, err := slhdsa.New(*alg)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	sk, _, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	priv, err := jose.NewPrivateJWK(*alg, sk)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	priv.Kid = priv.Thumbprint()
+
+	data, err := json.MarshalIndent(priv, "", "  ")
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := os.WriteFile(*keyPath, append(data, '\n'), 0o600); err != nil {
+		fatal(err)
+	}
+}
+
+func readKey(path, zone string, ksk bool, ttl uint32) *dnssec.Key {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	k, err := jose.ParseJWK(data)
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	sk, err := k.PrivateKey()
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	pk, err := k.PublicKey()
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	flags := dnssec.FlagZone
+	if ksk {
+		flags |= dnssec.FlagSEP
+	}
+
+	return &dnssec.Key{Zone: zone, Flags: flags, TTL: ttl, ParameterSet: k.Alg, PublicKey: pk, PrivateKey: sk}
+}
+
+func sign(args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	zone := fs.String("zone", "", "zone origin")
+	validity := fs.Duration("validity", 30*24*time.Hour, "signature validity period")
+	ttl := fs.Uint("ttl", 3600, "DNSKEY TTL")
+
+	var kskPaths, zskPaths list
+	fs.Var(&kskPaths, "ksk", "key signing key, repeatable")
+	fs.Var(&zskPaths, "zsk", "zone signing key, repeatable")
+	fs.Parse(args)
+
+	if *zone == "" || len(kskPaths) == 0 || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	origin := fqdn(*zone)
+
+	var keys []*dnssec.Key
+
+	for _, path := range kskPaths {
+		keys = append(keys, readKey(path, origin, true, uint32(*ttl)))
+	}
+
+	for _, path := range zskPaths {
+		keys = append(keys, readKey(path, origin, false, uint32(*ttl)))
+	}
+
+	// Backdate inception to tolerate validator clock skew
+	now := time.Now().UTC()
+	signed, err := dnssec.SignZone(origin, readZone(fs.Arg(0), origin), keys, now.Add(-time.Hour), now.Add(*validity))
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := dnssec.WriteZone(os.Stdout, signed); err != nil {
+		fatal(err)
+	}
+
+	for _, k := range keys[:len(kskPaths)] {
+		dnskey, err := k.DNSKEY()
+
+		if err != nil {
+			fatal(err)
+		}
+
+		ds, err := dnssec.DS(dnskey)
+
+		if err != nil {
+			fatal(err)
+		}
+
+		fmt.Fprintln(os.Stderr, ds.String())
+	}
+}
+
+func validate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	zone := fs.String("zone", "", "zone origin")
+	anchorPath := fs.String("anchor", "", "zone file with trusted DS or DNSKEY records")
+	fs.Parse(args)
+
+	if *zone == "" || *anchorPath == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	origin := fqdn(*zone)
+
+	if err := dnssec.ValidateZone(origin, readZone(fs.Arg(0), origin), readZone(*anchorPath, origin), time.Now()); err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("%s: valid\n", origin)
+}
+
+func stats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	zone := fs.String("zone", "example.com.", "zone origin used for the response estimate")
+	fs.Parse(args)
+
+	sizes, err := dnssec.Sizes(fqdn(*zone))
+
+	if err != nil {
+		fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "parameter set\tpublic key\tsignature\tDNSKEY\tRRSIG\tresponse\tUDP\tTCP\t")
+
+	for _, s := range sizes {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%t\t%t\t\n", s.ParameterSet, s.PublicKey, s.Signature, s.DNSKEY, s.RRSIG, s.Response, s.FitsUDP, s.FitsTCP)
+	}
+
+	w.Flush()
+}
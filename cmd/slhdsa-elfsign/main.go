@@ -0,0 +1,250 @@
+// Command slhdsa-elfsign embeds SLH-DSA signatures in ELF binaries and
+// verifies them, with keys stored as JWK files.
+//
+//	slhdsa-elfsign keygen [-alg parameter-set] -key private.jwk -pub public.jwk
+//	slhdsa-elfsign sign -key private.jwk [-o output] binary
+//	slhdsa-elfsign verify -pub public.jwk [-pub ...] binary ...
+//
+// sign rewrites the binary in place unless -o is given.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/elfsign"
+	"github.com/skuuzie/go-slhdsa/jose"
+)
+
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		keygen(os.Args[2:])
+	case "sign":
+		sign(os.Args[2:])
+	case "verify":
+		verify(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: slhdsa-elfsign keygen|sign|verify [flags] ...")
+	os.Exit(2)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "slhdsa-elfsign:", err)
+	os.Exit(1)
+}
+
+func readJWK(path string) *jose.JWK {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	k, err := jose.ParseJWK(data)
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	return k
+}
+
+func writeJWK(path string, k *jose.JWK, mode os.FileMode) {
+	data, err := json.MarshalIndent(k, "", "  ")
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), mode); err != nil {
+		fatal(err)
+	}
+}
+
+func keygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	alg := fs.String("alg", slhdsa.ParameterSet.SLHDSA_SHA2_128s, "SLH-DSA parameter set")
+	keyPath := fs.String("key", "", "private key output")
+	pubPath := fs.String("pub", "", "public key output")
+	fs.Parse(args)
+
+	if *keyPath == "" || *pubPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ctx, err := slhdsa.New(*alg)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	sk, _, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	priv, err := jose.NewPrivateJWK(*alg, sk)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	priv.Kid = priv.Thumbprint()
+
+	writeJWK(*keyPath, priv, 0o600)
+	writeJWK(*pubPath, priv.Public(), 0o644)
+}
+
+func sign(args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "private key")
+	out := fs.String("o", "", "signed binary output, defaults to replacing the input")
+	fs.Parse(args)
+
+	if *keyPath == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	path := fs.Arg(0)
+
+	k := readJWK(*keyPath)
+	sk, err := k.PrivateKey()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	signed, err := elfsign.Sign(data, k.Alg, sk)
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	if *out == "" {
+		*out = path
+	}
+
+	// Replace through a temporary file so a running binary is not modified
+	tmp, err := os.CreateTemp(filepath.Dir(*out), "."+filepath.Base(*out)+".*")
+
+	if err != nil {
+		fatal(err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(signed); err != nil {
+		fatal(err)
+	}
+
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		fatal(err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		fatal(err)
+	}
+
+	if err := os.Rename(tmp.Name(), *out); err != nil {
+		fatal(err)
+	}
+
+	pk, err := k.PublicKey()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("%s: signed by key %s\n", *out, slhdsa.KeyID(pk))
+}
+
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+
+	var pubPaths list
+	fs.Var(&pubPaths, "pub", "trusted public key, repeatable")
+	fs.Parse(args)
+
+	if len(pubPaths) == 0 || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var keys []slhdsa.TrustedKey
+
+	for _, path := range pubPaths {
+		k := readJWK(path)
+		pk, err := k.PublicKey()
+
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", path, err))
+		}
+
+		keys = append(keys, slhdsa.TrustedKey{ParameterSet: k.Alg, PublicKey: pk})
+	}
+
+	failed := false
+
+	for _, path := range fs.Args() {
+		data, err := os.ReadFile(path)
+
+		if err != nil {
+			fatal(err)
+		}
+
+		sig, err := elfsign.Verify(data, keys)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+
+		fmt.Printf("%s: verified by key %s\n", path, sig.KeyID)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
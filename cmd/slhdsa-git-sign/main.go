@@ -0,0 +1,111 @@
+// Command slhdsa-git-sign lets git sign and verify commits and tags with
+// SLH-DSA keys by standing in for gpg.
+//
+//	git config gpg.program slhdsa-git-sign
+//	git config user.signingkey ~/.config/slhdsa-git/signing.asc
+//
+// or, for CMS signatures made with a JWK key,
+//
+//	git config gpg.format x509
+//	git config gpg.x509.program slhdsa-git-sign
+//	git config user.signingkey ~/.config/slhdsa-git/signing.jwk
+//
+// The signing key is the file named by user.signingkey, or
+// $SLHDSA_GIT_SIGNING_KEY when git passes a name that is not a file.
+// Signatures verify against $SLHDSA_GIT_TRUSTED_KEYS, by default
+// ~/.config/slhdsa-git/trusted_keys, which lists armored OpenPGP public
+// keys and one public JWK per line.
+//
+//	slhdsa-git-sign keygen [-alg parameter-set] -uid "Name <email>" [-key signing.asc] [-pub signing.pub.asc]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/gitsign"
+	"github.com/skuuzie/go-slhdsa/openpgp"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "keygen" {
+		keygen(os.Args[2:])
+		return
+	}
+
+	trusted := os.Getenv("SLHDSA_GIT_TRUSTED_KEYS")
+	if trusted == "" {
+		if dir, err := os.UserConfigDir(); err == nil {
+			trusted = filepath.Join(dir, "slhdsa-git", "trusted_keys")
+		}
+	}
+
+	os.Exit(gitsign.Run(os.Args[1:], &gitsign.Config{
+		DefaultKey:  os.Getenv("SLHDSA_GIT_SIGNING_KEY"),
+		TrustedKeys: trusted,
+		Stdin:       os.Stdin,
+		Stdout:      os.Stdout,
+		Stderr:      os.Stderr,
+	}))
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "slhdsa-git-sign:", err)
+	os.Exit(1)
+}
+
+func keygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	alg := fs.String("alg", slhdsa.ParameterSet.SLHDSA_SHAKE_128s, "SLH-DSA parameter set (SHAKE sets only)")
+	uid := fs.String("uid", "", "user ID, such as \"Name <email>\"")
+	keyPath := fs.String("key", "signing.asc", "secret key output")
+	pubPath := fs.String("pub", "signing.pub.asc", "public key output")
+	fs.Parse(args)
+
+	if *uid == "" {
+		fmt.Fprintln(os.Stderr, "usage: slhdsa-git-sign keygen -uid \"Name <email>\" [flags]")
+		os.Exit(2)
+	}
+
+	k, err := openpgp.GenerateKey(*alg, *uid)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	secret, err := k.SerializePrivate()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	public, err := k.SerializePublic()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	// Refuse to overwrite an existing secret key
+	f, err := os.OpenFile(*keyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if _, err := f.Write(openpgp.Armor(openpgp.BlockSecretKey, secret)); err != nil {
+		fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		fatal(err)
+	}
+
+	if err := os.WriteFile(*pubPath, openpgp.Armor(openpgp.BlockPublicKey, public), 0o644); err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("fingerprint %X\n", k.Fingerprint())
+}
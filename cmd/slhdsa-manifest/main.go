@@ -0,0 +1,235 @@
+// Command slhdsa-manifest signs directory trees with a single SLH-DSA
+// signature over a manifest of their files.
+//
+//	slhdsa-manifest keygen [-alg parameter-set] -name name [-key manifest.key] [-pub manifest.pub]
+//	slhdsa-manifest sign [-key manifest.key] [-x pattern]... [-o dir/MANIFEST] dir
+//	slhdsa-manifest verify [-pub manifest.pub]... [-m dir/MANIFEST] dir
+//
+// Keys are signed note keys (package note). verify lists added, missing and
+// modified files and exits with status 1 when the tree differs from the
+// signed manifest.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/manifest"
+	"github.com/skuuzie/go-slhdsa/note"
+)
+
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		keygen(os.Args[2:])
+	case "sign":
+		sign(os.Args[2:])
+	case "verify":
+		verify(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: slhdsa-manifest keygen|sign|verify [flags] ...")
+	os.Exit(2)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "slhdsa-manifest:", err)
+	os.Exit(1)
+}
+
+func readKey(path string) string {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+func keygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	alg := fs.String("alg", slhdsa.ParameterSet.SLHDSA_SHA2_128s, "SLH-DSA parameter set")
+	name := fs.String("name", "", "key name, such as the distribution host name")
+	keyPath := fs.String("key", "manifest.key", "signer key output")
+	pubPath := fs.String("pub", "manifest.pub", "verifier key output")
+	fs.Parse(args)
+
+	if *name == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	skey, vkey, err := note.GenerateKey(*name, *alg)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	// Refuse to overwrite an existing signer key
+	f, err := os.OpenFile(*keyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if _, err := fmt.Fprintln(f, skey); err != nil {
+		fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		fatal(err)
+	}
+
+	if err := os.WriteFile(*pubPath, []byte(vkey+"\n"), 0o644); err != nil {
+		fatal(err)
+	}
+
+	fmt.Println(vkey)
+}
+
+func sign(args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyPath := fs.String("key", "manifest.key", "signer key")
+	out := fs.String("o", "", "signed manifest output, defaults to MANIFEST in the directory, - for standard output")
+	var exclude list
+	fs.Var(&exclude, "x", "exclude paths matching the pattern (repeatable)")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	root := fs.Arg(0)
+
+	signer, err := note.NewSigner(readKey(*keyPath))
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", *keyPath, err))
+	}
+
+	if *out == "" {
+		*out = filepath.Join(root, "MANIFEST")
+	}
+
+	// A manifest written into the tree cannot cover itself
+	if *out != "-" {
+		if rel, err := filepath.Rel(root, *out); err == nil && filepath.IsLocal(rel) {
+			exclude = append(exclude, filepath.ToSlash(rel))
+		}
+	}
+
+	m, err := manifest.Build(root, exclude...)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	signed, err := manifest.Sign(m, signer)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if *out == "-" {
+		os.Stdout.Write(signed)
+		return
+	}
+
+	if err := os.WriteFile(*out, signed, 0o644); err != nil {
+		fatal(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "signed %d files\n", len(m.Entries))
+}
+
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	var pubPaths list
+	fs.Var(&pubPaths, "pub", "trusted verifier key file (repeatable), defaults to manifest.pub")
+	manifestPath := fs.String("m", "", "signed manifest, defaults to MANIFEST in the directory")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	root := fs.Arg(0)
+
+	if len(pubPaths) == 0 {
+		pubPaths = list{"manifest.pub"}
+	}
+
+	var verifiers []note.Verifier
+
+	for _, path := range pubPaths {
+		v, err := note.NewVerifier(readKey(path))
+
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", path, err))
+		}
+
+		verifiers = append(verifiers, v)
+	}
+
+	if *manifestPath == "" {
+		*manifestPath = filepath.Join(root, "MANIFEST")
+	}
+
+	signed, err := os.ReadFile(*manifestPath)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	m, changes, err := manifest.Verify(root, signed, note.VerifierList(verifiers...))
+
+	var unverified *note.UnverifiedNoteError
+	if errors.As(err, &unverified) {
+		fatal(fmt.Errorf("%s: not signed by a trusted key", *manifestPath))
+	}
+
+	if err != nil {
+		fatal(err)
+	}
+
+	for _, c := range changes {
+		if detail := c.Detail(); detail != "" {
+			fmt.Printf("%s %s (%s)\n", c.Kind, c.Path, detail)
+		} else {
+			fmt.Printf("%s %s\n", c.Kind, c.Path)
+		}
+	}
+
+	if len(changes) != 0 {
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "verified %d files\n", len(m.Entries))
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package main
+
+import "os"
+
+// Terminal modes are not supported here, the password is read as typed
+func disableEcho(f *os.File) (restore func(), ok bool) {
+	return nil, false
+}
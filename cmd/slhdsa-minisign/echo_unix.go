@@ -0,0 +1,36 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+func termios(f *os.File, req uintptr, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// Turn off echo on a terminal, ok is false when f is not one
+func disableEcho(f *os.File) (restore func(), ok bool) {
+	var old syscall.Termios
+
+	if err := termios(f, ioctlGetTermios, &old); err != nil {
+		return nil, false
+	}
+
+	t := old
+	t.Lflag &^= syscall.ECHO
+	t.Lflag |= syscall.ICANON | syscall.ECHONL
+
+	if err := termios(f, ioctlSetTermios, &t); err != nil {
+		return nil, false
+	}
+
+	return func() { termios(f, ioctlSetTermios, &old) }, true
+}
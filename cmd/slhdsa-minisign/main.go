@@ -0,0 +1,292 @@
+// Command slhdsa-minisign signs and verifies files with minisign-style
+// signatures and SLH-DSA keys.
+//
+//	slhdsa-minisign keygen [-alg parameter-set] [-p minisign.pub] [-s minisign.key] [-W]
+//	slhdsa-minisign sign [-s minisign.key] [-t trusted-comment] [-c untrusted-comment] file ...
+//	slhdsa-minisign verify [-p minisign.pub | -P public-key] [-x file.minisig] [-q] file
+//
+// Secret key passwords are read from $SLHDSA_MINISIGN_PASSWORD, or from
+// standard input when it is unset, with echo turned off on a Unix terminal.
+// keygen asks twice and refuses an empty password, -W stores the key
+// unencrypted instead.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/minisign"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		keygen(os.Args[2:])
+	case "sign":
+		sign(os.Args[2:])
+	case "verify":
+		verify(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: slhdsa-minisign keygen|sign|verify [flags] ...")
+	os.Exit(2)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "slhdsa-minisign:", err)
+	os.Exit(1)
+}
+
+// Shared so a piped confirmation is not lost in an earlier reader's buffer
+var stdin = bufio.NewReader(os.Stdin)
+
+func readLine(prompt string) []byte {
+	fmt.Fprint(os.Stderr, prompt)
+
+	if restore, ok := disableEcho(os.Stdin); ok {
+		// Leave the terminal usable when interrupted at the prompt
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt)
+
+		go func() {
+			if _, ok := <-sigs; ok {
+				restore()
+				fmt.Fprintln(os.Stderr)
+				os.Exit(130)
+			}
+		}()
+
+		defer func() {
+			signal.Stop(sigs)
+			close(sigs)
+			restore()
+		}()
+	}
+
+	line, err := stdin.ReadString('\n')
+
+	if err != nil && line == "" {
+		fatal(fmt.Errorf("reading password: %w", err))
+	}
+
+	return []byte(strings.TrimRight(line, "\r\n"))
+}
+
+// Password of a secret key, asked twice and required non-empty for a new one
+func readPassword(prompt string, confirm bool) []byte {
+	p, ok := os.LookupEnv("SLHDSA_MINISIGN_PASSWORD")
+	password := []byte(p)
+
+	if !ok {
+		password = readLine(prompt)
+
+		if confirm && !bytes.Equal(password, readLine("Password (again): ")) {
+			fatal(errors.New("passwords do not match"))
+		}
+	}
+
+	if confirm && len(password) == 0 {
+		fatal(errors.New("empty password, use -W for an unencrypted secret key"))
+	}
+
+	return password
+}
+
+func keygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	alg := fs.String("alg", slhdsa.ParameterSet.SLHDSA_SHA2_128s, "SLH-DSA parameter set")
+	pubPath := fs.String("p", "minisign.pub", "public key output")
+	keyPath := fs.String("s", "minisign.key", "secret key output")
+	noPassword := fs.Bool("W", false, "do not encrypt the secret key")
+	fs.Parse(args)
+
+	k, err := minisign.GenerateKey(*alg)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	var password []byte
+	if !*noPassword {
+		password = readPassword("Password: ", true)
+	}
+
+	secret, err := minisign.MarshalPrivateKey(k, password)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	pk, err := k.Public()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	public, err := minisign.MarshalPublicKey(pk)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	// Refuse to overwrite an existing secret key
+	f, err := os.OpenFile(*keyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if _, err := f.Write(secret); err != nil {
+		fatal(err)
+	}
+
+	if err := f.Close(); err != nil {
+		fatal(err)
+	}
+
+	if err := os.WriteFile(*pubPath, public, 0o644); err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("public key %s written to %s\n", pk.KeyID, *pubPath)
+}
+
+func sign(args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyPath := fs.String("s", "minisign.key", "secret key")
+	trusted := fs.String("t", "", "trusted comment, defaults to the timestamp and file name")
+	untrusted := fs.String("c", "", "untrusted comment")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*keyPath)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	var password []byte
+
+	if encrypted, err := minisign.IsEncrypted(data); err != nil {
+		fatal(fmt.Errorf("%s: %w", *keyPath, err))
+	} else if encrypted {
+		password = readPassword("Password: ", false)
+	}
+
+	k, err := minisign.ParsePrivateKey(data, password)
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", *keyPath, err))
+	}
+
+	for _, path := range fs.Args() {
+		f, err := os.Open(path)
+
+		if err != nil {
+			fatal(err)
+		}
+
+		comment := *trusted
+		if comment == "" {
+			comment = fmt.Sprintf("timestamp:%d\tfile:%s", time.Now().Unix(), filepath.Base(path))
+		}
+
+		sig, err := minisign.Sign(k, f, comment, *untrusted)
+		f.Close()
+
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", path, err))
+		}
+
+		if err := os.WriteFile(path+".minisig", sig, 0o644); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	pubPath := fs.String("p", "minisign.pub", "public key file")
+	pubLine := fs.String("P", "", "public key, overrides -p")
+	sigPath := fs.String("x", "", "signature file, defaults to the file name with .minisig appended")
+	quiet := fs.Bool("q", false, "do not print the trusted comment")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	path := fs.Arg(0)
+
+	pubData := []byte(*pubLine)
+
+	if *pubLine == "" {
+		var err error
+		pubData, err = os.ReadFile(*pubPath)
+
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	pk, err := minisign.ParsePublicKey(pubData)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if *sigPath == "" {
+		*sigPath = path + ".minisig"
+	}
+
+	sigData, err := os.ReadFile(*sigPath)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	sig, err := minisign.ParseSignature(sigData)
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", *sigPath, err))
+	}
+
+	f, err := os.Open(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	defer f.Close()
+
+	if err := pk.Verify(f, sig); err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	if !*quiet {
+		fmt.Println("Signature and comment signature verified")
+		fmt.Println("Trusted comment:", sig.TrustedComment)
+	}
+}
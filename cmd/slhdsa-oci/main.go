@@ -0,0 +1,208 @@
+// Command slhdsa-oci signs and verifies images in an OCI image layout with
+// SLH-DSA keys stored as JWK files.
+//
+//	slhdsa-oci keygen [-alg parameter-set] -key private.jwk -pub public.jwk
+//	slhdsa-oci sign -key private.jwk [-ref reference] layout tag-or-digest
+//	slhdsa-oci verify -pub public.jwk [-pub ...] layout tag-or-digest
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/jose"
+	"github.com/skuuzie/go-slhdsa/oci"
+)
+
+// Repeatable string flag
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "keygen":
+		keygen(os.Args[2:])
+	case "sign":
+		sign(os.Args[2:])
+	case "verify":
+		verify(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: slhdsa-oci keygen|sign|verify [flags] ...")
+	os.Exit(2)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "slhdsa-oci:", err)
+	os.Exit(1)
+}
+
+func readJWK(path string) *jose.JWK {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	k, err := jose.ParseJWK(data)
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", path, err))
+	}
+
+	return k
+}
+
+func writeJWK(path string, k *jose.JWK, mode os.FileMode) {
+	data, err := json.MarshalIndent(k, "", "  ")
+
+	if err != nil {
+		fatal(err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), mode); err != nil {
+		fatal(err)
+	}
+}
+
+func keygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	alg := fs.String("alg", slhdsa.ParameterSet.SLHDSA_SHA2_128s, "SLH-DSA parameter set")
+	keyPath := fs.String("key", "", "private key output")
+	pubPath := fs.String("pub", "", "public key output")
+	fs.Parse(args)
+
+	if *keyPath == "" || *pubPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	ctx, err := slhdsa.New(*alg)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	sk, _, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	priv, err := jose.NewPrivateJWK(*alg, sk)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	priv.Kid = priv.Thumbprint()
+
+	writeJWK(*keyPath, priv, 0o600)
+	writeJWK(*pubPath, priv.Public(), 0o644)
+}
+
+func resolve(fs *flag.FlagSet) (*oci.Layout, oci.Descriptor) {
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	l, err := oci.OpenLayout(fs.Arg(0))
+
+	if err != nil {
+		fatal(err)
+	}
+
+	d, err := l.Resolve(fs.Arg(1))
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", fs.Arg(1), err))
+	}
+
+	return l, d
+}
+
+func sign(args []string) {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "private key")
+	ref := fs.String("ref", "", "image reference recorded in the signature")
+	fs.Parse(args)
+
+	if *keyPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	k := readJWK(*keyPath)
+	sk, err := k.PrivateKey()
+
+	if err != nil {
+		fatal(err)
+	}
+
+	l, target := resolve(fs)
+	d, err := l.Sign(target, *ref, k.Alg, sk)
+
+	if err != nil {
+		fatal(err)
+	}
+
+	fmt.Printf("signed %s, signature %s\n", target.Digest, d.Digest)
+}
+
+func verify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+
+	var pubPaths list
+	fs.Var(&pubPaths, "pub", "trusted public key, repeatable")
+	fs.Parse(args)
+
+	if len(pubPaths) == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var keys []slhdsa.TrustedKey
+
+	for _, path := range pubPaths {
+		k := readJWK(path)
+		pk, err := k.PublicKey()
+
+		if err != nil {
+			fatal(fmt.Errorf("%s: %w", path, err))
+		}
+
+		keys = append(keys, slhdsa.TrustedKey{ParameterSet: k.Alg, PublicKey: pk})
+	}
+
+	l, target := resolve(fs)
+	keyIDs, err := l.Verify(target, keys)
+
+	if err != nil {
+		fatal(fmt.Errorf("%s: %w", target.Digest, err))
+	}
+
+	for _, id := range keyIDs {
+		fmt.Printf("%s: verified by key %s\n", target.Digest, id)
+	}
+}
@@ -0,0 +1,225 @@
+// Package cms implements CMS SignedData (RFC 5652) with SLH-DSA signers as
+// profiled by RFC 9814.
+package cms
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Content types and attribute identifiers (RFC 5652)
+var (
+	OIDData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	OIDSignedData    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	OIDContentType   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	OIDMessageDigest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	OIDSigningTime   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 5}
+)
+
+// Digest algorithm identifiers
+var (
+	OIDSHA256   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	OIDSHA512   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	OIDSHAKE128 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 11}
+	OIDSHAKE256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 12}
+)
+
+// SLH-DSA algorithm identifiers (RFC 9814 Section 3)
+var algorithmOIDs = map[string]asn1.ObjectIdentifier{
+	slhdsa.ParameterSet.SLHDSA_SHA2_128s:  {2, 16, 840, 1, 101, 3, 4, 3, 20},
+	slhdsa.ParameterSet.SLHDSA_SHA2_128f:  {2, 16, 840, 1, 101, 3, 4, 3, 21},
+	slhdsa.ParameterSet.SLHDSA_SHA2_192s:  {2, 16, 840, 1, 101, 3, 4, 3, 22},
+	slhdsa.ParameterSet.SLHDSA_SHA2_192f:  {2, 16, 840, 1, 101, 3, 4, 3, 23},
+	slhdsa.ParameterSet.SLHDSA_SHA2_256s:  {2, 16, 840, 1, 101, 3, 4, 3, 24},
+	slhdsa.ParameterSet.SLHDSA_SHA2_256f:  {2, 16, 840, 1, 101, 3, 4, 3, 25},
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128s: {2, 16, 840, 1, 101, 3, 4, 3, 26},
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128f: {2, 16, 840, 1, 101, 3, 4, 3, 27},
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192s: {2, 16, 840, 1, 101, 3, 4, 3, 28},
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192f: {2, 16, 840, 1, 101, 3, 4, 3, 29},
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256s: {2, 16, 840, 1, 101, 3, 4, 3, 30},
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256f: {2, 16, 840, 1, 101, 3, 4, 3, 31},
+}
+
+// Digest algorithm paired with each SLH-DSA parameter set (RFC 9814 Section 4)
+var digestPairing = map[string]asn1.ObjectIdentifier{
+	slhdsa.ParameterSet.SLHDSA_SHA2_128s:  OIDSHA256,
+	slhdsa.ParameterSet.SLHDSA_SHA2_128f:  OIDSHA256,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192s:  OIDSHA512,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192f:  OIDSHA512,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256s:  OIDSHA512,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256f:  OIDSHA512,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128s: OIDSHAKE128,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128f: OIDSHAKE128,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192s: OIDSHAKE256,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192f: OIDSHAKE256,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256s: OIDSHAKE256,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256f: OIDSHAKE256,
+}
+
+var (
+	ErrUnsupportedParameterSet = errors.New("cms: unsupported SLH-DSA parameter set")
+	ErrUnsupportedAlgorithm    = errors.New("cms: unsupported algorithm identifier")
+	ErrNoSignature             = errors.New("cms: no signer info matches the given keys")
+	ErrDigestMismatch          = errors.New("cms: message digest does not match content")
+	ErrDigestAlgorithm         = errors.New("cms: digest algorithm not paired with the SLH-DSA parameter set")
+	ErrInvalidSignature        = errors.New("cms: invalid SLH-DSA signature")
+	ErrMissingContent          = errors.New("cms: detached signature requires content")
+	ErrAttachedContent         = errors.New("cms: detached content given for a signature with attached content")
+)
+
+// Algorithm identifier of an SLH-DSA parameter set
+func AlgorithmOID(paramSet string) (asn1.ObjectIdentifier, error) {
+	oid, ok := algorithmOIDs[paramSet]
+
+	if !ok {
+		return nil, ErrUnsupportedParameterSet
+	}
+
+	return oid, nil
+}
+
+// SLH-DSA parameter set of an algorithm identifier
+func ParameterSetFromOID(oid asn1.ObjectIdentifier) (string, error) {
+	for paramSet, o := range algorithmOIDs {
+		if o.Equal(oid) {
+			return paramSet, nil
+		}
+	}
+
+	return "", ErrUnsupportedAlgorithm
+}
+
+// Digest algorithm RFC 9814 pairs with a parameter set
+func DigestAlgorithmOID(paramSet string) (asn1.ObjectIdentifier, error) {
+	oid, ok := digestPairing[paramSet]
+
+	if !ok {
+		return nil, ErrUnsupportedParameterSet
+	}
+
+	return oid, nil
+}
+
+// Compute message digest with the given digest algorithm
+//
+// SHAKE128 and SHAKE256 produce 256 and 512 bits of output respectively (RFC 8702)
+func Digest(alg asn1.ObjectIdentifier, data []byte) ([]byte, error) {
+	switch {
+	case alg.Equal(OIDSHA256):
+		d := sha256.Sum256(data)
+		return d[:], nil
+	case alg.Equal(OIDSHA512):
+		d := sha512.Sum512(data)
+		return d[:], nil
+	case alg.Equal(OIDSHAKE128):
+		return sha3.SumSHAKE128(data, 32), nil
+	case alg.Equal(OIDSHAKE256):
+		return sha3.SumSHAKE256(data, 64), nil
+	}
+
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// ASN.1 structures (RFC 5652)
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type encapsulatedContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     asn1.RawValue `asn1:"optional,explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []algorithmIdentifier `asn1:"set"`
+	EncapContentInfo encapsulatedContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type issuerAndSerialNumber struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type signerInfo struct {
+	Version            int
+	SID                asn1.RawValue
+	DigestAlgorithm    algorithmIdentifier
+	SignedAttrs        asn1.RawValue `asn1:"optional,tag:0"`
+	SignatureAlgorithm algorithmIdentifier
+	Signature          []byte
+	UnsignedAttrs      asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values []asn1.RawValue `asn1:"set"`
+}
+
+// Signed attribute with a single DER encoded value
+type Attribute struct {
+	Type  asn1.ObjectIdentifier
+	Value []byte
+}
+
+// Parsed SignerInfo
+type SignerInfo struct {
+	// SLH-DSA parameter set named by the signature algorithm
+	ParameterSet string
+
+	// Subject key identifier, nil when the signer is identified by issuer and serial number
+	SubjectKeyID []byte
+
+	// DER encoded issuer name, nil when the signer is identified by subject key identifier
+	Issuer []byte
+
+	// Serial number of the signer certificate, nil when identified by subject key identifier
+	SerialNumber *big.Int
+
+	// Digest algorithm applied to the content
+	DigestAlgorithm asn1.ObjectIdentifier
+
+	// Signing time from the signed attributes, zero if absent
+	SigningTime time.Time
+
+	// Raw SLH-DSA signature
+	Signature []byte
+
+	// Every signed attribute, including content-type and message-digest
+	Attributes []Attribute
+
+	contentType   asn1.ObjectIdentifier
+	messageDigest []byte
+	signedAttrs   []byte
+}
+
+// Parsed SignedData
+type SignedData struct {
+	// Encapsulated content type
+	ContentType asn1.ObjectIdentifier
+
+	// Encapsulated content, nil for detached signatures
+	Content []byte
+
+	// DER encoded certificates carried in the SignedData, if any
+	Certificates []byte
+
+	Signers []SignerInfo
+}
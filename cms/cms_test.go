@@ -0,0 +1,214 @@
+package cms_test
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/cms"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+)
+
+func newSigner(t *testing.T, paramSet string) (cms.Signer, cms.VerifierKey) {
+	sk, pk := testkey.New(t, paramSet)
+
+	return cms.Signer{ParameterSet: paramSet, PrivateKey: sk}, cms.VerifierKey{ParameterSet: paramSet, PublicKey: pk}
+}
+
+func TestDigestPairing(t *testing.T) {
+	cases := map[string]asn1.ObjectIdentifier{
+		slhdsa.ParameterSet.SLHDSA_SHA2_128f:  cms.OIDSHA256,
+		slhdsa.ParameterSet.SLHDSA_SHA2_192s:  cms.OIDSHA512,
+		slhdsa.ParameterSet.SLHDSA_SHAKE_128s: cms.OIDSHAKE128,
+		slhdsa.ParameterSet.SLHDSA_SHAKE_256f: cms.OIDSHAKE256,
+	}
+
+	for paramSet, expected := range cases {
+		oid, err := cms.DigestAlgorithmOID(paramSet)
+
+		if err != nil || !oid.Equal(expected) {
+			t.Errorf("%v: expected %v, got %v (%v)", paramSet, expected, oid, err)
+		}
+	}
+}
+
+func TestDigestAlgorithmMismatch(t *testing.T) {
+	signer, key := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+
+	der, err := cms.Sign([]byte("content"), []cms.Signer{signer}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Declare SHA-512 where RFC 9814 pairs SHA2-128f with SHA-256
+	sha256OID, _ := asn1.Marshal(cms.OIDSHA256)
+	sha512OID, _ := asn1.Marshal(cms.OIDSHA512)
+
+	sd, err := cms.Parse(bytes.ReplaceAll(der, sha256OID, sha512OID))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sd.Verify(nil, []cms.VerifierKey{key}); err != cms.ErrDigestAlgorithm {
+		t.Errorf("expected ErrDigestAlgorithm, got %v", err)
+	}
+}
+
+func TestSignAttached(t *testing.T) {
+	signer, key := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHAKE_128f)
+	content := []byte("firmware image v1.2.3")
+	signingTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	der, err := cms.Sign(content, []cms.Signer{signer}, &cms.SignOptions{SigningTime: signingTime})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sd, err := cms.Parse(der)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(sd.Content, content) {
+		t.Fatalf("content mismatch: %q", sd.Content)
+	}
+
+	verified, err := sd.Verify(nil, []cms.VerifierKey{key})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !verified[0].SigningTime.Equal(signingTime) {
+		t.Errorf("signing time: expected %v, got %v", signingTime, verified[0].SigningTime)
+	}
+
+	if !verified[0].DigestAlgorithm.Equal(cms.OIDSHAKE128) {
+		t.Errorf("unexpected digest algorithm %v", verified[0].DigestAlgorithm)
+	}
+
+	_, other := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHAKE_128f)
+	if _, err := sd.Verify(nil, []cms.VerifierKey{other}); err == nil {
+		t.Error("verified with an untrusted key")
+	}
+
+	// Detached content is never silently ignored in favour of the attached content
+	for _, detached := range [][]byte{[]byte("other content"), content} {
+		if _, err := sd.Verify(detached, []cms.VerifierKey{key}); !errors.Is(err, cms.ErrAttachedContent) {
+			t.Errorf("detached %q with attached content: %v", detached, err)
+		}
+	}
+}
+
+func TestSignDetached(t *testing.T) {
+	signer, key := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	content := []byte("release.tar.gz contents")
+
+	der, err := cms.Sign(content, []cms.Signer{signer}, &cms.SignOptions{Detached: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sd, err := cms.Parse(der)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sd.Content != nil {
+		t.Fatal("detached signature carries content")
+	}
+
+	if _, err := sd.Verify(nil, []cms.VerifierKey{key}); err != cms.ErrMissingContent {
+		t.Errorf("expected ErrMissingContent, got %v", err)
+	}
+
+	if _, err := sd.Verify(content, []cms.VerifierKey{key}); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := sd.Verify([]byte("tampered"), []cms.VerifierKey{key}); err != cms.ErrDigestMismatch {
+		t.Errorf("expected ErrDigestMismatch, got %v", err)
+	}
+}
+
+func TestSMIME(t *testing.T) {
+	signer, key := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	entity := []byte("Content-Type: text/plain\n\nHello, post-quantum world.\n")
+
+	msg, err := cms.SignMIME(entity, []cms.Signer{signer}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, _, err := cms.VerifyMIME(msg, []cms.VerifierKey{key})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(signed, bytes.ReplaceAll(entity, []byte("\n"), []byte("\r\n"))) {
+		t.Errorf("unexpected entity %q", signed)
+	}
+
+	tampered := bytes.Replace(msg, []byte("Hello"), []byte("Jello"), 1)
+	if _, _, err := cms.VerifyMIME(tampered, []cms.VerifierKey{key}); err == nil {
+		t.Error("tampered message verified")
+	}
+
+	// No further part may follow, even after the closing boundary
+	closing := bytes.LastIndex(msg, []byte("\r\n--"))
+	boundary := bytes.TrimSuffix(msg[closing+4:], []byte("--\r\n"))
+	extra := slices.Concat(msg, []byte("--"), boundary, []byte("\r\nContent-Type: text/plain\r\n\r\nextra\r\n"))
+
+	if _, _, err := cms.VerifyMIME(extra, []cms.VerifierKey{key}); err == nil {
+		t.Error("message with three parts verified")
+	}
+}
+
+func TestExtraAttributesAndCertificates(t *testing.T) {
+	signer, key := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	oid := asn1.ObjectIdentifier{1, 2, 3, 4}
+	value, _ := asn1.Marshal("extra")
+	cert, _ := asn1.Marshal([]int{1, 2})
+
+	der, err := cms.Sign([]byte("content"), []cms.Signer{signer}, &cms.SignOptions{
+		Attributes:   []cms.Attribute{{Type: oid, Value: value}},
+		Certificates: [][]byte{cert},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sd, err := cms.Parse(der)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signers, err := sd.Verify(nil, []cms.VerifierKey{key})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, a := range signers[0].Attributes {
+		found = found || (a.Type.Equal(oid) && bytes.Equal(a.Value, value))
+	}
+
+	if !found || len(signers[0].Attributes) != 4 || !bytes.Contains(sd.Certificates, cert) {
+		t.Errorf("unexpected attributes %v or certificates %x", signers[0].Attributes, sd.Certificates)
+	}
+}
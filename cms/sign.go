@@ -0,0 +1,223 @@
+package cms
+
+import (
+	"crypto/sha1"
+	"encoding/asn1"
+	"errors"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// SLH-DSA signer of a SignedData
+type Signer struct {
+	// SLH-DSA parameter set of the private key
+	ParameterSet string
+
+	PrivateKey slhdsa.PrivateKey
+
+	// Subject key identifier placed in the SignerInfo
+	//
+	// Defaults to the SHA-1 of the public key bytes when nil
+	SubjectKeyID []byte
+}
+
+// SignedData generation options
+type SignOptions struct {
+	// Omit the encapsulated content
+	Detached bool
+
+	// Encapsulated content type, defaults to id-data
+	ContentType asn1.ObjectIdentifier
+
+	// Value of the signing-time attribute, defaults to the current time
+	SigningTime time.Time
+
+	// Use the deterministic SLH-DSA variant instead of the hedged one
+	Deterministic bool
+
+	// Additional signed attributes, after content-type, message-digest and signing-time
+	Attributes []Attribute
+
+	// DER encoded certificates to carry in the SignedData
+	Certificates [][]byte
+}
+
+// Default subject key identifier of an SLH-DSA public key
+func SubjectKeyID(pk slhdsa.PublicKey) []byte {
+	h := sha1.Sum(pk.KeyBytes)
+	return h[:]
+}
+
+// Produce a DER encoded ContentInfo wrapping a SignedData over `content`
+func Sign(content []byte, signers []Signer, opts *SignOptions) ([]byte, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("cms: at least one signer is required")
+	}
+
+	if opts == nil {
+		opts = &SignOptions{}
+	}
+
+	contentType := opts.ContentType
+	if contentType == nil {
+		contentType = OIDData
+	}
+
+	signingTime := opts.SigningTime
+	if signingTime.IsZero() {
+		signingTime = time.Now()
+	}
+
+	sd := signedData{
+		Version: 3,
+		EncapContentInfo: encapsulatedContentInfo{
+			EContentType: contentType,
+		},
+	}
+
+	if len(opts.Certificates) != 0 {
+		var certs []byte
+		for _, c := range opts.Certificates {
+			certs = append(certs, c...)
+		}
+
+		sd.Certificates = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certs}
+	}
+
+	if !opts.Detached {
+		eContent, err := asn1.Marshal(content)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sd.EncapContentInfo.EContent, err = explicit(0, eContent)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	seenDigest := map[string]bool{}
+
+	for _, s := range signers {
+		si, digestAlg, err := s.signerInfo(content, contentType, signingTime, opts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !seenDigest[digestAlg.String()] {
+			seenDigest[digestAlg.String()] = true
+			sd.DigestAlgorithms = append(sd.DigestAlgorithms, algorithmIdentifier{Algorithm: digestAlg})
+		}
+
+		sd.SignerInfos = append(sd.SignerInfos, si)
+	}
+
+	inner, err := asn1.Marshal(sd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := explicit(0, inner)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(contentInfo{
+		ContentType: OIDSignedData,
+		Content:     wrapped,
+	})
+}
+
+// Pre-encoded [tag] EXPLICIT wrapper, encoding/asn1 writes RawValue.FullBytes verbatim
+func explicit(tag int, inner []byte) (asn1.RawValue, error) {
+	encoded, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: tag, IsCompound: true, Bytes: inner})
+
+	if err != nil {
+		return asn1.RawValue{}, err
+	}
+
+	return asn1.RawValue{FullBytes: encoded}, nil
+}
+
+func (s Signer) signerInfo(content []byte, contentType asn1.ObjectIdentifier, signingTime time.Time, opts *SignOptions) (signerInfo, asn1.ObjectIdentifier, error) {
+	sigAlg, err := AlgorithmOID(s.ParameterSet)
+
+	if err != nil {
+		return signerInfo{}, nil, err
+	}
+
+	digestAlg, _ := DigestAlgorithmOID(s.ParameterSet)
+	digest, _ := Digest(digestAlg, content)
+
+	ski := s.SubjectKeyID
+	if ski == nil {
+		pk, err := slhdsa.PublicKeyFromPrivateKey(s.ParameterSet, s.PrivateKey)
+
+		if err != nil {
+			return signerInfo{}, nil, err
+		}
+
+		ski = SubjectKeyID(pk)
+	}
+
+	attrs, err := marshalSignedAttributes(contentType, digest, signingTime, opts.Attributes)
+
+	if err != nil {
+		return signerInfo{}, nil, err
+	}
+
+	ctx, _ := slhdsa.New(s.ParameterSet)
+	sig, err := ctx.GenerateSignature(s.PrivateKey, attrs, nil, !opts.Deterministic, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return signerInfo{}, nil, err
+	}
+
+	// SignedAttributes are signed as a SET OF and carried as [0] IMPLICIT
+	implicitAttrs := append([]byte{0xa0}, attrs[1:]...)
+
+	return signerInfo{
+		Version:            3,
+		SID:                asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, Bytes: ski},
+		DigestAlgorithm:    algorithmIdentifier{Algorithm: digestAlg},
+		SignedAttrs:        asn1.RawValue{FullBytes: implicitAttrs},
+		SignatureAlgorithm: algorithmIdentifier{Algorithm: sigAlg},
+		Signature:          sig,
+	}, digestAlg, nil
+}
+
+// DER encoding of the signed attributes as a SET OF Attribute
+func marshalSignedAttributes(contentType asn1.ObjectIdentifier, digest []byte, signingTime time.Time, extra []Attribute) ([]byte, error) {
+	values := []struct {
+		oid asn1.ObjectIdentifier
+		val any
+	}{
+		{OIDContentType, contentType},
+		{OIDMessageDigest, digest},
+		{OIDSigningTime, signingTime.UTC()},
+	}
+
+	attrs := make([]attribute, 0, len(values))
+
+	for _, v := range values {
+		encoded, err := asn1.Marshal(v.val)
+
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, attribute{Type: v.oid, Values: []asn1.RawValue{{FullBytes: encoded}}})
+	}
+
+	for _, a := range extra {
+		attrs = append(attrs, attribute{Type: a.Type, Values: []asn1.RawValue{{FullBytes: a.Value}}})
+	}
+
+	return asn1.MarshalWithParams(attrs, "set")
+}
@@ -0,0 +1,183 @@
+package cms
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"mime"
+	"strings"
+)
+
+// micalg names of the paired digest algorithms (RFC 8551 Section 3.5.3.2)
+func micalg(signers []Signer) string {
+	var names []string
+	seen := map[string]bool{}
+
+	for _, s := range signers {
+		oid, err := DigestAlgorithmOID(s.ParameterSet)
+
+		if err != nil {
+			continue
+		}
+
+		var name string
+		switch {
+		case oid.Equal(OIDSHA256):
+			name = "sha-256"
+		case oid.Equal(OIDSHA512):
+			name = "sha-512"
+		case oid.Equal(OIDSHAKE128):
+			name = "shake128"
+		case oid.Equal(OIDSHAKE256):
+			name = "shake256"
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+// Convert bare LF line endings to CRLF as required for signed MIME entities
+func canonicalizeLineEndings(b []byte) []byte {
+	out := bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(out, []byte("\n"), []byte("\r\n"))
+}
+
+// Wrap a MIME entity (headers and body) in an S/MIME multipart/signed message
+//
+// The signature is always detached, `opts.Detached` is ignored.
+func SignMIME(entity []byte, signers []Signer, opts *SignOptions) ([]byte, error) {
+	canonical := canonicalizeLineEndings(entity)
+
+	o := SignOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.Detached = true
+
+	der, err := Sign(canonical, signers, &o)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rnd := make([]byte, 16)
+	rand.Read(rnd)
+	boundary := "----slhdsa-" + hex.EncodeToString(rnd)
+
+	var buf bytes.Buffer
+
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: " + mime.FormatMediaType("multipart/signed", map[string]string{
+		"protocol": "application/pkcs7-signature",
+		"micalg":   micalg(signers),
+		"boundary": boundary,
+	}))
+	buf.WriteString("\r\n\r\nThis is an S/MIME signed message\r\n\r\n")
+	buf.WriteString("--" + boundary + "\r\n")
+	buf.Write(canonical)
+	buf.WriteString("\r\n--" + boundary + "\r\n")
+	buf.WriteString("Content-Type: application/pkcs7-signature; name=\"smime.p7s\"\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buf.WriteString("Content-Disposition: attachment; filename=\"smime.p7s\"\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString(der)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+// Verify an S/MIME multipart/signed message against the trusted keys
+//
+// Returns the signed MIME entity and the SignerInfos that verified.
+func VerifyMIME(message []byte, keys []VerifierKey) ([]byte, []SignerInfo, error) {
+	message = canonicalizeLineEndings(message)
+
+	headerEnd := bytes.Index(message, []byte("\r\n\r\n"))
+	if headerEnd < 0 {
+		return nil, nil, errors.New("cms: malformed MIME message")
+	}
+
+	boundary, err := signedBoundary(string(message[:headerEnd]))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	delim := []byte("\r\n--" + boundary)
+	body := message[headerEnd+2:]
+
+	parts := bytes.Split(body, delim)
+	if len(parts) != 4 {
+		return nil, nil, errors.New("cms: multipart/signed must contain exactly two parts")
+	}
+
+	if !bytes.HasPrefix(parts[3], []byte("--")) {
+		return nil, nil, errors.New("cms: missing closing boundary")
+	}
+
+	entity := bytes.TrimPrefix(parts[1], []byte("\r\n"))
+	sigPart := bytes.TrimPrefix(parts[2], []byte("\r\n"))
+
+	sigStart := bytes.Index(sigPart, []byte("\r\n\r\n"))
+	if sigStart < 0 {
+		return nil, nil, errors.New("cms: malformed signature part")
+	}
+
+	encoded := strings.Join(strings.Fields(string(sigPart[sigStart+4:])), "")
+	der, err := base64.StdEncoding.DecodeString(encoded)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sd, err := Parse(der)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verified, err := sd.Verify(entity, keys)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entity, verified, nil
+}
+
+// Boundary of a multipart/signed Content-Type header
+func signedBoundary(headers string) (string, error) {
+	for _, line := range strings.Split(strings.NewReplacer("\r\n\t", " ", "\r\n ", " ").Replace(headers), "\r\n") {
+		name, value, ok := strings.Cut(line, ":")
+
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "Content-Type") {
+			continue
+		}
+
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+
+		if err != nil {
+			return "", err
+		}
+
+		if mediaType != "multipart/signed" || params["boundary"] == "" {
+			break
+		}
+
+		return params["boundary"], nil
+	}
+
+	return "", errors.New("cms: message is not multipart/signed")
+}
@@ -0,0 +1,244 @@
+package cms
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/asn1"
+	"errors"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Trusted SLH-DSA public key used for verification
+type VerifierKey struct {
+	ParameterSet string
+	PublicKey    slhdsa.PublicKey
+
+	// Subject key identifier, defaults to the SHA-1 of the public key bytes when nil
+	SubjectKeyID []byte
+}
+
+// Parse a DER encoded ContentInfo carrying a SignedData
+func Parse(der []byte) (*SignedData, error) {
+	var ci contentInfo
+
+	rest, err := asn1.Unmarshal(der, &ci)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) != 0 {
+		return nil, errors.New("cms: trailing data after ContentInfo")
+	}
+
+	if !ci.ContentType.Equal(OIDSignedData) {
+		return nil, errors.New("cms: content type is not signed-data")
+	}
+
+	var sd signedData
+
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, err
+	}
+
+	out := &SignedData{
+		ContentType:  sd.EncapContentInfo.EContentType,
+		Certificates: sd.Certificates.FullBytes,
+	}
+
+	if len(sd.EncapContentInfo.EContent.FullBytes) != 0 {
+		content := []byte{}
+
+		if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent.Bytes, &content); err != nil {
+			return nil, err
+		}
+
+		out.Content = content
+	}
+
+	for _, si := range sd.SignerInfos {
+		parsed, err := parseSignerInfo(si)
+
+		if err != nil {
+			return nil, err
+		}
+
+		out.Signers = append(out.Signers, parsed)
+	}
+
+	return out, nil
+}
+
+func parseSignerInfo(si signerInfo) (SignerInfo, error) {
+	paramSet, err := ParameterSetFromOID(si.SignatureAlgorithm.Algorithm)
+
+	if err != nil {
+		return SignerInfo{}, err
+	}
+
+	if len(si.SignatureAlgorithm.Parameters.FullBytes) != 0 {
+		return SignerInfo{}, errors.New("cms: SLH-DSA algorithm parameters must be absent")
+	}
+
+	out := SignerInfo{
+		ParameterSet:    paramSet,
+		DigestAlgorithm: si.DigestAlgorithm.Algorithm,
+		Signature:       si.Signature,
+	}
+
+	switch {
+	case si.SID.Class == asn1.ClassContextSpecific && si.SID.Tag == 0:
+		out.SubjectKeyID = si.SID.Bytes
+	case si.SID.Class == asn1.ClassUniversal && si.SID.Tag == asn1.TagSequence:
+		var ias issuerAndSerialNumber
+
+		if _, err := asn1.Unmarshal(si.SID.FullBytes, &ias); err != nil {
+			return SignerInfo{}, err
+		}
+
+		out.Issuer = ias.Issuer.FullBytes
+		out.SerialNumber = ias.SerialNumber
+	default:
+		return SignerInfo{}, errors.New("cms: unknown signer identifier")
+	}
+
+	if len(si.SignedAttrs.FullBytes) == 0 {
+		return SignerInfo{}, errors.New("cms: signed attributes are required")
+	}
+
+	// Signature covers the DER encoding with an explicit SET OF tag
+	out.signedAttrs = append([]byte{0x31}, si.SignedAttrs.FullBytes[1:]...)
+
+	var attrs []attribute
+
+	if _, err := asn1.UnmarshalWithParams(out.signedAttrs, &attrs, "set"); err != nil {
+		return SignerInfo{}, err
+	}
+
+	for _, a := range attrs {
+		if len(a.Values) != 1 {
+			return SignerInfo{}, errors.New("cms: signed attribute must have exactly one value")
+		}
+
+		v := a.Values[0].FullBytes
+		out.Attributes = append(out.Attributes, Attribute{Type: a.Type, Value: v})
+
+		switch {
+		case a.Type.Equal(OIDContentType):
+			_, err = asn1.Unmarshal(v, &out.contentType)
+		case a.Type.Equal(OIDMessageDigest):
+			_, err = asn1.Unmarshal(v, &out.messageDigest)
+		case a.Type.Equal(OIDSigningTime):
+			_, err = asn1.Unmarshal(v, &out.SigningTime)
+		}
+
+		if err != nil {
+			return SignerInfo{}, err
+		}
+	}
+
+	if out.contentType == nil || out.messageDigest == nil {
+		return SignerInfo{}, errors.New("cms: content-type and message-digest attributes are required")
+	}
+
+	return out, nil
+}
+
+// Verify at least one SignerInfo against the trusted keys
+//
+// `detached` supplies the content of a detached signature and must be nil otherwise,
+// ErrAttachedContent is returned when both are present.
+// Returns the SignerInfos that verified.
+func (sd *SignedData) Verify(detached []byte, keys []VerifierKey) ([]SignerInfo, error) {
+	content := sd.Content
+
+	if content != nil && detached != nil {
+		return nil, ErrAttachedContent
+	}
+
+	if content == nil {
+		if detached == nil {
+			return nil, ErrMissingContent
+		}
+
+		content = detached
+	}
+
+	var verified []SignerInfo
+	var lastErr error = ErrNoSignature
+
+	for _, si := range sd.Signers {
+		for _, k := range keys {
+			if !si.matches(k) {
+				continue
+			}
+
+			if err := si.verify(content, sd.ContentType, k); err != nil {
+				lastErr = err
+				continue
+			}
+
+			verified = append(verified, si)
+			break
+		}
+	}
+
+	if len(verified) == 0 {
+		return nil, lastErr
+	}
+
+	return verified, nil
+}
+
+func (si SignerInfo) matches(k VerifierKey) bool {
+	if k.ParameterSet != si.ParameterSet || si.SubjectKeyID == nil {
+		return false
+	}
+
+	ski := k.SubjectKeyID
+	if ski == nil {
+		ski = SubjectKeyID(k.PublicKey)
+	}
+
+	return bytes.Equal(ski, si.SubjectKeyID)
+}
+
+func (si SignerInfo) verify(content []byte, contentType asn1.ObjectIdentifier, k VerifierKey) error {
+	if !si.contentType.Equal(contentType) {
+		return errors.New("cms: content-type attribute does not match encapsulated content type")
+	}
+
+	// RFC 9814 Section 4 fixes the digest algorithm of each parameter set
+	if !si.DigestAlgorithm.Equal(digestPairing[si.ParameterSet]) {
+		return ErrDigestAlgorithm
+	}
+
+	digest, err := Digest(si.DigestAlgorithm, content)
+
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare(digest, si.messageDigest) != 1 {
+		return ErrDigestMismatch
+	}
+
+	ctx, err := slhdsa.New(si.ParameterSet)
+
+	if err != nil {
+		return err
+	}
+
+	ok, err := ctx.VerifySignature(k.PublicKey, si.signedAttrs, si.Signature, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+// Package composite implements composite signatures pairing SLH-DSA with
+// Ed25519 or ECDSA, following the construction of the IETF LAMPS composite
+// signatures draft (draft-ietf-lamps-pq-composite-sigs).
+//
+// A composite signature is valid only when both component signatures verify,
+// so it stays secure as long as either algorithm is unbroken.
+//
+// The draft registers object identifiers for ML-DSA composites only. The
+// SLH-DSA combinations here use identifiers under a UUID arc (2.25, ITU-T
+// X.667) and should be treated as provisional.
+package composite
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Traditional component algorithms
+const (
+	Ed25519   = "Ed25519"
+	ECDSAP256 = "ECDSA-P256"
+	ECDSAP384 = "ECDSA-P384"
+)
+
+// Message representative prefix, "CompositeAlgorithmSignatures2025" in ASCII
+const Prefix = "CompositeAlgorithmSignatures2025"
+
+// Provisional arc for SLH-DSA composite identifiers, followed by the parameter
+// set and traditional algorithm indices
+const oidArc = "2.25.150776629481613880767110125913770926539"
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("composite: unsupported algorithm combination")
+	ErrInvalidKey           = errors.New("composite: invalid key encoding")
+	ErrInvalidSignature     = errors.New("composite: invalid signature")
+	ErrContextTooLong       = errors.New("composite: context longer than 255 bytes")
+)
+
+// SLH-DSA parameter sets in identifier order
+var parameterSets = []string{
+	slhdsa.ParameterSet.SLHDSA_SHA2_128s,
+	slhdsa.ParameterSet.SLHDSA_SHA2_128f,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192s,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192f,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256s,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256f,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128s,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128f,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192s,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192f,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256s,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256f,
+}
+
+// Traditional algorithms in identifier order
+var traditionals = []string{Ed25519, ECDSAP256, ECDSAP384}
+
+// SLH-DSA signature sizes (FIPS 205 Table 2)
+var signatureSizes = map[string]int{
+	slhdsa.ParameterSet.SLHDSA_SHA2_128s:  7856,
+	slhdsa.ParameterSet.SLHDSA_SHA2_128f:  17088,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192s:  16224,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192f:  35664,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256s:  29792,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256f:  49856,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128s: 7856,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128f: 17088,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192s: 16224,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192f: 35664,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256s: 29792,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256f: 49856,
+}
+
+// Composite algorithm combination
+type algorithm struct {
+	paramSet    string
+	traditional string
+	label       string
+	oid         []byte
+}
+
+var algorithms []*algorithm
+
+func init() {
+	for i, paramSet := range parameterSets {
+		for j, trad := range traditionals {
+			oid, err := x509.ParseOID(fmt.Sprintf("%s.%d.%d", oidArc, i+1, j+1))
+
+			if err != nil {
+				panic(err)
+			}
+
+			der, _ := oid.MarshalBinary()
+
+			algorithms = append(algorithms, &algorithm{
+				paramSet:    paramSet,
+				traditional: trad,
+				label:       "COMPSIG-SLHDSA-" + paramSet[len("SLH-DSA-"):] + "-" + trad + "-SHA512",
+				oid:         der,
+			})
+		}
+	}
+}
+
+func lookup(paramSet, traditional string) (*algorithm, error) {
+	for _, alg := range algorithms {
+		if alg.paramSet == paramSet && alg.traditional == traditional {
+			return alg, nil
+		}
+	}
+
+	return nil, ErrUnsupportedAlgorithm
+}
+
+func lookupOID(oid []byte) (*algorithm, error) {
+	for _, alg := range algorithms {
+		if string(alg.oid) == string(oid) {
+			return alg, nil
+		}
+	}
+
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// Name of a composite algorithm, e.g. "SLH-DSA-SHA2-128s-Ed25519"
+func Name(paramSet, traditional string) (string, error) {
+	if _, err := lookup(paramSet, traditional); err != nil {
+		return "", err
+	}
+
+	return paramSet + "-" + traditional, nil
+}
+
+// Domain separation label of a composite algorithm
+func Label(paramSet, traditional string) (string, error) {
+	alg, err := lookup(paramSet, traditional)
+
+	if err != nil {
+		return "", err
+	}
+
+	return alg.label, nil
+}
+
+// Dotted object identifier of a composite algorithm
+func OID(paramSet, traditional string) (string, error) {
+	alg, err := lookup(paramSet, traditional)
+
+	if err != nil {
+		return "", err
+	}
+
+	var oid x509.OID
+	if err := oid.UnmarshalBinary(alg.oid); err != nil {
+		return "", err
+	}
+
+	return oid.String(), nil
+}
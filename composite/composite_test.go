@@ -0,0 +1,158 @@
+package composite_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/composite"
+)
+
+var paramSet = slhdsa.ParameterSet.SLHDSA_SHA2_128f
+
+func TestSignVerify(t *testing.T) {
+	msg := []byte("message")
+	ctx := []byte("context")
+
+	for _, trad := range []string{composite.Ed25519, composite.ECDSAP256, composite.ECDSAP384} {
+		t.Run(trad, func(t *testing.T) {
+			sk, err := composite.GenerateKey(paramSet, trad)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pk, err := sk.Public()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if name, _ := pk.Algorithm(); name != paramSet+"-"+trad {
+				t.Errorf("unexpected algorithm %q", name)
+			}
+
+			sig, err := sk.Sign(msg, ctx)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := pk.Verify(msg, ctx, sig); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := pk.Verify(msg, []byte("other"), sig); !errors.Is(err, composite.ErrInvalidSignature) {
+				t.Errorf("verified under another context: %v", err)
+			}
+
+			if err := pk.Verify([]byte("other"), ctx, sig); err == nil {
+				t.Error("verified another message")
+			}
+
+			// Corrupting either component must fail verification
+			for _, i := range []int{10, len(sig) - 5} {
+				bad := bytes.Clone(sig)
+				bad[i] ^= 1
+
+				if err := pk.Verify(msg, ctx, bad); err == nil {
+					t.Errorf("verified with byte %d corrupted", i)
+				}
+			}
+		})
+	}
+}
+
+func TestComponentStripping(t *testing.T) {
+	sk, _ := composite.GenerateKey(paramSet, composite.Ed25519)
+	pk, _ := sk.Public()
+	msg := []byte("message")
+
+	sig, err := sk.Sign(msg, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A standalone Ed25519 signature over the bare message must not substitute
+	// for the domain-separated component
+	trad := sk.Traditional.(ed25519.PrivateKey)
+	forged := append(bytes.Clone(sig[:len(sig)-ed25519.SignatureSize]), ed25519.Sign(trad, msg)...)
+
+	if err := pk.Verify(msg, nil, forged); err == nil {
+		t.Error("verified with a non-composite Ed25519 signature")
+	}
+
+	// Signatures are bound to the combination through the label
+	other, _ := composite.GenerateKey(paramSet, composite.ECDSAP256)
+	otherPub, _ := other.Public()
+	otherPub.PQ = pk.PQ
+
+	if err := otherPub.Verify(msg, nil, sig); err == nil {
+		t.Error("verified under another combination")
+	}
+}
+
+func TestDER(t *testing.T) {
+	for _, trad := range []string{composite.Ed25519, composite.ECDSAP384} {
+		sk, _ := composite.GenerateKey(paramSet, trad)
+		pk, _ := sk.Public()
+
+		pubDER, err := composite.MarshalPKIXPublicKey(pk)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pk2, err := composite.ParsePKIXPublicKey(pubDER)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		privDER, err := composite.MarshalPKCS8PrivateKey(sk)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sk2, err := composite.ParsePKCS8PrivateKey(privDER)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		a, _ := pk.Bytes()
+		b, _ := pk2.Bytes()
+
+		if !bytes.Equal(a, b) {
+			t.Errorf("%s: public key changed in round trip", trad)
+		}
+
+		sig, err := sk2.Sign([]byte("message"), nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := pk.Verify([]byte("message"), nil, sig); err != nil {
+			t.Errorf("%s: %v", trad, err)
+		}
+
+		if _, err := composite.ParsePKIXPublicKey(privDER); err == nil {
+			t.Errorf("%s: parsed a private key as public", trad)
+		}
+	}
+
+	oid, err := composite.OID(paramSet, composite.Ed25519)
+
+	if err != nil || oid != "2.25.150776629481613880767110125913770926539.2.1" {
+		t.Errorf("unexpected OID %q %v", oid, err)
+	}
+
+	if _, err := composite.GenerateKey(paramSet, "RSA"); !errors.Is(err, composite.ErrUnsupportedAlgorithm) {
+		t.Errorf("expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+}
@@ -0,0 +1,382 @@
+package composite
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"math/big"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Composite public key
+type PublicKey struct {
+	ParameterSet string
+	PQ           slhdsa.PublicKey
+
+	// ed25519.PublicKey or *ecdsa.PublicKey on P-256 or P-384
+	Traditional crypto.PublicKey
+}
+
+// Composite private key
+type PrivateKey struct {
+	ParameterSet string
+	PQ           slhdsa.PrivateKey
+
+	// ed25519.PrivateKey or *ecdsa.PrivateKey on P-256 or P-384
+	Traditional crypto.Signer
+}
+
+// Name of the traditional algorithm of a public key
+func traditionalName(pub crypto.PublicKey) (string, error) {
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		if len(k) == ed25519.PublicKeySize {
+			return Ed25519, nil
+		}
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return ECDSAP256, nil
+		case elliptic.P384():
+			return ECDSAP384, nil
+		}
+	}
+
+	return "", ErrUnsupportedAlgorithm
+}
+
+func curve(traditional string) elliptic.Curve {
+	if traditional == ECDSAP384 {
+		return elliptic.P384()
+	}
+
+	return elliptic.P256()
+}
+
+// Generate a composite key pair with fresh component keys
+func GenerateKey(paramSet, traditional string) (*PrivateKey, error) {
+	if _, err := lookup(paramSet, traditional); err != nil {
+		return nil, err
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	sk, _, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var trad crypto.Signer
+
+	if traditional == Ed25519 {
+		_, trad, err = ed25519.GenerateKey(rand.Reader)
+	} else {
+		trad, err = ecdsa.GenerateKey(curve(traditional), rand.Reader)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PrivateKey{ParameterSet: paramSet, PQ: sk, Traditional: trad}, nil
+}
+
+func (k *PrivateKey) algorithm() (*algorithm, error) {
+	if k.Traditional == nil {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	trad, err := traditionalName(k.Traditional.Public())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lookup(k.ParameterSet, trad)
+}
+
+func (k *PublicKey) algorithm() (*algorithm, error) {
+	trad, err := traditionalName(k.Traditional)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return lookup(k.ParameterSet, trad)
+}
+
+// Composite public key of the private key
+func (k *PrivateKey) Public() (*PublicKey, error) {
+	if _, err := k.algorithm(); err != nil {
+		return nil, err
+	}
+
+	pk, err := slhdsa.PublicKeyFromPrivateKey(k.ParameterSet, k.PQ)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublicKey{ParameterSet: k.ParameterSet, PQ: pk, Traditional: k.Traditional.Public()}, nil
+}
+
+// Name of the composite algorithm of the key
+func (k *PublicKey) Algorithm() (string, error) {
+	alg, err := k.algorithm()
+
+	if err != nil {
+		return "", err
+	}
+
+	return Name(alg.paramSet, alg.traditional)
+}
+
+// Raw key, the SLH-DSA public key followed by the Ed25519 key or uncompressed ECDSA point
+func (k *PublicKey) Bytes() ([]byte, error) {
+	if _, err := k.algorithm(); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, k.PQ.KeyBytes...)
+
+	switch t := k.Traditional.(type) {
+	case ed25519.PublicKey:
+		return append(out, t...), nil
+	case *ecdsa.PublicKey:
+		pub, err := t.ECDH()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return append(out, pub.Bytes()...), nil
+	}
+
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// Raw key, the SLH-DSA private key followed by the Ed25519 seed or DER ECPrivateKey
+func (k *PrivateKey) Bytes() ([]byte, error) {
+	if _, err := k.algorithm(); err != nil {
+		return nil, err
+	}
+
+	out := append([]byte{}, k.PQ.KeyBytes...)
+
+	switch t := k.Traditional.(type) {
+	case ed25519.PrivateKey:
+		return append(out, t.Seed()...), nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(t)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return append(out, der...), nil
+	}
+
+	return nil, ErrUnsupportedAlgorithm
+}
+
+func parsePublicKey(alg *algorithm, raw []byte) (*PublicKey, error) {
+	ctx, _ := slhdsa.New(alg.paramSet)
+	pqLen := publicKeySize(alg.paramSet)
+
+	if len(raw) < pqLen {
+		return nil, ErrInvalidKey
+	}
+
+	pq, err := ctx.GetPublicKeyFromBytes(raw[:pqLen])
+
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	k := &PublicKey{ParameterSet: alg.paramSet, PQ: pq}
+	rest := raw[pqLen:]
+
+	if alg.traditional == Ed25519 {
+		if len(rest) != ed25519.PublicKeySize {
+			return nil, ErrInvalidKey
+		}
+
+		k.Traditional = ed25519.PublicKey(append([]byte{}, rest...))
+
+		return k, nil
+	}
+
+	// Validate the point before splitting its coordinates
+	ecdhCurve := ecdh.P256()
+	if alg.traditional == ECDSAP384 {
+		ecdhCurve = ecdh.P384()
+	}
+
+	if _, err := ecdhCurve.NewPublicKey(rest); err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	size := (len(rest) - 1) / 2
+	k.Traditional = &ecdsa.PublicKey{
+		Curve: curve(alg.traditional),
+		X:     new(big.Int).SetBytes(rest[1 : 1+size]),
+		Y:     new(big.Int).SetBytes(rest[1+size:]),
+	}
+
+	return k, nil
+}
+
+func parsePrivateKey(alg *algorithm, raw []byte) (*PrivateKey, error) {
+	ctx, _ := slhdsa.New(alg.paramSet)
+	pqLen := 2 * publicKeySize(alg.paramSet)
+
+	if len(raw) < pqLen {
+		return nil, ErrInvalidKey
+	}
+
+	pq, err := ctx.GetPrivateKeyFromBytes(raw[:pqLen])
+
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	k := &PrivateKey{ParameterSet: alg.paramSet, PQ: pq}
+	rest := raw[pqLen:]
+
+	if alg.traditional == Ed25519 {
+		if len(rest) != ed25519.SeedSize {
+			return nil, ErrInvalidKey
+		}
+
+		k.Traditional = ed25519.NewKeyFromSeed(rest)
+
+		return k, nil
+	}
+
+	ec, err := x509.ParseECPrivateKey(rest)
+
+	if err != nil || ec.Curve != curve(alg.traditional) {
+		return nil, ErrInvalidKey
+	}
+
+	k.Traditional = ec
+
+	return k, nil
+}
+
+// SLH-DSA public key size, twice the security parameter n (FIPS 205 Table 2)
+func publicKeySize(paramSet string) int {
+	switch paramSet[len(paramSet)-4 : len(paramSet)-1] {
+	case "128":
+		return 32
+	case "192":
+		return 48
+	}
+
+	return 64
+}
+
+type algorithmIdentifier struct {
+	Algorithm asn1.RawValue
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm algorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// OneAsymmetricKey (RFC 5958) without attributes or public key
+type oneAsymmetricKey struct {
+	Version    int
+	Algorithm  algorithmIdentifier
+	PrivateKey []byte
+}
+
+func (alg *algorithm) identifier() algorithmIdentifier {
+	return algorithmIdentifier{Algorithm: asn1.RawValue{Tag: asn1.TagOID, Bytes: alg.oid}}
+}
+
+// DER SubjectPublicKeyInfo of a composite public key
+func MarshalPKIXPublicKey(k *PublicKey) ([]byte, error) {
+	alg, err := k.algorithm()
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := k.Bytes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: alg.identifier(),
+		PublicKey: asn1.BitString{Bytes: raw, BitLength: 8 * len(raw)},
+	})
+}
+
+// Parse a DER SubjectPublicKeyInfo holding a composite public key
+func ParsePKIXPublicKey(der []byte) (*PublicKey, error) {
+	var spki subjectPublicKeyInfo
+
+	if rest, err := asn1.Unmarshal(der, &spki); err != nil || len(rest) != 0 {
+		return nil, ErrInvalidKey
+	}
+
+	if spki.Algorithm.Algorithm.Tag != asn1.TagOID || spki.PublicKey.BitLength%8 != 0 {
+		return nil, ErrInvalidKey
+	}
+
+	alg, err := lookupOID(spki.Algorithm.Algorithm.Bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePublicKey(alg, spki.PublicKey.Bytes)
+}
+
+// DER PKCS #8 OneAsymmetricKey of a composite private key
+func MarshalPKCS8PrivateKey(k *PrivateKey) ([]byte, error) {
+	alg, err := k.algorithm()
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := k.Bytes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(oneAsymmetricKey{Algorithm: alg.identifier(), PrivateKey: raw})
+}
+
+// Parse a DER PKCS #8 OneAsymmetricKey holding a composite private key
+func ParsePKCS8PrivateKey(der []byte) (*PrivateKey, error) {
+	var key oneAsymmetricKey
+
+	if rest, err := asn1.Unmarshal(der, &key); err != nil || len(rest) != 0 {
+		return nil, ErrInvalidKey
+	}
+
+	if key.Version != 0 || key.Algorithm.Algorithm.Tag != asn1.TagOID {
+		return nil, ErrInvalidKey
+	}
+
+	alg, err := lookupOID(key.Algorithm.Algorithm.Bytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePrivateKey(alg, key.PrivateKey)
+}
@@ -0,0 +1,131 @@
+package composite
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Message representative M' = Prefix || Label || len(ctx) || ctx || SHA512(M)
+func representative(alg *algorithm, message, ctx []byte) ([]byte, error) {
+	if len(ctx) > 255 {
+		return nil, ErrContextTooLong
+	}
+
+	ph := sha512.Sum512(message)
+
+	out := append([]byte(Prefix), alg.label...)
+	out = append(out, byte(len(ctx)))
+	out = append(out, ctx...)
+
+	return append(out, ph[:]...), nil
+}
+
+// ECDSA digest of the message representative, matched to the curve size
+func ecdsaDigest(traditional string, m []byte) []byte {
+	if traditional == ECDSAP384 {
+		h := sha512.Sum384(m)
+		return h[:]
+	}
+
+	h := sha256.Sum256(m)
+	return h[:]
+}
+
+// Sign a message with an optional context of at most 255 bytes
+//
+// The signature is the SLH-DSA signature, with the label as its context,
+// followed by the Ed25519 signature or DER ECDSA signature over M'.
+func (k *PrivateKey) Sign(message, ctx []byte) ([]byte, error) {
+	alg, err := k.algorithm()
+
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := representative(alg, message, ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pq, _ := slhdsa.New(alg.paramSet)
+	sig, err := pq.GenerateSignature(k.PQ, m, []byte(alg.label), true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var trad []byte
+
+	switch t := k.Traditional.(type) {
+	case ed25519.PrivateKey:
+		trad = ed25519.Sign(t, m)
+	case *ecdsa.PrivateKey:
+		trad, err = ecdsa.SignASN1(rand.Reader, t, ecdsaDigest(alg.traditional, m))
+	default:
+		// Signers backed by hardware, the hash must match ecdsaDigest
+		opts := crypto.Hash(0)
+		if alg.traditional == ECDSAP256 {
+			opts = crypto.SHA256
+		} else if alg.traditional == ECDSAP384 {
+			opts = crypto.SHA384
+		}
+
+		digest := m
+		if opts != 0 {
+			digest = ecdsaDigest(alg.traditional, m)
+		}
+
+		trad, err = t.Sign(rand.Reader, digest, opts)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(sig, trad...), nil
+}
+
+// Verify a composite signature, both component signatures must be valid
+func (k *PublicKey) Verify(message, ctx, signature []byte) error {
+	alg, err := k.algorithm()
+
+	if err != nil {
+		return err
+	}
+
+	m, err := representative(alg, message, ctx)
+
+	if err != nil {
+		return err
+	}
+
+	n := signatureSizes[alg.paramSet]
+	if len(signature) <= n {
+		return ErrInvalidSignature
+	}
+
+	pq, _ := slhdsa.New(alg.paramSet)
+	pqOK, err := pq.VerifySignature(k.PQ, m, signature[:n], []byte(alg.label), slhdsa.PreHashAlgorithm.Pure)
+
+	var tradOK bool
+
+	switch t := k.Traditional.(type) {
+	case ed25519.PublicKey:
+		tradOK = ed25519.Verify(t, m, signature[n:])
+	case *ecdsa.PublicKey:
+		tradOK = ecdsa.VerifyASN1(t, ecdsaDigest(alg.traditional, m), signature[n:])
+	}
+
+	if err != nil || !pqOK || !tradOK {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
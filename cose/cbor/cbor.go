@@ -0,0 +1,198 @@
+// Package cbor is a minimal CBOR (RFC 8949) encoder and decoder covering the
+// data model needed by COSE and CWT.
+//
+// Encoding follows the core deterministic encoding requirements: preferred
+// (shortest) argument lengths, definite lengths only and map keys sorted by
+// their encoded bytes.
+//
+// Supported Go values: nil, bool, signed and unsigned integers, float64,
+// []byte, string, []any, map[any]any, Tag and RawMessage. Decoding produces
+// int64, []byte, string, []any, map[any]any, Tag, bool, float64 and nil.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Major types
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+)
+
+// Maximum nesting depth accepted by the decoder
+const maxDepth = 64
+
+var (
+	ErrUnexpectedEOF  = errors.New("cbor: unexpected end of data")
+	ErrTrailingData   = errors.New("cbor: trailing data")
+	ErrIndefinite     = errors.New("cbor: indefinite length items are not supported")
+	ErrIntegerRange   = errors.New("cbor: integer out of range")
+	ErrMaxDepth       = errors.New("cbor: maximum nesting depth exceeded")
+	ErrUnhashableKey  = errors.New("cbor: unsupported map key type")
+	ErrDuplicateKey   = errors.New("cbor: duplicate map key")
+	ErrInvalidUTF8    = errors.New("cbor: invalid UTF-8 text string")
+	ErrUnsupportedVal = errors.New("cbor: unsupported value")
+)
+
+// Tagged data item
+type Tag struct {
+	Number  uint64
+	Content any
+}
+
+// Pre-encoded data item, written verbatim by Marshal
+type RawMessage []byte
+
+// Encode a value
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	m := major << 5
+
+	switch {
+	case arg < 24:
+		buf.WriteByte(m | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.Write([]byte{m | 24, byte(arg)})
+	case arg <= math.MaxUint16:
+		buf.WriteByte(m | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= math.MaxUint32:
+		buf.WriteByte(m | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		buf.WriteByte(m | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
+
+func writeInt(buf *bytes.Buffer, n int64) {
+	if n >= 0 {
+		writeHead(buf, majorUnsigned, uint64(n))
+	} else {
+		writeHead(buf, majorNegative, uint64(-(n + 1)))
+	}
+}
+
+func encode(buf *bytes.Buffer, v any) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteByte(0xf6)
+	case bool:
+		if x {
+			buf.WriteByte(0xf5)
+		} else {
+			buf.WriteByte(0xf4)
+		}
+	case int:
+		writeInt(buf, int64(x))
+	case int8:
+		writeInt(buf, int64(x))
+	case int16:
+		writeInt(buf, int64(x))
+	case int32:
+		writeInt(buf, int64(x))
+	case int64:
+		writeInt(buf, x)
+	case uint:
+		writeHead(buf, majorUnsigned, uint64(x))
+	case uint8:
+		writeHead(buf, majorUnsigned, uint64(x))
+	case uint16:
+		writeHead(buf, majorUnsigned, uint64(x))
+	case uint32:
+		writeHead(buf, majorUnsigned, uint64(x))
+	case uint64:
+		writeHead(buf, majorUnsigned, x)
+	case float64:
+		buf.WriteByte(0xfb)
+		buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(x)))
+	case []byte:
+		writeHead(buf, majorBytes, uint64(len(x)))
+		buf.Write(x)
+	case string:
+		writeHead(buf, majorText, uint64(len(x)))
+		buf.WriteString(x)
+	case RawMessage:
+		buf.Write(x)
+	case Tag:
+		writeHead(buf, majorTag, x.Number)
+		return encode(buf, x.Content)
+	case []any:
+		writeHead(buf, majorArray, uint64(len(x)))
+
+		for _, item := range x {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[any]any:
+		return encodeMap(buf, x)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedVal, v)
+	}
+
+	return nil
+}
+
+// Deterministically encoded map, keys sorted by their encoding
+func encodeMap(buf *bytes.Buffer, m map[any]any) error {
+	type entry struct {
+		key, value []byte
+	}
+
+	entries := make([]entry, 0, len(m))
+
+	for k, v := range m {
+		key, err := Marshal(k)
+
+		if err != nil {
+			return err
+		}
+
+		value, err := Marshal(v)
+
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry{key, value})
+	}
+
+	slices.SortFunc(entries, func(a, b entry) int {
+		return bytes.Compare(a.key, b.key)
+	})
+
+	writeHead(buf, majorMap, uint64(len(entries)))
+
+	for i, e := range entries {
+		if i > 0 && bytes.Equal(entries[i-1].key, e.key) {
+			return ErrDuplicateKey
+		}
+
+		buf.Write(e.key)
+		buf.Write(e.value)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package cbor_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/skuuzie/go-slhdsa/cose/cbor"
+)
+
+// Vectors from RFC 8949 Appendix A
+func TestEncodeVectors(t *testing.T) {
+	cases := []struct {
+		v        any
+		expected string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{1000000, "1a000f4240"},
+		{-1, "20"},
+		{-1000, "3903e7"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"IETF", "6449455446"},
+		{[]any{1, []any{2, 3}, []any{4, 5}}, "8301820203820405"},
+		{map[any]any{"a": 1, "b": []any{2, 3}}, "a26161016162820203"},
+		{map[any]any{10: 1, -1: 2, 100: 3}, "a30a011864032002"},
+		{cbor.Tag{Number: 1, Content: 1363896240}, "c11a514b67b0"},
+		{true, "f5"},
+		{nil, "f6"},
+	}
+
+	for _, c := range cases {
+		encoded, err := cbor.Marshal(c.v)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if hex.EncodeToString(encoded) != c.expected {
+			t.Errorf("%v: expected %v, got %x", c.v, c.expected, encoded)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	v := map[any]any{
+		int64(1):  "text",
+		int64(-2): []byte{0xde, 0xad},
+		"list":    []any{int64(-1000), true, nil, cbor.Tag{Number: 18, Content: []any{}}},
+	}
+
+	encoded, err := cbor.Marshal(v)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := cbor.Unmarshal(encoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(decoded, v) {
+		t.Errorf("expected %v, got %v", v, decoded)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	cases := map[string]error{
+		"5f":                 cbor.ErrIndefinite,
+		"44010203":           cbor.ErrUnexpectedEOF,
+		"0000":               cbor.ErrTrailingData,
+		"a2010101":           cbor.ErrDuplicateKey,
+		"62c328":             cbor.ErrInvalidUTF8,
+		"9bffffffffffffffff": cbor.ErrUnexpectedEOF,
+	}
+
+	for input, expected := range cases {
+		data, _ := hex.DecodeString(input)
+
+		if _, err := cbor.Unmarshal(data); err != expected {
+			t.Errorf("%v: expected %v, got %v", input, expected, err)
+		}
+	}
+
+	deep := bytes.Repeat([]byte{0x81}, 100)
+	if _, err := cbor.Unmarshal(append(deep, 0)); err != cbor.ErrMaxDepth {
+		t.Errorf("expected ErrMaxDepth, got %v", err)
+	}
+}
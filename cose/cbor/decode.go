@@ -0,0 +1,251 @@
+package cbor
+
+import (
+	"encoding/binary"
+	"math"
+	"unicode/utf8"
+)
+
+// Decode a single data item, trailing bytes are rejected
+func Unmarshal(data []byte) (any, error) {
+	v, rest, err := UnmarshalFirst(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) != 0 {
+		return nil, ErrTrailingData
+	}
+
+	return v, nil
+}
+
+// Decode the first data item and return the remaining bytes
+func UnmarshalFirst(data []byte) (any, []byte, error) {
+	d := decoder{data: data}
+	v, err := d.decode(0)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return v, d.data[d.off:], nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+func (d *decoder) read(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, ErrUnexpectedEOF
+	}
+
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+
+	return b, nil
+}
+
+// Initial byte and argument of the next data item
+func (d *decoder) head() (byte, byte, uint64, error) {
+	b, err := d.read(1)
+
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	major, info := b[0]>>5, b[0]&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		b, err = d.read(1)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(b[0]), nil
+	case info == 25:
+		b, err = d.read(2)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err = d.read(4)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err = d.read(8)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		return major, info, binary.BigEndian.Uint64(b), nil
+	case info == 31:
+		return 0, 0, 0, ErrIndefinite
+	}
+
+	return 0, 0, 0, ErrUnsupportedVal
+}
+
+func (d *decoder) decode(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, ErrMaxDepth
+	}
+
+	major, info, arg, err := d.head()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case majorUnsigned:
+		if arg > math.MaxInt64 {
+			return nil, ErrIntegerRange
+		}
+
+		return int64(arg), nil
+
+	case majorNegative:
+		if arg > math.MaxInt64 {
+			return nil, ErrIntegerRange
+		}
+
+		return -1 - int64(arg), nil
+
+	case majorBytes:
+		b, err := d.read(arg)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte{}, b...), nil
+
+	case majorText:
+		b, err := d.read(arg)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !utf8.Valid(b) {
+			return nil, ErrInvalidUTF8
+		}
+
+		return string(b), nil
+
+	case majorArray:
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, ErrUnexpectedEOF
+		}
+
+		arr := make([]any, 0, arg)
+
+		for range arg {
+			item, err := d.decode(depth + 1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, item)
+		}
+
+		return arr, nil
+
+	case majorMap:
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, ErrUnexpectedEOF
+		}
+
+		m := make(map[any]any, arg)
+
+		for range arg {
+			k, err := d.decode(depth + 1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, ErrUnhashableKey
+			}
+
+			if _, dup := m[k]; dup {
+				return nil, ErrDuplicateKey
+			}
+
+			v, err := d.decode(depth + 1)
+
+			if err != nil {
+				return nil, err
+			}
+
+			m[k] = v
+		}
+
+		return m, nil
+
+	case majorTag:
+		content, err := d.decode(depth + 1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return Tag{Number: arg, Content: content}, nil
+	}
+
+	// Simple values and floats
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return halfToFloat(uint16(arg)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	}
+
+	return nil, ErrUnsupportedVal
+}
+
+// IEEE 754 half precision to float64
+func halfToFloat(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,219 @@
+// Package cose implements COSE_Sign1, COSE_Sign (RFC 9052) and CBOR Web
+// Tokens (RFC 8392) with SLH-DSA signers (draft-ietf-cose-sphincs-plus).
+package cose
+
+import (
+	"errors"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/cose/cbor"
+)
+
+// Common header parameter labels (RFC 9052 Section 3.1)
+const (
+	HeaderAlgorithm   = 1
+	HeaderCritical    = 2
+	HeaderContentType = 3
+	HeaderKeyID       = 4
+)
+
+// CBOR tags
+const (
+	TagSign1 = 18
+	TagSign  = 98
+	TagCWT   = 61
+)
+
+// COSE algorithm values of the SLH-DSA parameter sets
+//
+// Only the parameter sets with IANA early allocations are assigned.
+var algorithms = map[string]int64{
+	slhdsa.ParameterSet.SLHDSA_SHA2_128s:  -51,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128s: -52,
+	slhdsa.ParameterSet.SLHDSA_SHA2_128f:  -53,
+}
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("cose: unsupported algorithm")
+	ErrMalformed            = errors.New("cose: malformed message")
+	ErrInvalidSignature     = errors.New("cose: invalid signature")
+	ErrNoMatchingKey        = errors.New("cose: no trusted key matches the message")
+	ErrEmbeddedPayload      = errors.New("cose: detached payload given for a message with an embedded payload")
+)
+
+// COSE algorithm value of an SLH-DSA parameter set
+func Algorithm(paramSet string) (int64, error) {
+	alg, ok := algorithms[paramSet]
+
+	if !ok {
+		return 0, ErrUnsupportedAlgorithm
+	}
+
+	return alg, nil
+}
+
+// SLH-DSA parameter set of a COSE algorithm value
+func ParameterSet(alg int64) (string, error) {
+	for paramSet, a := range algorithms {
+		if a == alg {
+			return paramSet, nil
+		}
+	}
+
+	return "", ErrUnsupportedAlgorithm
+}
+
+// Header parameters keyed by label
+type Headers map[any]any
+
+// Algorithm value, 0 if absent
+func (h Headers) Algorithm() int64 {
+	alg, _ := h[int64(HeaderAlgorithm)].(int64)
+	return alg
+}
+
+// Key identifier, nil if absent
+func (h Headers) KeyID() []byte {
+	kid, _ := h[int64(HeaderKeyID)].([]byte)
+	return kid
+}
+
+// Encode protected headers as a bstr wrapped map, empty maps encode as a zero-length string
+func encodeProtected(h Headers) ([]byte, error) {
+	if len(h) == 0 {
+		return []byte{}, nil
+	}
+
+	return cbor.Marshal(normalize(h))
+}
+
+func decodeProtected(b []byte) (Headers, error) {
+	if len(b) == 0 {
+		return Headers{}, nil
+	}
+
+	v, err := cbor.Unmarshal(b)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := v.(map[any]any)
+
+	if !ok {
+		return nil, ErrMalformed
+	}
+
+	if _, ok := m[int64(HeaderCritical)]; ok {
+		return nil, errors.New("cose: critical header parameters are not supported")
+	}
+
+	return Headers(m), nil
+}
+
+func decodeUnprotected(v any) (Headers, error) {
+	m, ok := v.(map[any]any)
+
+	if !ok {
+		return nil, ErrMalformed
+	}
+
+	return Headers(m), nil
+}
+
+// Convert Go int labels to int64 so encoded and decoded headers compare equal
+func normalize(h Headers) map[any]any {
+	m := make(map[any]any, len(h))
+
+	for k, v := range h {
+		if i, ok := k.(int); ok {
+			k = int64(i)
+		}
+
+		m[k] = v
+	}
+
+	return m
+}
+
+// SLH-DSA signing key with its COSE key identifier
+type Signer struct {
+	ParameterSet string
+	PrivateKey   slhdsa.PrivateKey
+	KeyID        []byte
+}
+
+// Trusted SLH-DSA verification key
+type Verifier struct {
+	ParameterSet string
+	PublicKey    slhdsa.PublicKey
+	KeyID        []byte
+}
+
+func (s Signer) sign(toBeSigned []byte) ([]byte, error) {
+	ctx, err := slhdsa.New(s.ParameterSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.GenerateSignature(s.PrivateKey, toBeSigned, nil, true, slhdsa.PreHashAlgorithm.Pure)
+}
+
+// Verify with the first key accepted by the algorithm and key identifier
+//
+// The algorithm must be protected, the key identifier may be in either bucket.
+func verifyWithKeys(protected, unprotected Headers, toBeSigned, signature []byte, keys []Verifier) (*Verifier, error) {
+	paramSet, err := ParameterSet(protected.Algorithm())
+
+	if err != nil {
+		return nil, err
+	}
+
+	kid := protected.KeyID()
+	if kid == nil {
+		kid = unprotected.KeyID()
+	}
+
+	var lastErr error = ErrNoMatchingKey
+
+	for i, k := range keys {
+		if k.ParameterSet != paramSet {
+			continue
+		}
+
+		if kid != nil && string(k.KeyID) != string(kid) {
+			continue
+		}
+
+		ctx, _ := slhdsa.New(paramSet)
+		ok, err := ctx.VerifySignature(k.PublicKey, toBeSigned, signature, nil, slhdsa.PreHashAlgorithm.Pure)
+
+		if err == nil && ok {
+			return &keys[i], nil
+		}
+
+		lastErr = ErrInvalidSignature
+	}
+
+	return nil, lastErr
+}
+
+// Signing headers of a signer merged with caller supplied headers
+func signerHeaders(s Signer, protected, unprotected Headers) (Headers, Headers, error) {
+	alg, err := Algorithm(s.ParameterSet)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := Headers(normalize(protected))
+	p[int64(HeaderAlgorithm)] = alg
+
+	u := Headers(normalize(unprotected))
+	if s.KeyID != nil {
+		u[int64(HeaderKeyID)] = s.KeyID
+	}
+
+	return p, u, nil
+}
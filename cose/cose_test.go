@@ -0,0 +1,154 @@
+package cose_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/cose"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+)
+
+func newKey(t *testing.T, paramSet string, kid string) (cose.Signer, cose.Verifier) {
+	sk, pk := testkey.New(t, paramSet)
+
+	return cose.Signer{ParameterSet: paramSet, PrivateKey: sk, KeyID: []byte(kid)},
+		cose.Verifier{ParameterSet: paramSet, PublicKey: pk, KeyID: []byte(kid)}
+}
+
+func TestSign1(t *testing.T) {
+	signer, verifier := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "device-1")
+	aad := []byte("attestation")
+
+	data, err := cose.Sign1([]byte("evidence"), aad, signer, nil, nil, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := cose.ParseSign1(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Protected.Algorithm() != -53 || string(msg.Unprotected.KeyID()) != "device-1" {
+		t.Errorf("unexpected headers %v %v", msg.Protected, msg.Unprotected)
+	}
+
+	if _, err := msg.Verify(nil, aad, []cose.Verifier{verifier}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := msg.Verify(nil, []byte("other"), []cose.Verifier{verifier}); err != cose.ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	// A detached payload never replaces the embedded one
+	if _, err := msg.Verify([]byte("other evidence"), aad, []cose.Verifier{verifier}); err != cose.ErrEmbeddedPayload {
+		t.Errorf("expected ErrEmbeddedPayload, got %v", err)
+	}
+}
+
+func TestSign1Detached(t *testing.T) {
+	signer, verifier := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "k")
+
+	data, err := cose.Sign1([]byte("payload"), nil, signer, nil, nil, true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, _ := cose.ParseSign1(data)
+
+	if msg.Payload != nil {
+		t.Fatal("detached message carries payload")
+	}
+
+	if _, err := msg.Verify([]byte("payload"), nil, []cose.Verifier{verifier}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSign(t *testing.T) {
+	s1, v1 := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "a")
+	s2, v2 := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "b")
+
+	data, err := cose.Sign([]byte("payload"), nil, []cose.Signer{s1, s2}, nil, nil, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := cose.ParseSign(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verified, err := msg.Verify(nil, nil, []cose.Verifier{v1, v2})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(verified) != 2 {
+		t.Errorf("expected 2 verified signatures, got %v", len(verified))
+	}
+
+	if _, err := msg.Verify([]byte("other"), nil, []cose.Verifier{v1, v2}); err != cose.ErrEmbeddedPayload {
+		t.Errorf("expected ErrEmbeddedPayload, got %v", err)
+	}
+}
+
+func TestCOSEKey(t *testing.T) {
+	_, verifier := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "k")
+
+	data, err := cose.MarshalPublicKey(verifier.ParameterSet, verifier.PublicKey, verifier.KeyID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := cose.ParsePublicKey(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.ParameterSet != verifier.ParameterSet || !bytes.Equal(parsed.PublicKey.KeyBytes, verifier.PublicKey.KeyBytes) {
+		t.Errorf("unexpected key %+v", parsed)
+	}
+}
+
+func TestCWT(t *testing.T) {
+	signer, verifier := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "k")
+	now := time.Unix(1700000000, 0)
+
+	token, err := cose.SignCWT(&cose.Claims{
+		Issuer:   "attestation-service",
+		Audience: []string{"verifier"},
+		Expiry:   now.Add(time.Hour),
+		IssuedAt: now,
+		Extra:    map[any]any{int64(-65537): "nonce"},
+	}, signer)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := cose.VerifyCWT(token, []cose.Verifier{verifier}, cose.Validation{Issuer: "attestation-service", Audience: "verifier", Now: now})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Extra[int64(-65537)] != "nonce" || !claims.IssuedAt.Equal(now) {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	if _, err := cose.VerifyCWT(token, []cose.Verifier{verifier}, cose.Validation{Now: now.Add(2 * time.Hour)}); err != cose.ErrTokenExpired {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package cose
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/skuuzie/go-slhdsa/cose/cbor"
+)
+
+// Registered CWT claim keys (RFC 8392 Section 3.1)
+const (
+	ClaimIssuer    = 1
+	ClaimSubject   = 2
+	ClaimAudience  = 3
+	ClaimExpiry    = 4
+	ClaimNotBefore = 5
+	ClaimIssuedAt  = 6
+	ClaimCWTID     = 7
+)
+
+var (
+	ErrTokenExpired     = errors.New("cose: token is expired")
+	ErrTokenNotYetValid = errors.New("cose: token is not valid yet")
+	ErrInvalidIssuer    = errors.New("cose: unexpected issuer")
+	ErrInvalidAudience  = errors.New("cose: token is not intended for this audience")
+)
+
+// CWT claims set
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	Expiry    time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ID        []byte
+
+	// Private claims keyed by integer or text label
+	Extra map[any]any
+}
+
+func (c *Claims) marshal() ([]byte, error) {
+	m := map[any]any{}
+
+	for k, v := range c.Extra {
+		m[k] = v
+	}
+
+	if c.Issuer != "" {
+		m[int64(ClaimIssuer)] = c.Issuer
+	}
+
+	if c.Subject != "" {
+		m[int64(ClaimSubject)] = c.Subject
+	}
+
+	switch len(c.Audience) {
+	case 0:
+	case 1:
+		m[int64(ClaimAudience)] = c.Audience[0]
+	default:
+		aud := make([]any, len(c.Audience))
+		for i, a := range c.Audience {
+			aud[i] = a
+		}
+		m[int64(ClaimAudience)] = aud
+	}
+
+	times := map[int64]time.Time{ClaimExpiry: c.Expiry, ClaimNotBefore: c.NotBefore, ClaimIssuedAt: c.IssuedAt}
+	for k, t := range times {
+		if !t.IsZero() {
+			m[k] = t.Unix()
+		}
+	}
+
+	if c.ID != nil {
+		m[int64(ClaimCWTID)] = c.ID
+	}
+
+	return cbor.Marshal(normalize(m))
+}
+
+func unmarshalClaims(data []byte) (*Claims, error) {
+	v, err := cbor.Unmarshal(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := v.(map[any]any)
+
+	if !ok {
+		return nil, ErrMalformed
+	}
+
+	c := &Claims{Extra: map[any]any{}}
+
+	for k, v := range m {
+		label, _ := k.(int64)
+
+		switch label {
+		case ClaimIssuer:
+			c.Issuer, ok = v.(string)
+		case ClaimSubject:
+			c.Subject, ok = v.(string)
+		case ClaimAudience:
+			switch aud := v.(type) {
+			case string:
+				c.Audience = []string{aud}
+			case []any:
+				for _, a := range aud {
+					s, isString := a.(string)
+					ok = ok && isString
+					c.Audience = append(c.Audience, s)
+				}
+			default:
+				ok = false
+			}
+		case ClaimExpiry, ClaimNotBefore, ClaimIssuedAt:
+			var t time.Time
+			t, ok = numericDate(v)
+
+			switch label {
+			case ClaimExpiry:
+				c.Expiry = t
+			case ClaimNotBefore:
+				c.NotBefore = t
+			default:
+				c.IssuedAt = t
+			}
+		case ClaimCWTID:
+			c.ID, ok = v.([]byte)
+		default:
+			c.Extra[k] = v
+		}
+
+		if !ok {
+			return nil, ErrMalformed
+		}
+	}
+
+	return c, nil
+}
+
+func numericDate(v any) (time.Time, bool) {
+	switch n := v.(type) {
+	case int64:
+		return time.Unix(n, 0), true
+	case float64:
+		return time.Unix(0, int64(n*float64(time.Second))), true
+	}
+
+	return time.Time{}, false
+}
+
+// CWT validation rules
+type Validation struct {
+	// Expected issuer, not checked if empty
+	Issuer string
+
+	// Audience the token must include, not checked if empty
+	Audience string
+
+	// Allowed clock skew
+	Leeway time.Duration
+
+	// Validation time, defaults to the current time
+	Now time.Time
+}
+
+// Validate the registered claims
+func (c *Claims) Validate(v Validation) error {
+	now := v.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if v.Audience != "" && !slices.Contains(c.Audience, v.Audience) {
+		return ErrInvalidAudience
+	}
+
+	if !c.Expiry.IsZero() && !now.Before(c.Expiry.Add(v.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	if !c.NotBefore.IsZero() && now.Add(v.Leeway).Before(c.NotBefore) {
+		return ErrTokenNotYetValid
+	}
+
+	return nil
+}
+
+// Produce a CWT as a COSE_Sign1 message wrapped in the CWT tag
+func SignCWT(claims *Claims, s Signer) ([]byte, error) {
+	payload, err := claims.marshal()
+
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := Sign1(payload, nil, s, nil, nil, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cbor.Marshal(cbor.Tag{Number: TagCWT, Content: cbor.RawMessage(msg)})
+}
+
+// Verify a CWT against the trusted keys and validate its claims
+//
+// The CWT tag is optional.
+func VerifyCWT(data []byte, keys []Verifier, v Validation) (*Claims, error) {
+	item, err := cbor.Unmarshal(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tag, ok := item.(cbor.Tag); ok && tag.Number == TagCWT {
+		if data, err = cbor.Marshal(tag.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	msg, err := ParseSign1(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.Payload == nil {
+		return nil, ErrMalformed
+	}
+
+	if _, err := msg.Verify(nil, nil, keys); err != nil {
+		return nil, err
+	}
+
+	claims, err := unmarshalClaims(msg.Payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := claims.Validate(v); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
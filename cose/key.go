@@ -0,0 +1,84 @@
+package cose
+
+import (
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/cose/cbor"
+)
+
+// Key type of algorithm key pairs
+const KeyTypeAKP = 7
+
+// COSE_Key parameter labels
+const (
+	keyLabelKty  = 1
+	keyLabelKid  = 2
+	keyLabelAlg  = 3
+	keyLabelPub  = -1
+	keyLabelPriv = -2
+)
+
+// Encode an SLH-DSA public key as a COSE_Key
+func MarshalPublicKey(paramSet string, pk slhdsa.PublicKey, kid []byte) ([]byte, error) {
+	alg, err := Algorithm(paramSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key := map[any]any{
+		int64(keyLabelKty): int64(KeyTypeAKP),
+		int64(keyLabelAlg): alg,
+		int64(keyLabelPub): pk.KeyBytes,
+	}
+
+	if kid != nil {
+		key[int64(keyLabelKid)] = kid
+	}
+
+	return cbor.Marshal(key)
+}
+
+// Decode a COSE_Key carrying an SLH-DSA public key
+//
+// Private key material in the COSE_Key is ignored.
+func ParsePublicKey(data []byte) (*Verifier, error) {
+	v, err := cbor.Unmarshal(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := v.(map[any]any)
+
+	if !ok {
+		return nil, ErrMalformed
+	}
+
+	if kty, _ := key[int64(keyLabelKty)].(int64); kty != KeyTypeAKP {
+		return nil, ErrMalformed
+	}
+
+	alg, _ := key[int64(keyLabelAlg)].(int64)
+	paramSet, err := ParameterSet(alg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pub, ok := key[int64(keyLabelPub)].([]byte)
+
+	if !ok {
+		return nil, ErrMalformed
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	pk, err := ctx.GetPublicKeyFromBytes(pub)
+
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := key[int64(keyLabelKid)].([]byte)
+
+	return &Verifier{ParameterSet: paramSet, PublicKey: pk, KeyID: kid}, nil
+}
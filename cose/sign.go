@@ -0,0 +1,213 @@
+package cose
+
+import (
+	"errors"
+
+	"github.com/skuuzie/go-slhdsa/cose/cbor"
+)
+
+// COSE_Signature of a COSE_Sign message
+type Signature struct {
+	Protected   Headers
+	Unprotected Headers
+	Signature   []byte
+
+	rawProtected []byte
+}
+
+// COSE_Sign message
+type SignMessage struct {
+	Protected   Headers
+	Unprotected Headers
+
+	// Payload, nil when detached
+	Payload []byte
+
+	Signatures []Signature
+
+	rawProtected []byte
+}
+
+// Sig_structure of COSE_Sign (RFC 9052 Section 4.4)
+func signToBeSigned(bodyProtected, signProtected, externalAAD, payload []byte) ([]byte, error) {
+	if externalAAD == nil {
+		externalAAD = []byte{}
+	}
+
+	return cbor.Marshal([]any{"Signature", bodyProtected, signProtected, externalAAD, payload})
+}
+
+// Produce a tagged COSE_Sign message with one signature per signer
+//
+// `protected` and `unprotected` are body headers and may be nil.
+func Sign(payload, externalAAD []byte, signers []Signer, protected, unprotected Headers, detached bool) ([]byte, error) {
+	if len(signers) == 0 {
+		return nil, errors.New("cose: at least one signer is required")
+	}
+
+	bodyProtected, err := encodeProtected(protected)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var sigs []any
+
+	for _, s := range signers {
+		p, u, err := signerHeaders(s, nil, nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		signProtected, err := encodeProtected(p)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tbs, err := signToBeSigned(bodyProtected, signProtected, externalAAD, payload)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sig, err := s.sign(tbs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sigs = append(sigs, []any{signProtected, map[any]any(u), sig})
+	}
+
+	var carried any = payload
+	if detached {
+		carried = nil
+	}
+
+	return cbor.Marshal(cbor.Tag{Number: TagSign, Content: []any{bodyProtected, normalize(unprotected), carried, sigs}})
+}
+
+// Parse a tagged or untagged COSE_Sign message
+func ParseSign(data []byte) (*SignMessage, error) {
+	v, err := cbor.Unmarshal(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tag, ok := v.(cbor.Tag); ok {
+		if tag.Number != TagSign {
+			return nil, ErrMalformed
+		}
+
+		v = tag.Content
+	}
+
+	arr, ok := v.([]any)
+
+	if !ok || len(arr) != 4 {
+		return nil, ErrMalformed
+	}
+
+	rawProtected, ok1 := arr[0].([]byte)
+	sigs, ok2 := arr[3].([]any)
+
+	if !ok1 || !ok2 || len(sigs) == 0 {
+		return nil, ErrMalformed
+	}
+
+	msg := &SignMessage{rawProtected: rawProtected}
+
+	if msg.Protected, err = decodeProtected(rawProtected); err != nil {
+		return nil, err
+	}
+
+	if msg.Unprotected, err = decodeUnprotected(arr[1]); err != nil {
+		return nil, err
+	}
+
+	switch payload := arr[2].(type) {
+	case []byte:
+		msg.Payload = payload
+	case nil:
+	default:
+		return nil, ErrMalformed
+	}
+
+	for _, item := range sigs {
+		s, ok := item.([]any)
+
+		if !ok || len(s) != 3 {
+			return nil, ErrMalformed
+		}
+
+		raw, ok1 := s[0].([]byte)
+		sig, ok2 := s[2].([]byte)
+
+		if !ok1 || !ok2 {
+			return nil, ErrMalformed
+		}
+
+		parsed := Signature{Signature: sig, rawProtected: raw}
+
+		if parsed.Protected, err = decodeProtected(raw); err != nil {
+			return nil, err
+		}
+
+		if parsed.Unprotected, err = decodeUnprotected(s[1]); err != nil {
+			return nil, err
+		}
+
+		msg.Signatures = append(msg.Signatures, parsed)
+	}
+
+	return msg, nil
+}
+
+// Verify every signature that a trusted key accepts
+//
+// `detached` supplies the payload of a detached message and must be nil otherwise.
+// Returns the keys that verified, at least one signature must verify.
+func (m *SignMessage) Verify(detached, externalAAD []byte, keys []Verifier) ([]*Verifier, error) {
+	payload := m.Payload
+
+	if payload != nil && detached != nil {
+		return nil, ErrEmbeddedPayload
+	}
+
+	if payload == nil {
+		if detached == nil {
+			return nil, ErrMalformed
+		}
+
+		payload = detached
+	}
+
+	var verified []*Verifier
+	var lastErr error = ErrNoMatchingKey
+
+	for _, s := range m.Signatures {
+		tbs, err := signToBeSigned(m.rawProtected, s.rawProtected, externalAAD, payload)
+
+		if err != nil {
+			return nil, err
+		}
+
+		k, err := verifyWithKeys(s.Protected, s.Unprotected, tbs, s.Signature, keys)
+
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		verified = append(verified, k)
+	}
+
+	if len(verified) == 0 {
+		return nil, lastErr
+	}
+
+	return verified, nil
+}
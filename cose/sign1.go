@@ -0,0 +1,143 @@
+package cose
+
+import (
+	"github.com/skuuzie/go-slhdsa/cose/cbor"
+)
+
+// COSE_Sign1 message
+type Sign1Message struct {
+	Protected   Headers
+	Unprotected Headers
+
+	// Payload, nil when detached
+	Payload []byte
+
+	Signature []byte
+
+	rawProtected []byte
+}
+
+// Sig_structure of COSE_Sign1 (RFC 9052 Section 4.4)
+func sign1ToBeSigned(protected, externalAAD, payload []byte) ([]byte, error) {
+	if externalAAD == nil {
+		externalAAD = []byte{}
+	}
+
+	return cbor.Marshal([]any{"Signature1", protected, externalAAD, payload})
+}
+
+// Produce a tagged COSE_Sign1 message
+//
+// `protected` and `unprotected` may carry additional header parameters and may be nil.
+// When `detached` is set the payload is signed but not carried in the message.
+func Sign1(payload, externalAAD []byte, s Signer, protected, unprotected Headers, detached bool) ([]byte, error) {
+	p, u, err := signerHeaders(s, protected, unprotected)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rawProtected, err := encodeProtected(p)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tbs, err := sign1ToBeSigned(rawProtected, externalAAD, payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := s.sign(tbs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var carried any = payload
+	if detached {
+		carried = nil
+	}
+
+	return cbor.Marshal(cbor.Tag{Number: TagSign1, Content: []any{rawProtected, map[any]any(u), carried, sig}})
+}
+
+// Parse a tagged or untagged COSE_Sign1 message
+func ParseSign1(data []byte) (*Sign1Message, error) {
+	v, err := cbor.Unmarshal(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tag, ok := v.(cbor.Tag); ok {
+		if tag.Number != TagSign1 {
+			return nil, ErrMalformed
+		}
+
+		v = tag.Content
+	}
+
+	arr, ok := v.([]any)
+
+	if !ok || len(arr) != 4 {
+		return nil, ErrMalformed
+	}
+
+	rawProtected, ok1 := arr[0].([]byte)
+	sig, ok2 := arr[3].([]byte)
+
+	if !ok1 || !ok2 {
+		return nil, ErrMalformed
+	}
+
+	msg := &Sign1Message{Signature: sig, rawProtected: rawProtected}
+
+	if msg.Protected, err = decodeProtected(rawProtected); err != nil {
+		return nil, err
+	}
+
+	if msg.Unprotected, err = decodeUnprotected(arr[1]); err != nil {
+		return nil, err
+	}
+
+	switch payload := arr[2].(type) {
+	case []byte:
+		msg.Payload = payload
+	case nil:
+	default:
+		return nil, ErrMalformed
+	}
+
+	return msg, nil
+}
+
+// Verify the message against the trusted keys
+//
+// `detached` supplies the payload of a detached message and must be nil otherwise,
+// ErrEmbeddedPayload is returned when both are present.
+// Returns the key that verified.
+func (m *Sign1Message) Verify(detached, externalAAD []byte, keys []Verifier) (*Verifier, error) {
+	payload := m.Payload
+
+	if payload != nil && detached != nil {
+		return nil, ErrEmbeddedPayload
+	}
+
+	if payload == nil {
+		if detached == nil {
+			return nil, ErrMalformed
+		}
+
+		payload = detached
+	}
+
+	tbs, err := sign1ToBeSigned(m.rawProtected, externalAAD, payload)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyWithKeys(m.Protected, m.Unprotected, tbs, m.Signature, keys)
+}
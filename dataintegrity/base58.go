@@ -0,0 +1,72 @@
+package dataintegrity
+
+import (
+	"math/big"
+	"strings"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Digits used by big.Int.Text for base 58
+const bigAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUV"
+
+// Multibase base58-btc encoding, prefixed with 'z'
+//
+// Uses big.Int conversion, a byte-wise loop is quadratic and too slow for
+// the larger SLH-DSA signatures.
+func encodeMultibase(data []byte) string {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	var digits string
+
+	if zeros < len(data) {
+		digits = new(big.Int).SetBytes(data[zeros:]).Text(58)
+	}
+
+	b := []byte("z" + strings.Repeat("1", zeros) + digits)
+	for i := 1 + zeros; i < len(b); i++ {
+		b[i] = base58Alphabet[strings.IndexByte(bigAlphabet, b[i])]
+	}
+
+	return string(b)
+}
+
+func decodeMultibase(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "z") {
+		return nil, ErrMalformed
+	}
+
+	s = s[1:]
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+
+	b := []byte(s[zeros:])
+	for i, c := range b {
+		j := strings.IndexByte(base58Alphabet, c)
+
+		if j < 0 {
+			return nil, ErrMalformed
+		}
+
+		b[i] = bigAlphabet[j]
+	}
+
+	out := make([]byte, zeros)
+
+	if len(b) > 0 {
+		n, ok := new(big.Int).SetString(string(b), 58)
+
+		if !ok {
+			return nil, ErrMalformed
+		}
+
+		out = append(out, n.Bytes()...)
+	}
+
+	return out, nil
+}
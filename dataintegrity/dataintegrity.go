@@ -0,0 +1,407 @@
+// Package dataintegrity implements Data Integrity proofs (W3C VC Data
+// Integrity 1.0) over JSON documents canonicalized with JCS (RFC 8785) and
+// signed with SLH-DSA.
+//
+// SLH-DSA-SHA2-128s uses the slhdsa128-jcs-2024 cryptosuite of the W3C
+// quantum-safe cryptosuites draft, built like eddsa-jcs-2022. The draft
+// specifies no other parameter set, those use package-specific cryptosuite
+// names such as "slh-dsa-shake-256f-jcs" and a hash matching their
+// security category.
+package dataintegrity
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"hash"
+	"strings"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/jcs"
+)
+
+// Proof type of every cryptosuite
+const ProofType = "DataIntegrityProof"
+
+// Cryptosuite of SLH-DSA-SHA2-128s in the quantum-safe cryptosuites draft
+const CryptosuiteSLHDSA128JCS = "slhdsa128-jcs-2024"
+
+// Default proof purpose
+const AssertionMethod = "assertionMethod"
+
+var (
+	ErrMalformed              = errors.New("dataintegrity: malformed document or proof")
+	ErrNoProof                = errors.New("dataintegrity: document has no proof")
+	ErrUnknownKey             = errors.New("dataintegrity: unknown verification method")
+	ErrUnsupportedCryptosuite = errors.New("dataintegrity: unsupported cryptosuite")
+	ErrInvalidProof           = errors.New("dataintegrity: invalid proof value")
+	ErrProofPurpose           = errors.New("dataintegrity: unexpected proof purpose")
+	ErrChallenge              = errors.New("dataintegrity: challenge or domain mismatch")
+	ErrExpired                = errors.New("dataintegrity: proof expired")
+	ErrContextMismatch        = errors.New("dataintegrity: proof context does not match document")
+)
+
+// Data Integrity proof, times are XML Schema dateTimeStamp strings
+type Proof struct {
+	Context            json.RawMessage `json:"@context,omitempty"`
+	ID                 string          `json:"id,omitempty"`
+	Type               string          `json:"type"`
+	Cryptosuite        string          `json:"cryptosuite"`
+	Created            string          `json:"created,omitempty"`
+	Expires            string          `json:"expires,omitempty"`
+	VerificationMethod string          `json:"verificationMethod"`
+	ProofPurpose       string          `json:"proofPurpose"`
+	Challenge          string          `json:"challenge,omitempty"`
+	Domain             string          `json:"domain,omitempty"`
+	PreviousProof      string          `json:"previousProof,omitempty"`
+	Nonce              string          `json:"nonce,omitempty"`
+	ProofValue         string          `json:"proofValue,omitempty"`
+}
+
+// Maps the verificationMethod of a proof to a public key
+type KeyResolver interface {
+	ResolveKey(verificationMethod string) (slhdsa.TrustedKey, error)
+}
+
+// Static key resolver
+type Keys map[string]slhdsa.TrustedKey
+
+func (k Keys) ResolveKey(verificationMethod string) (slhdsa.TrustedKey, error) {
+	key, ok := k[verificationMethod]
+
+	if !ok {
+		return slhdsa.TrustedKey{}, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// Cryptosuite name of a parameter set
+func Cryptosuite(paramSet string) (string, error) {
+	if _, err := slhdsa.New(paramSet); err != nil {
+		return "", ErrUnsupportedCryptosuite
+	}
+
+	if paramSet == slhdsa.ParameterSet.SLHDSA_SHA2_128s {
+		return CryptosuiteSLHDSA128JCS, nil
+	}
+
+	return strings.ToLower(paramSet) + "-jcs", nil
+}
+
+// Hash for the security category of the parameter set
+func newHash(paramSet string) hash.Hash {
+	switch {
+	case strings.Contains(paramSet, "192"):
+		return sha512.New384()
+	case strings.Contains(paramSet, "256"):
+		return sha512.New()
+	}
+
+	return sha256.New()
+}
+
+func digest(paramSet string, data []byte) []byte {
+	h := newHash(paramSet)
+	h.Write(data)
+
+	return h.Sum(nil)
+}
+
+// Split a document into its members without the proof and its proofs
+func splitDocument(doc []byte) (map[string]json.RawMessage, []Proof, error) {
+	// Rejects duplicate keys and invalid UTF-8 that decoding would hide
+	if _, err := jcs.Transform(doc); err != nil {
+		return nil, nil, err
+	}
+
+	var members map[string]json.RawMessage
+
+	if err := json.Unmarshal(doc, &members); err != nil || members == nil {
+		return nil, nil, ErrMalformed
+	}
+
+	raw, ok := members["proof"]
+	delete(members, "proof")
+
+	if !ok {
+		return members, nil, nil
+	}
+
+	var proofs []Proof
+
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		if err := json.Unmarshal(raw, &proofs); err != nil {
+			return nil, nil, ErrMalformed
+		}
+	} else {
+		var p Proof
+
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, nil, ErrMalformed
+		}
+
+		proofs = []Proof{p}
+	}
+
+	return members, proofs, nil
+}
+
+// Hash of the proof configuration followed by the hash of the canonical
+// unsecured document (eddsa-jcs-2022 Sections 3.3.3 to 3.3.5)
+func hashData(paramSet string, unsecured map[string]json.RawMessage, p Proof) ([]byte, error) {
+	p.ProofValue = ""
+
+	config, err := jcs.Marshal(p)
+
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := jcs.Marshal(unsecured)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(digest(paramSet, config), digest(paramSet, doc)...), nil
+}
+
+// Produces proofs with an SLH-DSA private key
+type Signer struct {
+	// URL of the verification method, usually a DID URL with a fragment
+	VerificationMethod string
+
+	ParameterSet string
+	PrivateKey   slhdsa.PrivateKey
+
+	// Clock, time.Now when nil
+	Now func() time.Time
+}
+
+// Add a proof to a JSON document, returns the secured document
+//
+// Options carries the optional proof fields (id, proofPurpose, expires,
+// challenge, domain, previousProof, nonce). Existing proofs are kept and the
+// proof member becomes a proof set.
+func (s *Signer) Sign(doc []byte, options Proof) ([]byte, error) {
+	members, proofs, err := splitDocument(doc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	suite, err := Cryptosuite(s.ParameterSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := options
+	p.Type = ProofType
+	p.Cryptosuite = suite
+	p.VerificationMethod = s.VerificationMethod
+
+	if p.ProofPurpose == "" {
+		p.ProofPurpose = AssertionMethod
+	}
+
+	if p.Created == "" {
+		now := time.Now
+		if s.Now != nil {
+			now = s.Now
+		}
+
+		p.Created = now().UTC().Format(time.RFC3339)
+	}
+
+	if ctx, ok := members["@context"]; ok {
+		p.Context = ctx
+	}
+
+	data, err := hashData(s.ParameterSet, members, p)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, _ := slhdsa.New(s.ParameterSet)
+	sig, err := ctx.GenerateSignature(s.PrivateKey, data, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.ProofValue = encodeMultibase(sig)
+	proofs = append(proofs, p)
+
+	var proof any = proofs
+	if len(proofs) == 1 {
+		proof = proofs[0]
+	}
+
+	raw, err := json.Marshal(proof)
+
+	if err != nil {
+		return nil, err
+	}
+
+	members["proof"] = raw
+
+	return json.Marshal(members)
+}
+
+// Verifies every proof of a document against keys from a resolver
+type Verifier struct {
+	Keys KeyResolver
+
+	// Expected proof purpose, AssertionMethod when empty
+	ProofPurpose string
+
+	// Expected challenge and domain, not checked when empty
+	Challenge string
+	Domain    string
+
+	// Clock, time.Now when nil
+	Now func() time.Time
+}
+
+// Context values of a document or proof, each in canonical form
+func contextValues(raw json.RawMessage) ([]string, error) {
+	var v any
+
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, ErrMalformed
+	}
+
+	list, ok := v.([]any)
+	if !ok {
+		list = []any{v}
+	}
+
+	out := make([]string, len(list))
+
+	for i, c := range list {
+		b, err := jcs.Marshal(c)
+
+		if err != nil {
+			return nil, err
+		}
+
+		out[i] = string(b)
+	}
+
+	return out, nil
+}
+
+func (v *Verifier) verifyProof(unsecured map[string]json.RawMessage, p Proof) error {
+	if p.Type != ProofType {
+		return ErrUnsupportedCryptosuite
+	}
+
+	purpose := v.ProofPurpose
+	if purpose == "" {
+		purpose = AssertionMethod
+	}
+
+	if p.ProofPurpose != purpose {
+		return ErrProofPurpose
+	}
+
+	if (v.Challenge != "" && p.Challenge != v.Challenge) || (v.Domain != "" && p.Domain != v.Domain) {
+		return ErrChallenge
+	}
+
+	if p.Expires != "" {
+		expires, err := time.Parse(time.RFC3339, p.Expires)
+
+		if err != nil {
+			return ErrMalformed
+		}
+
+		now := time.Now
+		if v.Now != nil {
+			now = v.Now
+		}
+
+		if now().After(expires) {
+			return ErrExpired
+		}
+	}
+
+	// The document context must start with the proof context
+	if len(p.Context) > 0 {
+		want, err := contextValues(p.Context)
+
+		if err != nil {
+			return err
+		}
+
+		got, err := contextValues(unsecured["@context"])
+
+		if err != nil || len(got) < len(want) {
+			return ErrContextMismatch
+		}
+
+		for i := range want {
+			if got[i] != want[i] {
+				return ErrContextMismatch
+			}
+		}
+	}
+
+	key, err := v.Keys.ResolveKey(p.VerificationMethod)
+
+	if err != nil {
+		return err
+	}
+
+	if suite, err := Cryptosuite(key.ParameterSet); err != nil || suite != p.Cryptosuite {
+		return ErrUnsupportedCryptosuite
+	}
+
+	sig, err := decodeMultibase(p.ProofValue)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := hashData(key.ParameterSet, unsecured, p)
+
+	if err != nil {
+		return err
+	}
+
+	ctx, _ := slhdsa.New(key.ParameterSet)
+	ok, err := ctx.VerifySignature(key.PublicKey, data, sig, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil || !ok {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// Verify every proof of a secured document, returns the verified proofs
+func (v *Verifier) Verify(doc []byte) ([]Proof, error) {
+	unsecured, proofs, err := splitDocument(doc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(proofs) == 0 {
+		return nil, ErrNoProof
+	}
+
+	for _, p := range proofs {
+		if err := v.verifyProof(unsecured, p); err != nil {
+			return nil, err
+		}
+	}
+
+	return proofs, nil
+}
@@ -0,0 +1,134 @@
+package dataintegrity_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/dataintegrity"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+)
+
+const credential = `{
+  "@context": ["https://www.w3.org/ns/credentials/v2", "https://www.w3.org/ns/credentials/examples/v2"],
+  "type": ["VerifiableCredential", "AlumniCredential"],
+  "issuer": "did:example:issuer",
+  "validFrom": "2024-01-01T00:00:00Z",
+  "credentialSubject": {"id": "did:example:alice", "alumniOf": "Example University", "score": 1.50}
+}`
+
+func newSigner(t *testing.T, method string) (*dataintegrity.Signer, slhdsa.TrustedKey) {
+	paramSet := slhdsa.ParameterSet.SLHDSA_SHA2_128f
+	sk, pk := testkey.New(t, paramSet)
+
+	return &dataintegrity.Signer{VerificationMethod: method, ParameterSet: paramSet, PrivateKey: sk}, slhdsa.TrustedKey{ParameterSet: paramSet, PublicKey: pk}
+}
+
+func TestCryptosuite(t *testing.T) {
+	if s, _ := dataintegrity.Cryptosuite(slhdsa.ParameterSet.SLHDSA_SHA2_128s); s != "slhdsa128-jcs-2024" {
+		t.Errorf("unexpected cryptosuite %q", s)
+	}
+
+	if s, _ := dataintegrity.Cryptosuite(slhdsa.ParameterSet.SLHDSA_SHAKE_256f); s != "slh-dsa-shake-256f-jcs" {
+		t.Errorf("unexpected cryptosuite %q", s)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	s, key := newSigner(t, "did:example:issuer#key-1")
+	secured, err := s.Sign([]byte(credential), dataintegrity.Proof{Expires: "2099-01-01T00:00:00Z"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := &dataintegrity.Verifier{Keys: dataintegrity.Keys{"did:example:issuer#key-1": key}}
+	proofs, err := v.Verify(secured)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := proofs[0]
+
+	if p.Type != "DataIntegrityProof" || p.ProofPurpose != "assertionMethod" || !strings.HasPrefix(p.ProofValue, "z") || len(p.Context) == 0 {
+		t.Errorf("unexpected proof %+v", p)
+	}
+
+	// Formatting and member order do not matter after canonicalization
+	var indented bytes.Buffer
+	json.Indent(&indented, secured, "", "\t")
+
+	if _, err := v.Verify(indented.Bytes()); err != nil {
+		t.Error(err)
+	}
+
+	tampered := bytes.Replace(secured, []byte("Example University"), []byte("Other University"), 1)
+
+	if _, err := v.Verify(tampered); !errors.Is(err, dataintegrity.ErrInvalidProof) {
+		t.Errorf("expected ErrInvalidProof, got %v", err)
+	}
+
+	changed := bytes.Replace(secured, []byte(`"https://www.w3.org/ns/credentials/v2","https://www.w3.org/ns/credentials/examples/v2"],"credentialSubject"`), []byte(`"https://example.com/v1"],"credentialSubject"`), 1)
+
+	if _, err := v.Verify(changed); !errors.Is(err, dataintegrity.ErrContextMismatch) {
+		t.Errorf("expected ErrContextMismatch, got %v", err)
+	}
+
+	cases := map[error]*dataintegrity.Verifier{
+		dataintegrity.ErrUnknownKey:   {Keys: dataintegrity.Keys{}},
+		dataintegrity.ErrProofPurpose: {Keys: v.Keys, ProofPurpose: "authentication"},
+		dataintegrity.ErrChallenge:    {Keys: v.Keys, Challenge: "abc"},
+		dataintegrity.ErrExpired:      {Keys: v.Keys, Now: func() time.Time { return time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC) }},
+	}
+
+	for expected, c := range cases {
+		if _, err := c.Verify(secured); !errors.Is(err, expected) {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+	}
+
+	if _, err := v.Verify([]byte(credential)); !errors.Is(err, dataintegrity.ErrNoProof) {
+		t.Errorf("expected ErrNoProof, got %v", err)
+	}
+}
+
+func TestProofSet(t *testing.T) {
+	s1, key1 := newSigner(t, "did:example:a#1")
+	s2, key2 := newSigner(t, "did:example:b#1")
+
+	secured, err := s1.Sign([]byte(credential), dataintegrity.Proof{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secured, err = s2.Sign(secured, dataintegrity.Proof{ProofPurpose: "authentication", Challenge: "1f44", Domain: "example.org"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := dataintegrity.Keys{"did:example:a#1": key1, "did:example:b#1": key2}
+
+	// All proofs of the set must verify, including the purpose of each
+	if _, err := (&dataintegrity.Verifier{Keys: keys}).Verify(secured); !errors.Is(err, dataintegrity.ErrProofPurpose) {
+		t.Errorf("expected ErrProofPurpose, got %v", err)
+	}
+
+	var doc struct {
+		Proof []dataintegrity.Proof `json:"proof"`
+	}
+
+	if err := json.Unmarshal(secured, &doc); err != nil || len(doc.Proof) != 2 {
+		t.Fatalf("expected a proof set, got %v", err)
+	}
+
+	if _, err := dataintegrity.Cryptosuite("SLH-DSA-MD5-128s"); !errors.Is(err, dataintegrity.ErrUnsupportedCryptosuite) {
+		t.Errorf("expected ErrUnsupportedCryptosuite, got %v", err)
+	}
+}
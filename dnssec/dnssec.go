@@ -0,0 +1,124 @@
+// Package dnssec signs and validates DNS zones with SLH-DSA.
+//
+// SLH-DSA has no assigned DNSSEC algorithm number, so keys and signatures use
+// the private-use algorithm PRIVATEOID (RFC 4034 Appendix A.1.1): the public
+// key and signature fields start with a length byte and the BER encoded
+// object identifier of the parameter set (RFC 9814), followed by the raw key
+// or signature.
+//
+// The zone file support covers the record types common in a lab zone and
+// lowercases all names, so stored RDATA is already in canonical form.
+// Authenticated denial of existence (NSEC/NSEC3) is not produced.
+package dnssec
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Resource record types
+const (
+	TypeA      uint16 = 1
+	TypeNS     uint16 = 2
+	TypeCNAME  uint16 = 5
+	TypeSOA    uint16 = 6
+	TypePTR    uint16 = 12
+	TypeMX     uint16 = 15
+	TypeTXT    uint16 = 16
+	TypeAAAA   uint16 = 28
+	TypeSRV    uint16 = 33
+	TypeDS     uint16 = 43
+	TypeRRSIG  uint16 = 46
+	TypeDNSKEY uint16 = 48
+)
+
+const ClassINET uint16 = 1
+
+// Private-use algorithm identified by an OID prefix (RFC 4034 Appendix A.1.1)
+const AlgorithmPrivateOID uint8 = 254
+
+// DNSKEY flags
+const (
+	FlagZone uint16 = 0x0100
+	FlagSEP  uint16 = 0x0001
+)
+
+// DS digest type SHA-256 (RFC 4509)
+const DigestSHA256 uint8 = 2
+
+var (
+	ErrSyntax              = errors.New("dnssec: zone file syntax error")
+	ErrUnsupportedType     = errors.New("dnssec: unsupported record type")
+	ErrUnsupportedKey      = errors.New("dnssec: unsupported DNSKEY algorithm or key")
+	ErrNoDNSKEY            = errors.New("dnssec: zone has no DNSKEY RRset at the apex")
+	ErrUntrustedDNSKEY     = errors.New("dnssec: DNSKEY RRset not signed by a trust anchor")
+	ErrMissingSignature    = errors.New("dnssec: RRset has no valid RRSIG")
+	ErrSignatureExpired    = errors.New("dnssec: RRSIG outside its validity period")
+	ErrInvalidSignature    = errors.New("dnssec: invalid RRSIG")
+	ErrRRSIGNotCoveredType = errors.New("dnssec: RRSIG does not match the RRset")
+)
+
+var typeNames = map[uint16]string{
+	TypeA:      "A",
+	TypeNS:     "NS",
+	TypeCNAME:  "CNAME",
+	TypeSOA:    "SOA",
+	TypePTR:    "PTR",
+	TypeMX:     "MX",
+	TypeTXT:    "TXT",
+	TypeAAAA:   "AAAA",
+	TypeSRV:    "SRV",
+	TypeDS:     "DS",
+	TypeRRSIG:  "RRSIG",
+	TypeDNSKEY: "DNSKEY",
+}
+
+// Mnemonic of a record type, TYPEnnn when unknown (RFC 3597)
+func TypeString(t uint16) string {
+	if s, ok := typeNames[t]; ok {
+		return s
+	}
+
+	return fmt.Sprintf("TYPE%d", t)
+}
+
+func typeFromString(s string) (uint16, bool) {
+	s = strings.ToUpper(s)
+
+	for t, name := range typeNames {
+		if name == s {
+			return t, true
+		}
+	}
+
+	var n uint16
+	if _, err := fmt.Sscanf(s, "TYPE%d", &n); err == nil && fmt.Sprintf("TYPE%d", n) == s {
+		return n, true
+	}
+
+	return 0, false
+}
+
+// Resource record with wire format RDATA
+type RR struct {
+	// Fully qualified lowercase owner name with a trailing dot
+	Name string
+
+	TTL   uint32
+	Class uint16
+	Type  uint16
+
+	// Uncompressed wire RDATA with lowercase names
+	Data []byte
+}
+
+// Presentation format of the record
+func (rr *RR) String() string {
+	class := "IN"
+	if rr.Class != ClassINET {
+		class = fmt.Sprintf("CLASS%d", rr.Class)
+	}
+
+	return fmt.Sprintf("%s\t%d\t%s\t%s\t%s", rr.Name, rr.TTL, class, TypeString(rr.Type), formatRData(rr.Type, rr.Data))
+}
@@ -0,0 +1,166 @@
+package dnssec_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/dnssec"
+)
+
+const zoneFile = `$ORIGIN example.com.
+$TTL 3600
+@	IN SOA ns1 hostmaster (
+		2024010101 ; serial
+		7200 3600 1209600 300 )
+	IN NS ns1
+	IN MX 10 Mail
+ns1	IN A 192.0.2.1
+www	300 IN AAAA 2001:db8::1
+txt	IN TXT "hello \"world\"" second
+_sip._tcp IN SRV 10 60 5060 sip
+sub	IN NS ns.sub
+ns.sub	IN A 192.0.2.53
+`
+
+func parse(t *testing.T, text string) []dnssec.RR {
+	rrs, err := dnssec.ParseZone(strings.NewReader(text), "example.com.")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rrs
+}
+
+func TestParseZone(t *testing.T) {
+	rrs := parse(t, zoneFile)
+
+	if len(rrs) != 9 {
+		t.Fatalf("parsed %d records", len(rrs))
+	}
+
+	if rrs[2].String() != "example.com.\t3600\tIN\tMX\t10 mail.example.com." {
+		t.Errorf("unexpected MX %q", rrs[2].String())
+	}
+
+	if rrs[4].TTL != 300 || rrs[4].Name != "www.example.com." {
+		t.Errorf("unexpected AAAA %q", rrs[4].String())
+	}
+
+	// Presentation output parses back to the same records
+	var buf bytes.Buffer
+	dnssec.WriteZone(&buf, rrs)
+	again := parse(t, buf.String())
+
+	for i := range rrs {
+		if again[i].String() != rrs[i].String() || !bytes.Equal(again[i].Data, rrs[i].Data) {
+			t.Errorf("round trip changed %q to %q", rrs[i].String(), again[i].String())
+		}
+	}
+
+	if _, err := dnssec.ParseZone(strings.NewReader("a IN A 1.2.3.4\n"), "example.com."); err == nil {
+		t.Error("accepted a record without a TTL")
+	}
+}
+
+func TestKeyTagAndDS(t *testing.T) {
+	// RFC 4509 Section 2.3 example
+	rrs := parse(t, `dskey.example.com. 86400 IN DNSKEY 256 3 5 ( AQOeiiR0GOMYkDshWoSKz9XzfwJr1AYtsmx3TGkJaNXVbfi/
+		2pHm822aJ5iI9BMzNXxeYCmZDRD99WYwYqUSdjMmmAphXdvx
+		egXd/M5+X7OrzKBaMbCVdFLUUh6DhweJBjEVv5f2wwjM9Xzc
+		nOf+EPbtG9DMBmADjFDc2w/rljwvFw== )
+`)
+
+	if tag := dnssec.KeyTag(rrs[0].Data); tag != 60485 {
+		t.Errorf("unexpected key tag %d", tag)
+	}
+
+	ds, err := dnssec.DS(rrs[0])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "60485 5 2 D4B7D520E7BB5F0F67674A0CCEB1E3E0614B93C4F9E99B8383F6A1E4469DA50A"
+
+	if !strings.HasSuffix(ds.String(), want) {
+		t.Errorf("unexpected DS %q", ds.String())
+	}
+}
+
+func TestSignValidate(t *testing.T) {
+	paramSet := slhdsa.ParameterSet.SLHDSA_SHA2_128f
+	ksk, _ := dnssec.GenerateKey("example.com.", paramSet, true, 3600)
+	zsk, _ := dnssec.GenerateKey("example.com.", paramSet, false, 3600)
+
+	now := time.Now()
+	signed, err := dnssec.SignZone("example.com.", parse(t, zoneFile), []*dnssec.Key{ksk, zsk}, now.Add(-time.Hour), now.Add(24*time.Hour))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kskRR, _ := ksk.DNSKEY()
+	ds, _ := dnssec.DS(kskRR)
+	anchors := []dnssec.RR{ds}
+
+	// Re-parsing the presentation output must not break signatures
+	var buf bytes.Buffer
+	dnssec.WriteZone(&buf, signed)
+	signed = parse(t, buf.String())
+
+	if err := dnssec.ValidateZone("example.com.", signed, anchors, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Delegation NS and glue are not signed
+	for _, rr := range signed {
+		if rr.Type == dnssec.TypeRRSIG && strings.HasSuffix(rr.Name, "sub.example.com.") {
+			t.Errorf("signed non-authoritative data at %s", rr.Name)
+		}
+	}
+
+	if err := dnssec.ValidateZone("example.com.", signed, anchors, now.Add(48*time.Hour)); !errors.Is(err, dnssec.ErrUntrustedDNSKEY) {
+		t.Errorf("expected ErrUntrustedDNSKEY after expiry, got %v", err)
+	}
+
+	zskRR, _ := zsk.DNSKEY()
+
+	if err := dnssec.ValidateZone("example.com.", signed, []dnssec.RR{zskRR}, now); !errors.Is(err, dnssec.ErrUntrustedDNSKEY) {
+		t.Errorf("expected ErrUntrustedDNSKEY with a ZSK anchor, got %v", err)
+	}
+
+	for i, rr := range signed {
+		if rr.Type == dnssec.TypeA && rr.Name == "ns1.example.com." {
+			signed[i].Data = []byte{192, 0, 2, 99}
+		}
+	}
+
+	err = dnssec.ValidateZone("example.com.", signed, anchors, now)
+
+	if !errors.Is(err, dnssec.ErrMissingSignature) || !strings.Contains(err.Error(), "ns1.example.com. A") {
+		t.Errorf("expected failure for the modified A record, got %v", err)
+	}
+}
+
+func TestSizes(t *testing.T) {
+	sizes, err := dnssec.Sizes("example.com.")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sizes) != 12 {
+		t.Fatalf("got %d sizes", len(sizes))
+	}
+
+	for _, s := range sizes {
+		if s.FitsUDP || !s.FitsTCP || s.RRSIG <= s.Signature {
+			t.Errorf("unexpected size %+v", s)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package dnssec
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/binary"
+	"slices"
+	"strings"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/cms"
+)
+
+// Zone signing key
+type Key struct {
+	// Zone apex, fully qualified
+	Zone string
+
+	// DNSKEY flags, FlagZone with FlagSEP for a key signing key
+	Flags uint16
+
+	TTL          uint32
+	ParameterSet string
+	PublicKey    slhdsa.PublicKey
+	PrivateKey   slhdsa.PrivateKey
+}
+
+// Length byte and BER object identifier prefixing PRIVATEOID keys and signatures
+func oidPrefix(paramSet string) ([]byte, error) {
+	oid, err := cms.AlgorithmOID(paramSet)
+
+	if err != nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	der, err := asn1.Marshal(oid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(len(der))}, der...), nil
+}
+
+// Split a PRIVATEOID field into its parameter set and payload
+func splitOIDPrefix(data []byte) (string, []byte, error) {
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return "", nil, ErrUnsupportedKey
+	}
+
+	var oid asn1.ObjectIdentifier
+
+	if rest, err := asn1.Unmarshal(data[1:1+int(data[0])], &oid); err != nil || len(rest) != 0 {
+		return "", nil, ErrUnsupportedKey
+	}
+
+	paramSet, err := cms.ParameterSetFromOID(oid)
+
+	if err != nil {
+		return "", nil, ErrUnsupportedKey
+	}
+
+	return paramSet, data[1+int(data[0]):], nil
+}
+
+// Generate a key for the zone, a key signing key when ksk is set
+func GenerateKey(zone, paramSet string, ksk bool, ttl uint32) (*Key, error) {
+	ctx, err := slhdsa.New(paramSet)
+
+	if err != nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	sk, pk, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		return nil, err
+	}
+
+	flags := FlagZone
+	if ksk {
+		flags |= FlagSEP
+	}
+
+	return &Key{Zone: absolute(zone, "."), Flags: flags, TTL: ttl, ParameterSet: paramSet, PublicKey: pk, PrivateKey: sk}, nil
+}
+
+// DNSKEY record of the key
+func (k *Key) DNSKEY() (RR, error) {
+	prefix, err := oidPrefix(k.ParameterSet)
+
+	if err != nil {
+		return RR{}, err
+	}
+
+	data := binary.BigEndian.AppendUint16(nil, k.Flags)
+	data = append(data, 3, AlgorithmPrivateOID)
+	data = append(data, prefix...)
+	data = append(data, k.PublicKey.KeyBytes...)
+
+	return RR{Name: k.Zone, TTL: k.TTL, Class: ClassINET, Type: TypeDNSKEY, Data: data}, nil
+}
+
+// Key tag of DNSKEY RDATA (RFC 4034 Appendix B)
+func KeyTag(dnskey []byte) uint16 {
+	var ac uint32
+
+	for i, b := range dnskey {
+		if i&1 == 1 {
+			ac += uint32(b)
+		} else {
+			ac += uint32(b) << 8
+		}
+	}
+
+	ac += ac >> 16 & 0xffff
+
+	return uint16(ac)
+}
+
+// DS record of a DNSKEY record with a SHA-256 digest (RFC 4509)
+func DS(dnskey RR) (RR, error) {
+	owner, err := nameToWire(dnskey.Name)
+
+	if err != nil {
+		return RR{}, err
+	}
+
+	if dnskey.Type != TypeDNSKEY || len(dnskey.Data) < 4 {
+		return RR{}, ErrUnsupportedKey
+	}
+
+	digest := sha256.Sum256(append(owner, dnskey.Data...))
+
+	data := binary.BigEndian.AppendUint16(nil, KeyTag(dnskey.Data))
+	data = append(data, dnskey.Data[3], DigestSHA256)
+	data = append(data, digest[:]...)
+
+	return RR{Name: dnskey.Name, TTL: dnskey.TTL, Class: dnskey.Class, Type: TypeDS, Data: data}, nil
+}
+
+// Set of records sharing owner, class and type
+type RRset []RR
+
+// Data signed by an RRSIG: its RDATA without the signature followed by the
+// records in canonical form and order (RFC 4034 Section 3.1.8.1)
+func signedData(rrsigPrefix []byte, set RRset, origTTL uint32) ([]byte, error) {
+	owner, err := nameToWire(set[0].Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Owner names of wildcard expansions are not produced when signing a zone
+	rdatas := make([][]byte, len(set))
+	for i, rr := range set {
+		rdatas[i] = rr.Data
+	}
+
+	slices.SortFunc(rdatas, bytes.Compare)
+	rdatas = slices.CompactFunc(rdatas, bytes.Equal)
+
+	out := append([]byte{}, rrsigPrefix...)
+
+	for _, rd := range rdatas {
+		out = append(out, owner...)
+		out = binary.BigEndian.AppendUint16(out, set[0].Type)
+		out = binary.BigEndian.AppendUint16(out, set[0].Class)
+		out = binary.BigEndian.AppendUint32(out, origTTL)
+		out = binary.BigEndian.AppendUint16(out, uint16(len(rd)))
+		out = append(out, rd...)
+	}
+
+	return out, nil
+}
+
+// RRSIG RDATA up to and including the signer name
+func rrsigPrefix(set RRset, alg uint8, origTTL uint32, inception, expiration time.Time, keyTag uint16, signer string) ([]byte, error) {
+	signerWire, err := nameToWire(signer)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data := binary.BigEndian.AppendUint16(nil, set[0].Type)
+	data = append(data, alg, byte(labelCount(set[0].Name)))
+	data = binary.BigEndian.AppendUint32(data, origTTL)
+	data = binary.BigEndian.AppendUint32(data, uint32(expiration.Unix()))
+	data = binary.BigEndian.AppendUint32(data, uint32(inception.Unix()))
+	data = binary.BigEndian.AppendUint16(data, keyTag)
+
+	return append(data, signerWire...), nil
+}
+
+// Sign an RRset with the key
+func (k *Key) SignRRset(set RRset, inception, expiration time.Time) (RR, error) {
+	dnskey, err := k.DNSKEY()
+
+	if err != nil {
+		return RR{}, err
+	}
+
+	prefix, err := rrsigPrefix(set, AlgorithmPrivateOID, set[0].TTL, inception, expiration, KeyTag(dnskey.Data), k.Zone)
+
+	if err != nil {
+		return RR{}, err
+	}
+
+	data, err := signedData(prefix, set, set[0].TTL)
+
+	if err != nil {
+		return RR{}, err
+	}
+
+	ctx, _ := slhdsa.New(k.ParameterSet)
+	sig, err := ctx.GenerateSignature(k.PrivateKey, data, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return RR{}, err
+	}
+
+	oid, _ := oidPrefix(k.ParameterSet)
+	rdata := append(append(prefix, oid...), sig...)
+
+	return RR{Name: set[0].Name, TTL: set[0].TTL, Class: set[0].Class, Type: TypeRRSIG, Data: rdata}, nil
+}
+
+type setKey struct {
+	name  string
+	class uint16
+	typ   uint16
+}
+
+// Group records into RRsets in order of first appearance
+func groupRRsets(rrs []RR) []RRset {
+	index := make(map[setKey]int)
+
+	var sets []RRset
+
+	for _, rr := range rrs {
+		key := setKey{rr.Name, rr.Class, rr.Type}
+
+		if i, ok := index[key]; ok {
+			sets[i] = append(sets[i], rr)
+			continue
+		}
+
+		index[key] = len(sets)
+		sets = append(sets, RRset{rr})
+	}
+
+	return sets
+}
+
+// Whether name is at or below the delegation point cut
+func isBelow(name, cut string) bool {
+	return name == cut || strings.HasSuffix(name, "."+cut)
+}
+
+// Whether an RRset of the zone is authoritative data that gets signed
+//
+// NS records at a delegation point and glue below it are not signed, DS at
+// a delegation point is (RFC 4035 Section 2.2).
+func signable(set RRset, zone string, cuts []string) bool {
+	name, typ := set[0].Name, set[0].Type
+
+	if typ == TypeRRSIG || !isBelow(name, zone) {
+		return false
+	}
+
+	for _, cut := range cuts {
+		if name == cut && typ == TypeDS {
+			return true
+		}
+
+		if isBelow(name, cut) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Delegation points, owners of NS RRsets below the apex
+func delegations(rrs []RR, zone string) []string {
+	var cuts []string
+
+	for _, rr := range rrs {
+		if rr.Type == TypeNS && rr.Name != zone && !slices.Contains(cuts, rr.Name) {
+			cuts = append(cuts, rr.Name)
+		}
+	}
+
+	return cuts
+}
+
+// Sign a zone, returning its records with the DNSKEY RRset and RRSIGs added
+//
+// Key signing keys sign the DNSKEY RRset, zone signing keys sign every other
+// authoritative RRset. Without a zone signing key the key signing keys sign
+// everything. Existing DNSKEY and RRSIG records are replaced.
+func SignZone(zone string, rrs []RR, keys []*Key, inception, expiration time.Time) ([]RR, error) {
+	zone = absolute(zone, ".")
+
+	var kept []RR
+
+	for _, rr := range rrs {
+		if rr.Type == TypeRRSIG || (rr.Type == TypeDNSKEY && rr.Name == zone) {
+			continue
+		}
+
+		kept = append(kept, rr)
+	}
+
+	var ksks, zsks []*Key
+
+	for _, k := range keys {
+		dnskey, err := k.DNSKEY()
+
+		if err != nil {
+			return nil, err
+		}
+
+		kept = append(kept, dnskey)
+
+		if k.Flags&FlagSEP != 0 {
+			ksks = append(ksks, k)
+		} else {
+			zsks = append(zsks, k)
+		}
+	}
+
+	if len(zsks) == 0 {
+		zsks = ksks
+	}
+
+	if len(ksks) == 0 {
+		ksks = zsks
+	}
+
+	if len(ksks) == 0 {
+		return nil, ErrNoDNSKEY
+	}
+
+	cuts := delegations(kept, zone)
+	out := append([]RR{}, kept...)
+
+	for _, set := range groupRRsets(kept) {
+		if !signable(set, zone, cuts) {
+			continue
+		}
+
+		signers := zsks
+		if set[0].Type == TypeDNSKEY && set[0].Name == zone {
+			signers = ksks
+		}
+
+		for _, k := range signers {
+			sig, err := k.SignRRset(set, inception, expiration)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, sig)
+		}
+	}
+
+	return out, nil
+}
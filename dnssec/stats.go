@@ -0,0 +1,87 @@
+package dnssec
+
+import (
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Payload limit recommended for DNS over UDP with EDNS (DNS Flag Day 2020)
+const UDPPayloadSize = 1232
+
+// Public key and signature sizes of each parameter set (FIPS 205 Table 2)
+var keySizes = []struct {
+	paramSet  string
+	publicKey int
+	signature int
+}{
+	{slhdsa.ParameterSet.SLHDSA_SHA2_128s, 32, 7856},
+	{slhdsa.ParameterSet.SLHDSA_SHAKE_128s, 32, 7856},
+	{slhdsa.ParameterSet.SLHDSA_SHA2_128f, 32, 17088},
+	{slhdsa.ParameterSet.SLHDSA_SHAKE_128f, 32, 17088},
+	{slhdsa.ParameterSet.SLHDSA_SHA2_192s, 48, 16224},
+	{slhdsa.ParameterSet.SLHDSA_SHAKE_192s, 48, 16224},
+	{slhdsa.ParameterSet.SLHDSA_SHA2_192f, 48, 35664},
+	{slhdsa.ParameterSet.SLHDSA_SHAKE_192f, 48, 35664},
+	{slhdsa.ParameterSet.SLHDSA_SHA2_256s, 64, 29792},
+	{slhdsa.ParameterSet.SLHDSA_SHAKE_256s, 64, 29792},
+	{slhdsa.ParameterSet.SLHDSA_SHA2_256f, 64, 49856},
+	{slhdsa.ParameterSet.SLHDSA_SHAKE_256f, 64, 49856},
+}
+
+// Record and response sizes for one parameter set, in bytes
+type Size struct {
+	ParameterSet string
+
+	PublicKey int
+	Signature int
+
+	// RDATA of the DNSKEY and RRSIG records, including the OID prefix
+	DNSKEY int
+	RRSIG  int
+
+	// Response to an A query for the zone apex with one A record, its RRSIG
+	// and an EDNS OPT record
+	Response int
+
+	// Whether Response fits the recommended UDP payload size
+	FitsUDP bool
+
+	// Whether RRSIG RDATA fits the 65535 byte RDLENGTH and a TCP message
+	FitsTCP bool
+}
+
+// Sizes of every parameter set for signatures by the given zone
+func Sizes(zone string) ([]Size, error) {
+	zoneWire, err := nameToWire(absolute(zone, "."))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Size
+
+	for _, ks := range keySizes {
+		prefix, err := oidPrefix(ks.paramSet)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s := Size{ParameterSet: ks.paramSet, PublicKey: ks.publicKey, Signature: ks.signature}
+		s.DNSKEY = 4 + len(prefix) + ks.publicKey
+		s.RRSIG = 18 + len(zoneWire) + len(prefix) + ks.signature
+
+		// Header, question, then answer records with a compressed owner pointer
+		const header, rrFixed, opt = 12, 10, 11
+		question := len(zoneWire) + 4
+		a := 2 + rrFixed + 4
+		sig := 2 + rrFixed + s.RRSIG
+
+		s.Response = header + question + a + sig + opt
+		s.FitsUDP = s.Response <= UDPPayloadSize
+		s.FitsTCP = s.RRSIG <= 0xffff && s.Response <= 0xffff
+
+		out = append(out, s)
+	}
+
+	return out, nil
+}
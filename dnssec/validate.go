@@ -0,0 +1,218 @@
+package dnssec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Decoded RRSIG RDATA
+type rrsig struct {
+	typeCovered uint16
+	algorithm   uint8
+	labels      uint8
+	origTTL     uint32
+	expiration  uint32
+	inception   uint32
+	keyTag      uint16
+	signer      string
+
+	// RDATA up to and including the signer name
+	prefix    []byte
+	signature []byte
+}
+
+func parseRRSIG(data []byte) (*rrsig, error) {
+	if len(data) < 18 {
+		return nil, ErrInvalidSignature
+	}
+
+	signer, n, err := nameFromWire(data[18:])
+
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	return &rrsig{
+		typeCovered: binary.BigEndian.Uint16(data),
+		algorithm:   data[2],
+		labels:      data[3],
+		origTTL:     binary.BigEndian.Uint32(data[4:]),
+		expiration:  binary.BigEndian.Uint32(data[8:]),
+		inception:   binary.BigEndian.Uint32(data[12:]),
+		keyTag:      binary.BigEndian.Uint16(data[16:]),
+		signer:      signer,
+		prefix:      data[:18+n],
+		signature:   data[18+n:],
+	}, nil
+}
+
+// Whether a serial number time lies in the RRSIG validity window (RFC 4034 Section 3.1.5)
+func withinValidity(sig *rrsig, now time.Time) bool {
+	t := uint32(now.Unix())
+
+	return int32(t-sig.inception) >= 0 && int32(sig.expiration-t) >= 0
+}
+
+// Verify an RRSIG over an RRset with a DNSKEY record
+func VerifyRRSIG(set RRset, sigRR RR, dnskey RR, now time.Time) error {
+	sig, err := parseRRSIG(sigRR.Data)
+
+	if err != nil {
+		return err
+	}
+
+	if sig.typeCovered != set[0].Type || sigRR.Name != set[0].Name || int(sig.labels) > labelCount(set[0].Name) {
+		return ErrRRSIGNotCoveredType
+	}
+
+	if dnskey.Type != TypeDNSKEY || len(dnskey.Data) < 4 || dnskey.Name != sig.signer ||
+		sig.algorithm != AlgorithmPrivateOID || dnskey.Data[3] != AlgorithmPrivateOID ||
+		KeyTag(dnskey.Data) != sig.keyTag || binary.BigEndian.Uint16(dnskey.Data)&FlagZone == 0 {
+		return ErrInvalidSignature
+	}
+
+	if !withinValidity(sig, now) {
+		return ErrSignatureExpired
+	}
+
+	paramSet, pkBytes, err := splitOIDPrefix(dnskey.Data[4:])
+
+	if err != nil {
+		return err
+	}
+
+	sigParamSet, sigBytes, err := splitOIDPrefix(sig.signature)
+
+	if err != nil || sigParamSet != paramSet {
+		return ErrInvalidSignature
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	pk, err := ctx.GetPublicKeyFromBytes(pkBytes)
+
+	if err != nil {
+		return ErrUnsupportedKey
+	}
+
+	data, err := signedData(sig.prefix, set, sig.origTTL)
+
+	if err != nil {
+		return err
+	}
+
+	if ok, err := ctx.VerifySignature(pk, data, sigBytes, nil, slhdsa.PreHashAlgorithm.Pure); err != nil || !ok {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Whether a DNSKEY matches a trust anchor given as a DNSKEY or DS record
+func anchored(dnskey RR, anchors []RR) bool {
+	for _, a := range anchors {
+		switch a.Type {
+		case TypeDNSKEY:
+			if a.Name == dnskey.Name && bytes.Equal(a.Data, dnskey.Data) {
+				return true
+			}
+		case TypeDS:
+			ds, err := DS(dnskey)
+
+			if err == nil && a.Name == dnskey.Name && bytes.Equal(a.Data, ds.Data) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Validate a signed zone offline
+//
+// The apex DNSKEY RRset must be signed by a key matching one of the trust
+// anchors (DNSKEY or DS records), and every authoritative RRset must carry a
+// valid RRSIG by a key of that DNSKEY RRset. All failures are reported.
+func ValidateZone(zone string, rrs []RR, anchors []RR, now time.Time) error {
+	zone = absolute(zone, ".")
+
+	sigs := make(map[setKey][]RR)
+	var data []RR
+
+	for _, rr := range rrs {
+		if rr.Type == TypeRRSIG {
+			if len(rr.Data) >= 2 {
+				key := setKey{rr.Name, rr.Class, binary.BigEndian.Uint16(rr.Data)}
+				sigs[key] = append(sigs[key], rr)
+			}
+
+			continue
+		}
+
+		data = append(data, rr)
+	}
+
+	var keys RRset
+
+	for _, set := range groupRRsets(data) {
+		if set[0].Name == zone && set[0].Type == TypeDNSKEY {
+			keys = set
+		}
+	}
+
+	if keys == nil {
+		return ErrNoDNSKEY
+	}
+
+	// verified reports whether any of the keys produced a valid RRSIG over the set
+	verified := func(set RRset, keys RRset) error {
+		last := ErrMissingSignature
+
+		for _, sig := range sigs[setKey{set[0].Name, set[0].Class, set[0].Type}] {
+			for _, k := range keys {
+				err := VerifyRRSIG(set, sig, k, now)
+
+				if err == nil {
+					return nil
+				}
+
+				if errors.Is(err, ErrSignatureExpired) {
+					last = err
+				}
+			}
+		}
+
+		return last
+	}
+
+	var trusted RRset
+
+	for _, k := range keys {
+		if anchored(k, anchors) {
+			trusted = append(trusted, k)
+		}
+	}
+
+	if len(trusted) == 0 || verified(keys, trusted) != nil {
+		return ErrUntrustedDNSKEY
+	}
+
+	var errs []error
+	cuts := delegations(data, zone)
+
+	for _, set := range groupRRsets(data) {
+		if !signable(set, zone, cuts) || (set[0].Name == zone && set[0].Type == TypeDNSKEY) {
+			continue
+		}
+
+		if err := verified(set, keys); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", set[0].Name, TypeString(set[0].Type), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,362 @@
+package dnssec
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Uncompressed wire form of a fully qualified name
+func nameToWire(name string) ([]byte, error) {
+	if !strings.HasSuffix(name, ".") {
+		return nil, fmt.Errorf("%w: name %q is not fully qualified", ErrSyntax, name)
+	}
+
+	if name == "." {
+		return []byte{0}, nil
+	}
+
+	var out []byte
+
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" || len(label) > 63 || strings.ContainsAny(label, "\\ \t") {
+			return nil, fmt.Errorf("%w: invalid name %q", ErrSyntax, name)
+		}
+
+		out = append(out, byte(len(label)))
+		out = append(out, label...)
+	}
+
+	out = append(out, 0)
+
+	if len(out) > 255 {
+		return nil, fmt.Errorf("%w: name %q too long", ErrSyntax, name)
+	}
+
+	return out, nil
+}
+
+// Decode a name at the start of data, returns the name and bytes consumed
+func nameFromWire(data []byte) (string, int, error) {
+	var labels []string
+
+	for i := 0; i < len(data); {
+		n := int(data[i])
+
+		if n == 0 {
+			return strings.Join(labels, ".") + ".", i + 1, nil
+		}
+
+		if n > 63 || i+1+n > len(data) {
+			break
+		}
+
+		labels = append(labels, string(data[i+1:i+1+n]))
+		i += 1 + n
+	}
+
+	return "", 0, fmt.Errorf("%w: malformed wire name", ErrSyntax)
+}
+
+// Number of labels for the RRSIG labels field, not counting the root or a leading wildcard
+func labelCount(name string) int {
+	if name == "." {
+		return 0
+	}
+
+	n := strings.Count(name, ".")
+
+	if strings.HasPrefix(name, "*.") {
+		n--
+	}
+
+	return n
+}
+
+// RDATA field kinds
+type field int
+
+const (
+	fieldUint8 field = iota
+	fieldUint16
+	fieldUint32
+	fieldType
+	fieldTime
+	fieldName
+	fieldA
+	fieldAAAA
+
+	// The remaining kinds consume every remaining token
+	fieldText
+	fieldHex
+	fieldBase64
+)
+
+var rdataFields = map[uint16][]field{
+	TypeA:      {fieldA},
+	TypeNS:     {fieldName},
+	TypeCNAME:  {fieldName},
+	TypeSOA:    {fieldName, fieldName, fieldUint32, fieldUint32, fieldUint32, fieldUint32, fieldUint32},
+	TypePTR:    {fieldName},
+	TypeMX:     {fieldUint16, fieldName},
+	TypeTXT:    {fieldText},
+	TypeAAAA:   {fieldAAAA},
+	TypeSRV:    {fieldUint16, fieldUint16, fieldUint16, fieldName},
+	TypeDS:     {fieldUint16, fieldUint8, fieldUint8, fieldHex},
+	TypeRRSIG:  {fieldType, fieldUint8, fieldUint8, fieldUint32, fieldTime, fieldTime, fieldUint16, fieldName, fieldBase64},
+	TypeDNSKEY: {fieldUint16, fieldUint8, fieldUint8, fieldBase64},
+}
+
+// Signature times as YYYYMMDDHHmmSS (RFC 4034 Section 3.2)
+const timeFormat = "20060102150405"
+
+// Encode presentation tokens of a record as wire RDATA, relative names are completed with origin
+func parseRData(t uint16, tokens []string, origin string) ([]byte, error) {
+	// Generic encoding "\# length hex" (RFC 3597 Section 5)
+	if len(tokens) > 0 && tokens[0] == `\#` {
+		if len(tokens) < 2 {
+			return nil, ErrSyntax
+		}
+
+		data, err := hex.DecodeString(strings.Join(tokens[2:], ""))
+
+		if err != nil || strconv.Itoa(len(data)) != tokens[1] {
+			return nil, fmt.Errorf("%w: bad generic RDATA", ErrSyntax)
+		}
+
+		return data, nil
+	}
+
+	fields, ok := rdataFields[t]
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, TypeString(t))
+	}
+
+	var out []byte
+
+	for i, f := range fields {
+		if f >= fieldText {
+			rest := tokens[min(i, len(tokens)):]
+
+			if len(rest) == 0 {
+				return nil, fmt.Errorf("%w: missing %s RDATA", ErrSyntax, TypeString(t))
+			}
+
+			data, err := parseRest(f, rest)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return append(out, data...), nil
+		}
+
+		if i >= len(tokens) {
+			return nil, fmt.Errorf("%w: missing %s RDATA", ErrSyntax, TypeString(t))
+		}
+
+		tok := tokens[i]
+		var err error
+
+		switch f {
+		case fieldUint8, fieldUint16, fieldUint32:
+			bitSize := map[field]int{fieldUint8: 8, fieldUint16: 16, fieldUint32: 32}[f]
+
+			var v uint64
+			if v, err = strconv.ParseUint(tok, 10, bitSize); err == nil {
+				switch f {
+				case fieldUint8:
+					out = append(out, byte(v))
+				case fieldUint16:
+					out = binary.BigEndian.AppendUint16(out, uint16(v))
+				default:
+					out = binary.BigEndian.AppendUint32(out, uint32(v))
+				}
+			}
+		case fieldType:
+			v, ok := typeFromString(tok)
+			if !ok {
+				err = ErrUnsupportedType
+			}
+
+			out = binary.BigEndian.AppendUint16(out, v)
+		case fieldTime:
+			var v uint64
+
+			if len(tok) == len(timeFormat) {
+				var tm time.Time
+				if tm, err = time.Parse(timeFormat, tok); err == nil {
+					v = uint64(tm.Unix())
+				}
+			} else {
+				v, err = strconv.ParseUint(tok, 10, 32)
+			}
+
+			out = binary.BigEndian.AppendUint32(out, uint32(v))
+		case fieldName:
+			var wire []byte
+			if wire, err = nameToWire(absolute(tok, origin)); err == nil {
+				out = append(out, wire...)
+			}
+		case fieldA, fieldAAAA:
+			ip := net.ParseIP(tok)
+
+			switch {
+			case ip == nil:
+				err = ErrSyntax
+			case f == fieldA && ip.To4() != nil:
+				out = append(out, ip.To4()...)
+			case f == fieldAAAA && ip.To4() == nil:
+				out = append(out, ip.To16()...)
+			default:
+				err = ErrSyntax
+			}
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad %s field %q", ErrSyntax, TypeString(t), tok)
+		}
+	}
+
+	if len(tokens) != len(fields) {
+		return nil, fmt.Errorf("%w: extra %s RDATA", ErrSyntax, TypeString(t))
+	}
+
+	return out, nil
+}
+
+func parseRest(f field, tokens []string) ([]byte, error) {
+	switch f {
+	case fieldHex:
+		data, err := hex.DecodeString(strings.Join(tokens, ""))
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad hex", ErrSyntax)
+		}
+
+		return data, nil
+	case fieldBase64:
+		data, err := base64.StdEncoding.DecodeString(strings.Join(tokens, ""))
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad base64", ErrSyntax)
+		}
+
+		return data, nil
+	}
+
+	// Character strings, quotes already removed by the tokenizer
+	var out []byte
+
+	for _, s := range tokens {
+		if len(s) > 255 {
+			return nil, fmt.Errorf("%w: character string too long", ErrSyntax)
+		}
+
+		out = append(out, byte(len(s)))
+		out = append(out, s...)
+	}
+
+	return out, nil
+}
+
+// Presentation of wire RDATA, generic encoding when it does not decode
+func formatRData(t uint16, data []byte) string {
+	if s, ok := formatFields(t, data); ok {
+		return s
+	}
+
+	return fmt.Sprintf(`\# %d %x`, len(data), data)
+}
+
+func formatFields(t uint16, data []byte) (string, bool) {
+	fields, ok := rdataFields[t]
+
+	if !ok {
+		return "", false
+	}
+
+	var parts []string
+
+	for _, f := range fields {
+		switch f {
+		case fieldUint8:
+			if len(data) < 1 {
+				return "", false
+			}
+
+			parts = append(parts, strconv.Itoa(int(data[0])))
+			data = data[1:]
+		case fieldUint16, fieldType:
+			if len(data) < 2 {
+				return "", false
+			}
+
+			v := binary.BigEndian.Uint16(data)
+			data = data[2:]
+
+			if f == fieldType {
+				parts = append(parts, TypeString(v))
+			} else {
+				parts = append(parts, strconv.Itoa(int(v)))
+			}
+		case fieldUint32, fieldTime:
+			if len(data) < 4 {
+				return "", false
+			}
+
+			v := binary.BigEndian.Uint32(data)
+			data = data[4:]
+
+			if f == fieldTime {
+				parts = append(parts, time.Unix(int64(v), 0).UTC().Format(timeFormat))
+			} else {
+				parts = append(parts, strconv.FormatUint(uint64(v), 10))
+			}
+		case fieldName:
+			name, n, err := nameFromWire(data)
+			if err != nil {
+				return "", false
+			}
+
+			parts = append(parts, name)
+			data = data[n:]
+		case fieldA:
+			if len(data) != 4 {
+				return "", false
+			}
+
+			parts = append(parts, net.IP(data).String())
+			data = nil
+		case fieldAAAA:
+			if len(data) != 16 {
+				return "", false
+			}
+
+			parts = append(parts, net.IP(data).String())
+			data = nil
+		case fieldHex:
+			parts = append(parts, strings.ToUpper(hex.EncodeToString(data)))
+			data = nil
+		case fieldBase64:
+			parts = append(parts, base64.StdEncoding.EncodeToString(data))
+			data = nil
+		case fieldText:
+			for len(data) > 0 {
+				n := int(data[0])
+				if 1+n > len(data) {
+					return "", false
+				}
+
+				parts = append(parts, quoteText(string(data[1:1+n])))
+				data = data[1+n:]
+			}
+		}
+	}
+
+	return strings.Join(parts, " "), len(data) == 0
+}
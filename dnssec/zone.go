@@ -0,0 +1,290 @@
+package dnssec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Complete a relative name with the origin, '@' stands for the origin itself
+func absolute(name, origin string) string {
+	name = strings.ToLower(name)
+
+	switch {
+	case name == "@":
+		return origin
+	case strings.HasSuffix(name, "."):
+		return name
+	case origin == ".":
+		return name + "."
+	}
+
+	return name + "." + origin
+}
+
+// Logical line of a zone file, parentheses joined and comments removed
+type entry struct {
+	line   int
+	indent bool
+	tokens []string
+}
+
+// Split a zone file into logical lines of tokens
+//
+// Quoted strings become single tokens with \DDD and \X escapes resolved.
+func tokenize(r io.Reader) ([]entry, error) {
+	var (
+		entries []entry
+		cur     entry
+		depth   int
+	)
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+
+		if depth == 0 {
+			cur = entry{line: n, indent: len(line) > 0 && (line[0] == ' ' || line[0] == '\t')}
+		}
+
+		for i := 0; i < len(line); {
+			c := line[i]
+
+			switch {
+			case c == ';':
+				i = len(line)
+			case c == ' ' || c == '\t' || c == '\r':
+				i++
+			case c == '(':
+				depth++
+				i++
+			case c == ')':
+				if depth == 0 {
+					return nil, fmt.Errorf("%w: line %d: unbalanced parenthesis", ErrSyntax, n)
+				}
+
+				depth--
+				i++
+			case c == '"':
+				s, next, err := quoted(line, i+1)
+
+				if err != nil {
+					return nil, fmt.Errorf("%w: line %d", err, n)
+				}
+
+				cur.tokens = append(cur.tokens, s)
+				i = next
+			default:
+				j := i
+				for j < len(line) && !strings.ContainsRune(" \t\r;()\"", rune(line[j])) {
+					if line[j] == '\\' {
+						j++
+					}
+
+					j++
+				}
+
+				cur.tokens = append(cur.tokens, line[i:min(j, len(line))])
+				i = j
+			}
+		}
+
+		if depth == 0 && len(cur.tokens) > 0 {
+			entries = append(entries, cur)
+		}
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("%w: unbalanced parenthesis", ErrSyntax)
+	}
+
+	return entries, nil
+}
+
+// Read a quoted string starting after its opening quote
+func quoted(line string, i int) (string, int, error) {
+	var sb strings.Builder
+
+	for i < len(line) {
+		c := line[i]
+
+		switch {
+		case c == '"':
+			return sb.String(), i + 1, nil
+		case c == '\\' && i+3 < len(line) && isDigits(line[i+1:i+4]):
+			v, _ := strconv.Atoi(line[i+1 : i+4])
+			if v > 255 {
+				return "", 0, ErrSyntax
+			}
+
+			sb.WriteByte(byte(v))
+			i += 4
+		case c == '\\' && i+1 < len(line):
+			sb.WriteByte(line[i+1])
+			i += 2
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+
+	return "", 0, fmt.Errorf("%w: unterminated string", ErrSyntax)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Quote a character string for presentation, escaping '"', '\' and
+// non-printable bytes as \DDD
+func quoteText(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c < 0x20 || c > 0x7e:
+			fmt.Fprintf(&sb, "\\%03d", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	sb.WriteByte('"')
+
+	return sb.String()
+}
+
+// Parse a zone file in RFC 1035 master file format
+//
+// $ORIGIN and $TTL directives are supported, $INCLUDE is not. Names are
+// lowercased. A record without a TTL takes the $TTL value or the last TTL.
+func ParseZone(r io.Reader, origin string) ([]RR, error) {
+	entries, err := tokenize(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	origin = absolute(origin, ".")
+
+	var (
+		rrs        []RR
+		owner      string
+		defaultTTL = int64(-1)
+		lastTTL    = int64(-1)
+	)
+
+	for _, e := range entries {
+		toks := e.tokens
+
+		switch strings.ToUpper(toks[0]) {
+		case "$ORIGIN":
+			if len(toks) != 2 {
+				return nil, fmt.Errorf("%w: line %d: bad $ORIGIN", ErrSyntax, e.line)
+			}
+
+			origin = absolute(toks[1], origin)
+			continue
+		case "$TTL":
+			ttl, err := strconv.ParseUint(toks[min(1, len(toks)-1)], 10, 32)
+
+			if len(toks) != 2 || err != nil {
+				return nil, fmt.Errorf("%w: line %d: bad $TTL", ErrSyntax, e.line)
+			}
+
+			defaultTTL = int64(ttl)
+			continue
+		case "$INCLUDE":
+			return nil, fmt.Errorf("%w: line %d: $INCLUDE is not supported", ErrSyntax, e.line)
+		}
+
+		if !e.indent {
+			owner = absolute(toks[0], origin)
+			toks = toks[1:]
+		}
+
+		if owner == "" {
+			return nil, fmt.Errorf("%w: line %d: no owner name", ErrSyntax, e.line)
+		}
+
+		rr := RR{Name: owner, Class: ClassINET}
+		ttl := int64(-1)
+
+		// TTL and class in either order before the type
+		for len(toks) > 0 {
+			if v, err := strconv.ParseUint(toks[0], 10, 32); err == nil && ttl < 0 {
+				ttl = int64(v)
+			} else if strings.EqualFold(toks[0], "IN") {
+				rr.Class = ClassINET
+			} else {
+				break
+			}
+
+			toks = toks[1:]
+		}
+
+		if len(toks) == 0 {
+			return nil, fmt.Errorf("%w: line %d: missing type", ErrSyntax, e.line)
+		}
+
+		t, ok := typeFromString(toks[0])
+
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d: %s", ErrUnsupportedType, e.line, toks[0])
+		}
+
+		rr.Type = t
+
+		switch {
+		case ttl >= 0:
+			lastTTL = ttl
+		case defaultTTL >= 0:
+			ttl = defaultTTL
+		case lastTTL >= 0:
+			ttl = lastTTL
+		default:
+			return nil, fmt.Errorf("%w: line %d: no TTL", ErrSyntax, e.line)
+		}
+
+		rr.TTL = uint32(ttl)
+
+		if rr.Data, err = parseRData(t, toks[1:], origin); err != nil {
+			return nil, fmt.Errorf("line %d: %w", e.line, err)
+		}
+
+		rrs = append(rrs, rr)
+	}
+
+	return rrs, nil
+}
+
+// Write records in presentation format, one per line
+func WriteZone(w io.Writer, rrs []RR) error {
+	for _, rr := range rrs {
+		if _, err := fmt.Fprintln(w, rr.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+// Package dsse implements Dead Simple Signing Envelopes (DSSE v1) with
+// SLH-DSA signers and verifiers.
+package dsse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+var (
+	ErrNoSignature      = errors.New("dsse: envelope has no signatures")
+	ErrNoValidSignature = errors.New("dsse: no signature verified by the given keys")
+	ErrInvalidSignature = errors.New("dsse: invalid signature")
+	ErrMalformed        = errors.New("dsse: malformed envelope")
+)
+
+// Signed envelope, the payload is base64 encoded in JSON
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     []byte      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   []byte `json:"sig"`
+}
+
+// Produces signatures over the pre-authentication encoding
+type Signer interface {
+	KeyID() string
+	Sign(data []byte) ([]byte, error)
+}
+
+// Checks signatures over the pre-authentication encoding
+type Verifier interface {
+	KeyID() string
+	Verify(data, sig []byte) error
+}
+
+// Pre-authentication encoding
+//
+//	"DSSEv1" SP LEN(type) SP type SP LEN(body) SP body
+func PAE(payloadType string, payload []byte) []byte {
+	return append(fmt.Appendf(nil, "DSSEv1 %d %s %d ", len(payloadType), payloadType, len(payload)), payload...)
+}
+
+type signer struct {
+	paramSet string
+	sk       slhdsa.PrivateKey
+	keyID    string
+}
+
+// Signer of an SLH-DSA private key, the key ID defaults to slhdsa.KeyID of its public key
+func NewSigner(paramSet string, sk slhdsa.PrivateKey, keyID string) (Signer, error) {
+	pk, err := slhdsa.PublicKeyFromPrivateKey(paramSet, sk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if keyID == "" {
+		keyID = slhdsa.KeyID(pk)
+	}
+
+	return &signer{paramSet: paramSet, sk: sk, keyID: keyID}, nil
+}
+
+func (s *signer) KeyID() string {
+	return s.keyID
+}
+
+func (s *signer) Sign(data []byte) ([]byte, error) {
+	ctx, _ := slhdsa.New(s.paramSet)
+
+	return ctx.GenerateSignature(s.sk, data, nil, true, slhdsa.PreHashAlgorithm.Pure)
+}
+
+type verifier struct {
+	paramSet string
+	pk       slhdsa.PublicKey
+	keyID    string
+}
+
+// Verifier of an SLH-DSA public key, the key ID defaults to slhdsa.KeyID of the key
+func NewVerifier(paramSet string, pk slhdsa.PublicKey, keyID string) (Verifier, error) {
+	ctx, err := slhdsa.New(paramSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := ctx.GetPublicKeyFromBytes(pk.KeyBytes); err != nil {
+		return nil, err
+	}
+
+	if keyID == "" {
+		keyID = slhdsa.KeyID(pk)
+	}
+
+	return &verifier{paramSet: paramSet, pk: pk, keyID: keyID}, nil
+}
+
+func (v *verifier) KeyID() string {
+	return v.keyID
+}
+
+func (v *verifier) Verify(data, sig []byte) error {
+	ctx, _ := slhdsa.New(v.paramSet)
+	ok, err := ctx.VerifySignature(v.pk, data, sig, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil || !ok {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Sign the payload with every signer
+func Sign(payloadType string, payload []byte, signers ...Signer) (*Envelope, error) {
+	e := &Envelope{PayloadType: payloadType, Payload: payload}
+	pae := PAE(payloadType, payload)
+
+	for _, s := range signers {
+		sig, err := s.Sign(pae)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e.Signatures = append(e.Signatures, Signature{KeyID: s.KeyID(), Sig: sig})
+	}
+
+	return e, nil
+}
+
+// Verify the envelope, returns the key IDs of the verifiers that accepted a signature
+//
+// A signature with a key ID is only checked by verifiers of that ID, one
+// without is checked by all of them. Each verifier is reported once.
+func Verify(e *Envelope, verifiers ...Verifier) ([]string, error) {
+	if len(e.Signatures) == 0 {
+		return nil, ErrNoSignature
+	}
+
+	pae := PAE(e.PayloadType, e.Payload)
+	accepted := make([]bool, len(verifiers))
+
+	var keyIDs []string
+
+	for _, sig := range e.Signatures {
+		for i, v := range verifiers {
+			if accepted[i] || (sig.KeyID != "" && sig.KeyID != v.KeyID()) {
+				continue
+			}
+
+			if v.Verify(pae, sig.Sig) == nil {
+				accepted[i] = true
+				keyIDs = append(keyIDs, v.KeyID())
+			}
+		}
+	}
+
+	if len(keyIDs) == 0 {
+		return nil, ErrNoValidSignature
+	}
+
+	return keyIDs, nil
+}
+
+// Parse a JSON encoded envelope
+func Parse(data []byte) (*Envelope, error) {
+	var e Envelope
+
+	if err := json.Unmarshal(data, &e); err != nil || e.PayloadType == "" {
+		return nil, ErrMalformed
+	}
+
+	return &e, nil
+}
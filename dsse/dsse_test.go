@@ -0,0 +1,86 @@
+package dsse_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/dsse"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+)
+
+func newKeys(t *testing.T, paramSet, keyID string) (dsse.Signer, dsse.Verifier) {
+	sk, pk := testkey.New(t, paramSet)
+	s, err := dsse.NewSigner(paramSet, sk, keyID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := dsse.NewVerifier(paramSet, pk, keyID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s, v
+}
+
+func TestPAE(t *testing.T) {
+	// Test vector from the DSSE protocol specification
+	got := string(dsse.PAE("http://example.com/HelloWorld", []byte("hello world")))
+
+	if got != "DSSEv1 29 http://example.com/HelloWorld 11 hello world" {
+		t.Errorf("unexpected PAE %q", got)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	s1, v1 := newKeys(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "")
+	s2, v2 := newKeys(t, slhdsa.ParameterSet.SLHDSA_SHAKE_128f, "release")
+	_, other := newKeys(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "")
+
+	if s1.KeyID() != v1.KeyID() || len(s1.KeyID()) != 64 {
+		t.Errorf("default key IDs differ: %q %q", s1.KeyID(), v1.KeyID())
+	}
+
+	e, err := dsse.Sign("application/example", []byte("payload"), s1, s2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(e)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err = dsse.Parse(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyIDs, err := dsse.Verify(e, v1, v2, other)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keyIDs) != 2 || keyIDs[0] != v1.KeyID() || keyIDs[1] != "release" {
+		t.Errorf("unexpected accepted keys %v", keyIDs)
+	}
+
+	if _, err := dsse.Verify(e, other); !errors.Is(err, dsse.ErrNoValidSignature) {
+		t.Errorf("expected ErrNoValidSignature, got %v", err)
+	}
+
+	// The payload type is covered by the signature
+	e.PayloadType = "application/other"
+
+	if _, err := dsse.Verify(e, v1, v2); !errors.Is(err, dsse.ErrNoValidSignature) {
+		t.Errorf("verified with a changed payload type: %v", err)
+	}
+}
@@ -0,0 +1,402 @@
+// Package elfsign embeds SLH-DSA signatures in ELF binaries.
+//
+// The signature covers the loadable content of the file: the ELF header,
+// the program header table and the file contents of every PT_LOAD segment.
+// Section headers are not covered, so the ELF header fields locating them
+// (e_shoff, e_shnum, e_shstrndx) are hashed as zero. The signed message is
+// the SHA-512 digest of that content.
+//
+// Signing appends a note section named .note.slhdsa holding one note of
+// owner "SLHDSA" and type NoteTypeSignature, along with a new section
+// header table. The descriptor is
+//
+//	version (1) || len(parameter set) || parameter set || SHA-256(public key) || signature
+//
+// The note is not part of any segment, so signed binaries load and run
+// unchanged.
+package elfsign
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"debug/elf"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Name of the section holding the signature note
+const SectionName = ".note.slhdsa"
+
+// Owner and type of the signature note
+const (
+	NoteName          = "SLHDSA"
+	NoteTypeSignature = 1
+)
+
+const noteVersion = 1
+
+var (
+	ErrNotELF           = errors.New("elfsign: not an ELF file")
+	ErrUnsupported      = errors.New("elfsign: unsupported ELF layout")
+	ErrAlreadySigned    = errors.New("elfsign: file already carries a signature section")
+	ErrNotSigned        = errors.New("elfsign: file carries no signature")
+	ErrMalformed        = errors.New("elfsign: malformed signature note")
+	ErrUnknownKey       = errors.New("elfsign: signed by an untrusted key")
+	ErrInvalidSignature = errors.New("elfsign: invalid signature")
+)
+
+// Embedded signature
+type Signature struct {
+	ParameterSet string
+	KeyID        string
+	Signature    []byte
+}
+
+// Offsets of the ELF header fields describing the section header table
+type layout struct {
+	class     elf.Class
+	order     binary.ByteOrder
+	ehsize    int
+	shoff     int
+	shnum     int
+	shstrndx  int
+	shentsize int
+}
+
+func parseLayout(data []byte) (*layout, *elf.File, error) {
+	f, err := elf.NewFile(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, nil, ErrNotELF
+	}
+
+	l := &layout{class: f.Class, order: f.ByteOrder}
+
+	switch f.Class {
+	case elf.ELFCLASS64:
+		l.ehsize, l.shoff, l.shnum, l.shstrndx, l.shentsize = 64, 0x28, 0x3c, 0x3e, 64
+	case elf.ELFCLASS32:
+		l.ehsize, l.shoff, l.shnum, l.shstrndx, l.shentsize = 52, 0x20, 0x30, 0x32, 40
+	default:
+		return nil, nil, ErrUnsupported
+	}
+
+	if len(data) < l.ehsize {
+		return nil, nil, ErrNotELF
+	}
+
+	return l, f, nil
+}
+
+func (l *layout) uint(data []byte, off int) uint64 {
+	if l.class == elf.ELFCLASS64 {
+		return l.order.Uint64(data[off:])
+	}
+
+	return uint64(l.order.Uint32(data[off:]))
+}
+
+func (l *layout) putUint(data []byte, off int, v uint64) {
+	if l.class == elf.ELFCLASS64 {
+		l.order.PutUint64(data[off:], v)
+	} else {
+		l.order.PutUint32(data[off:], uint32(v))
+	}
+}
+
+// Digest of the loadable content
+func digest(data []byte, l *layout, f *elf.File) ([]byte, error) {
+	header := bytes.Clone(data[:l.ehsize])
+	l.putUint(header, l.shoff, 0)
+	l.order.PutUint16(header[l.shnum:], 0)
+	l.order.PutUint16(header[l.shstrndx:], 0)
+
+	h := sha512.New()
+
+	// File range with the normalized header in place of the original
+	hashRange := func(off, n uint64) error {
+		if off > uint64(len(data)) || n > uint64(len(data))-off {
+			return ErrUnsupported
+		}
+
+		if off < uint64(l.ehsize) {
+			k := min(n, uint64(l.ehsize)-off)
+			h.Write(header[off : off+k])
+			off, n = off+k, n-k
+		}
+
+		h.Write(data[off : off+n])
+
+		return nil
+	}
+
+	h.Write(header)
+
+	var phoff, phentsize uint64
+
+	if l.class == elf.ELFCLASS64 {
+		phoff, phentsize = l.order.Uint64(data[0x20:]), uint64(l.order.Uint16(data[0x36:]))
+	} else {
+		phoff, phentsize = uint64(l.order.Uint32(data[0x1c:])), uint64(l.order.Uint16(data[0x2a:]))
+	}
+
+	if err := hashRange(phoff, phentsize*uint64(len(f.Progs))); err != nil {
+		return nil, err
+	}
+
+	for _, p := range f.Progs {
+		if p.Type != elf.PT_LOAD {
+			continue
+		}
+
+		if err := hashRange(p.Off, p.Filesz); err != nil {
+			return nil, err
+		}
+	}
+
+	return h.Sum(nil), nil
+}
+
+// SHA-512 digest of the loadable content of an ELF file, the signed message
+func Digest(data []byte) ([]byte, error) {
+	l, f, err := parseLayout(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return digest(data, l, f)
+}
+
+func align(data []byte, n int) []byte {
+	for len(data)%n != 0 {
+		data = append(data, 0)
+	}
+
+	return data
+}
+
+// ELF note of the signature
+func (l *layout) note(desc []byte) []byte {
+	note := make([]byte, 12)
+	l.order.PutUint32(note[0:], uint32(len(NoteName)+1))
+	l.order.PutUint32(note[4:], uint32(len(desc)))
+	l.order.PutUint32(note[8:], NoteTypeSignature)
+	note = align(append(note, NoteName+"\x00"...), 4)
+
+	return align(append(note, desc...), 4)
+}
+
+// Sign the loadable content of an ELF file, returns the file with the
+// signature note section added
+func Sign(data []byte, paramSet string, sk slhdsa.PrivateKey) ([]byte, error) {
+	l, f, err := parseLayout(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Section(SectionName) != nil {
+		return nil, ErrAlreadySigned
+	}
+
+	// Extended section numbering keeps the counts in section 0, not handled
+	shoff := l.uint(data, l.shoff)
+	shnum := int(l.order.Uint16(data[l.shnum:]))
+	shstrndx := int(l.order.Uint16(data[l.shstrndx:]))
+
+	if shoff == 0 || shnum == 0 || shstrndx == 0 || shstrndx >= shnum || len(f.Sections) != shnum || shnum+1 >= int(elf.SHN_LORESERVE) {
+		return nil, ErrUnsupported
+	}
+
+	table := shoff + uint64(shnum*l.shentsize)
+
+	if table > uint64(len(data)) || shoff > table {
+		return nil, ErrUnsupported
+	}
+
+	msg, err := digest(data, l, f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := slhdsa.New(paramSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := ctx.GenerateSignature(sk, msg, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := slhdsa.PublicKeyFromPrivateKey(paramSet, sk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := sha256.Sum256(pk.KeyBytes)
+
+	desc := []byte{noteVersion, byte(len(paramSet))}
+	desc = append(desc, paramSet...)
+	desc = append(desc, keyID[:]...)
+	desc = append(desc, sig...)
+
+	shstrtab, err := f.Sections[shstrndx].Data()
+
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+
+	// Appended after the original contents: the note, a copy of the section
+	// name table with the new name, and the extended section header table
+	out := align(bytes.Clone(data), 4)
+
+	noteOff := len(out)
+	out = append(out, l.note(desc)...)
+	noteSize := len(out) - noteOff
+
+	strOff := len(out)
+	nameOff := len(shstrtab)
+	out = append(out, shstrtab...)
+	out = append(out, SectionName+"\x00"...)
+	strSize := len(out) - strOff
+
+	out = align(out, 8)
+	newShoff := len(out)
+	out = append(out, data[shoff:table]...)
+
+	str := out[newShoff+shstrndx*l.shentsize:]
+
+	if l.class == elf.ELFCLASS64 {
+		l.putUint(str, 0x18, uint64(strOff))
+		l.putUint(str, 0x20, uint64(strSize))
+	} else {
+		l.putUint(str, 0x10, uint64(strOff))
+		l.putUint(str, 0x14, uint64(strSize))
+	}
+
+	sh := make([]byte, l.shentsize)
+	l.order.PutUint32(sh[0:], uint32(nameOff))
+	l.order.PutUint32(sh[4:], uint32(elf.SHT_NOTE))
+
+	if l.class == elf.ELFCLASS64 {
+		l.putUint(sh, 0x18, uint64(noteOff))
+		l.putUint(sh, 0x20, uint64(noteSize))
+		l.putUint(sh, 0x30, 4)
+	} else {
+		l.putUint(sh, 0x10, uint64(noteOff))
+		l.putUint(sh, 0x14, uint64(noteSize))
+		l.putUint(sh, 0x20, 4)
+	}
+
+	out = append(out, sh...)
+
+	l.putUint(out, l.shoff, uint64(newShoff))
+	l.order.PutUint16(out[l.shnum:], uint16(shnum+1))
+
+	return out, nil
+}
+
+// Parse the signature note of an ELF file
+func ReadSignature(data []byte) (*Signature, error) {
+	_, f, err := parseLayout(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return readSignature(f)
+}
+
+func readSignature(f *elf.File) (*Signature, error) {
+	s := f.Section(SectionName)
+
+	if s == nil || s.Type != elf.SHT_NOTE {
+		return nil, ErrNotSigned
+	}
+
+	note, err := s.Data()
+
+	if err != nil || len(note) < 12 {
+		return nil, ErrMalformed
+	}
+
+	namesz := uint64(f.ByteOrder.Uint32(note[0:]))
+	descsz := uint64(f.ByteOrder.Uint32(note[4:]))
+	typ := f.ByteOrder.Uint32(note[8:])
+	descOff := 12 + (namesz+3)&^3
+
+	if namesz != uint64(len(NoteName)+1) || typ != NoteTypeSignature || descOff > uint64(len(note)) || descsz > uint64(len(note))-descOff {
+		return nil, ErrMalformed
+	}
+
+	if string(note[12:12+namesz]) != NoteName+"\x00" {
+		return nil, ErrMalformed
+	}
+
+	desc := note[descOff : descOff+descsz]
+
+	if len(desc) < 2 || desc[0] != noteVersion || len(desc) < 2+int(desc[1])+sha256.Size {
+		return nil, ErrMalformed
+	}
+
+	n := int(desc[1])
+
+	return &Signature{
+		ParameterSet: string(desc[2 : 2+n]),
+		KeyID:        hex.EncodeToString(desc[2+n : 2+n+sha256.Size]),
+		Signature:    desc[2+n+sha256.Size:],
+	}, nil
+}
+
+// Verify the embedded signature against the trusted keys
+func Verify(data []byte, keys []slhdsa.TrustedKey) (*Signature, error) {
+	l, f, err := parseLayout(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := readSignature(f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := digest(data, l, f)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range keys {
+		if k.ParameterSet != sig.ParameterSet || slhdsa.KeyID(k.PublicKey) != sig.KeyID {
+			continue
+		}
+
+		ctx, err := slhdsa.New(k.ParameterSet)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ok, err := ctx.VerifySignature(k.PublicKey, msg, sig.Signature, nil, slhdsa.PreHashAlgorithm.Pure)
+
+		if err != nil || !ok {
+			return nil, ErrInvalidSignature
+		}
+
+		return sig, nil
+	}
+
+	return nil, ErrUnknownKey
+}
@@ -0,0 +1,139 @@
+package elfsign_test
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/elfsign"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+)
+
+func newKey(t *testing.T) (slhdsa.PrivateKey, slhdsa.TrustedKey) {
+	sk, pk := testkey.New(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+
+	return sk, slhdsa.TrustedKey{ParameterSet: slhdsa.ParameterSet.SLHDSA_SHA2_128f, PublicKey: pk}
+}
+
+// The test binary itself is the ELF file under test
+func testBinary(t *testing.T) []byte {
+	if runtime.GOOS != "linux" {
+		t.Skip("test binary is not ELF on", runtime.GOOS)
+	}
+
+	path, err := os.Executable()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestSignVerify(t *testing.T) {
+	data := testBinary(t)
+	sk, key := newKey(t)
+
+	signed, err := elfsign.Sign(data, key.ParameterSet, sk)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The digest ignores the added section and section header table
+	d1, _ := elfsign.Digest(data)
+	d2, _ := elfsign.Digest(signed)
+
+	if !bytes.Equal(d1, d2) {
+		t.Error("signing changed the digest")
+	}
+
+	f, err := elf.NewFile(bytes.NewReader(signed))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := f.Section(elfsign.SectionName); s == nil || s.Type != elf.SHT_NOTE {
+		t.Fatal("signature section missing")
+	}
+
+	if f.Section(".text") == nil {
+		t.Error("original sections lost")
+	}
+
+	sig, err := elfsign.Verify(signed, []slhdsa.TrustedKey{key})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sig.KeyID != slhdsa.KeyID(key.PublicKey) || sig.ParameterSet != key.ParameterSet {
+		t.Errorf("unexpected signature %+v", sig)
+	}
+
+	if _, err := elfsign.Sign(signed, key.ParameterSet, sk); !errors.Is(err, elfsign.ErrAlreadySigned) {
+		t.Errorf("signing twice: %v", err)
+	}
+
+	// Signed binaries still run
+	path := filepath.Join(t.TempDir(), "signed.test")
+
+	if err := os.WriteFile(path, signed, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := exec.Command(path, "-test.run=^$").CombinedOutput(); err != nil {
+		t.Errorf("signed binary failed to run: %v\n%s", err, out)
+	}
+}
+
+func TestVerifyErrors(t *testing.T) {
+	data := testBinary(t)
+	sk, key := newKey(t)
+	_, other := newKey(t)
+
+	if _, err := elfsign.Verify(data, []slhdsa.TrustedKey{key}); !errors.Is(err, elfsign.ErrNotSigned) {
+		t.Errorf("unsigned: %v", err)
+	}
+
+	signed, err := elfsign.Sign(data, key.ParameterSet, sk)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := elfsign.Verify(signed, []slhdsa.TrustedKey{other}); !errors.Is(err, elfsign.ErrUnknownKey) {
+		t.Errorf("untrusted key: %v", err)
+	}
+
+	// Flip a byte inside the first loadable segment past the ELF header
+	f, _ := elf.NewFile(bytes.NewReader(signed))
+
+	for _, p := range f.Progs {
+		if p.Type == elf.PT_LOAD && p.Filesz > 0x1000 {
+			signed[p.Off+p.Filesz/2] ^= 1
+			break
+		}
+	}
+
+	if _, err := elfsign.Verify(signed, []slhdsa.TrustedKey{key}); !errors.Is(err, elfsign.ErrInvalidSignature) {
+		t.Errorf("tampered binary: %v", err)
+	}
+
+	if _, err := elfsign.Verify([]byte("#!/bin/sh\n"), nil); !errors.Is(err, elfsign.ErrNotELF) {
+		t.Errorf("not ELF: %v", err)
+	}
+}
@@ -0,0 +1,495 @@
+// Package gitsign implements the subset of the gpg and gpgsm command line
+// that git uses through gpg.program and gpg.x509.program, signing and
+// verifying with SLH-DSA keys.
+//
+// Signing keys are files: an armored OpenPGP secret key produces an armored
+// OpenPGP v6 detached signature ("PGP SIGNATURE"), a private JWK produces a
+// detached CMS SignedData armored as "SIGNED MESSAGE". Verification picks the
+// format from the signature armor and looks the signer up in a trusted keys
+// file holding armored OpenPGP public keys and single-line public JWKs.
+package gitsign
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skuuzie/go-slhdsa/cms"
+	"github.com/skuuzie/go-slhdsa/jose"
+	"github.com/skuuzie/go-slhdsa/openpgp"
+)
+
+// PEM type of CMS signatures, as gpgsm armors them
+const BlockSignedMessage = "SIGNED MESSAGE"
+
+var (
+	ErrUsage           = errors.New("gitsign: unsupported arguments")
+	ErrUnknownKeyType  = errors.New("gitsign: key file is neither an OpenPGP secret key nor a JWK")
+	ErrUnknownArmor    = errors.New("gitsign: unrecognized signature armor")
+	ErrNoSigningKey    = errors.New("gitsign: no signing key configured")
+	ErrTrustedKeysFile = errors.New("gitsign: malformed trusted keys file")
+)
+
+// Environment of one invocation
+type Config struct {
+	// Signing key file used when the key git names (user.signingkey or the
+	// committer identity) is not a readable file
+	DefaultKey string
+
+	// Trusted keys file
+	TrustedKeys string
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Parsed gpg command line
+type invocation struct {
+	statusFD  int
+	sign      bool
+	localUser string
+	verify    bool
+	files     []string
+}
+
+// Parse the options git passes, such as
+//
+//	--status-fd=2 -bsau <key>
+//	--keyid-format=long --status-fd=1 --verify <signature-file> -
+func parseArgs(args []string) (*invocation, error) {
+	inv := &invocation{statusFD: -1}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		next := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", ErrUsage
+			}
+
+			i++
+
+			return args[i], nil
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		var err error
+
+		switch {
+		case name == "--status-fd":
+			if !hasValue {
+				if value, err = next(); err != nil {
+					return nil, err
+				}
+			}
+
+			fd, err := strconv.Atoi(value)
+
+			if err != nil || fd < 0 {
+				return nil, ErrUsage
+			}
+
+			inv.statusFD = fd
+		case name == "--keyid-format", arg == "--armor", arg == "--detach-sign", arg == "--sign", arg == "--batch", arg == "--no-tty":
+			inv.sign = inv.sign || arg == "--detach-sign" || arg == "--sign"
+		case name == "--local-user":
+			if !hasValue {
+				if value, err = next(); err != nil {
+					return nil, err
+				}
+			}
+
+			inv.localUser = value
+		case arg == "--verify":
+			inv.verify = true
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			inv.files = append(inv.files, arg)
+		case !strings.HasPrefix(arg, "--"):
+			// Bundled short options, u takes the rest of the word or the
+			// following argument
+			for j := 1; j < len(arg); j++ {
+				switch arg[j] {
+				case 'b', 's':
+					inv.sign = true
+				case 'a':
+				case 'u':
+					if j+1 < len(arg) {
+						inv.localUser = arg[j+1:]
+					} else if inv.localUser, err = next(); err != nil {
+						return nil, err
+					}
+
+					j = len(arg)
+				default:
+					return nil, ErrUsage
+				}
+			}
+		default:
+			return nil, ErrUsage
+		}
+	}
+
+	if inv.sign == inv.verify {
+		return nil, ErrUsage
+	}
+
+	return inv, nil
+}
+
+// Status line writer for --status-fd
+func statusWriter(fd int, cfg *Config) io.Writer {
+	switch fd {
+	case -1:
+		return io.Discard
+	case 1:
+		return cfg.Stdout
+	case 2:
+		return cfg.Stderr
+	}
+
+	return os.NewFile(uintptr(fd), "status")
+}
+
+func status(w io.Writer, format string, args ...any) {
+	fmt.Fprintf(w, "[GNUPG:] "+format+"\n", args...)
+}
+
+// Run one gpg invocation, returns the exit status
+func Run(args []string, cfg *Config) int {
+	inv, err := parseArgs(args)
+
+	if err != nil {
+		fmt.Fprintf(cfg.Stderr, "%v: %q\n", err, args)
+		return 2
+	}
+
+	st := statusWriter(inv.statusFD, cfg)
+
+	if inv.sign {
+		if err := sign(inv, cfg, st); err != nil {
+			fmt.Fprintln(cfg.Stderr, err)
+			return 2
+		}
+
+		return 0
+	}
+
+	return verify(inv, cfg, st)
+}
+
+func readSigningKey(inv *invocation, cfg *Config) ([]byte, error) {
+	if inv.localUser != "" {
+		if data, err := os.ReadFile(inv.localUser); err == nil {
+			return data, nil
+		}
+	}
+
+	if cfg.DefaultKey == "" {
+		return nil, ErrNoSigningKey
+	}
+
+	return os.ReadFile(cfg.DefaultKey)
+}
+
+func sign(inv *invocation, cfg *Config, st io.Writer) error {
+	keyData, err := readSigningKey(inv, cfg)
+
+	if err != nil {
+		return err
+	}
+
+	payload, err := io.ReadAll(cfg.Stdin)
+
+	if err != nil {
+		return err
+	}
+
+	if blockType, der, err := openpgp.Dearmor(keyData); err == nil && blockType == openpgp.BlockSecretKey {
+		k, err := openpgp.ReadKey(der)
+
+		if err != nil {
+			return err
+		}
+
+		sig, err := openpgp.SignDetached(k, payload, false)
+
+		if err != nil {
+			return err
+		}
+
+		parsed, err := openpgp.ParseSignature(sig)
+
+		if err != nil {
+			return err
+		}
+
+		fpr := strings.ToUpper(hex.EncodeToString(k.Fingerprint()))
+
+		status(st, "KEY_CONSIDERED %s 2", fpr)
+		status(st, "BEGIN_SIGNING H%d", parsed.Hash)
+		status(st, "SIG_CREATED D %d %d 00 %d %s", parsed.Algorithm, parsed.Hash, parsed.CreationTime.Unix(), fpr)
+
+		_, err = cfg.Stdout.Write(openpgp.Armor(openpgp.BlockSignature, sig))
+
+		return err
+	}
+
+	k, err := jose.ParseJWK(bytes.TrimSpace(keyData))
+
+	if err != nil {
+		return ErrUnknownKeyType
+	}
+
+	sk, err := k.PrivateKey()
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	der, err := cms.Sign(payload, []cms.Signer{{ParameterSet: k.Alg, PrivateKey: sk}}, &cms.SignOptions{Detached: true, SigningTime: now})
+
+	if err != nil {
+		return err
+	}
+
+	pk, err := k.PublicKey()
+
+	if err != nil {
+		return err
+	}
+
+	ski := strings.ToUpper(hex.EncodeToString(cms.SubjectKeyID(pk)))
+
+	status(st, "KEY_CONSIDERED %s 0", ski)
+	status(st, "BEGIN_SIGNING")
+	status(st, "SIG_CREATED D 0 0 00 %d %s", now.Unix(), ski)
+
+	return pem.Encode(cfg.Stdout, &pem.Block{Type: BlockSignedMessage, Bytes: der})
+}
+
+// Keys of a trusted keys file
+type trustedKeys struct {
+	openpgp []*openpgp.Key
+	jwks    []*jose.JWK
+}
+
+// Parse a trusted keys file: armored OpenPGP public key blocks and public
+// JWKs on single lines, with blank lines and # comments ignored
+func parseTrustedKeys(data []byte) (*trustedKeys, error) {
+	keys := &trustedKeys{}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "{"):
+			k, err := jose.ParseJWK([]byte(line))
+
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrTrustedKeysFile, i+1, err)
+			}
+
+			keys.jwks = append(keys.jwks, k.Public())
+		case line == "-----BEGIN "+openpgp.BlockPublicKey+"-----":
+			start := i
+
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "-----END "+openpgp.BlockPublicKey+"-----" {
+				i++
+			}
+
+			_, der, err := openpgp.Dearmor([]byte(strings.Join(lines[start:min(i+1, len(lines))], "\n")))
+
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrTrustedKeysFile, start+1, err)
+			}
+
+			k, err := openpgp.ReadKey(der)
+
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrTrustedKeysFile, start+1, err)
+			}
+
+			keys.openpgp = append(keys.openpgp, k)
+		default:
+			return nil, fmt.Errorf("%w: line %d", ErrTrustedKeysFile, i+1)
+		}
+	}
+
+	return keys, nil
+}
+
+// Signature file and payload of a --verify invocation
+func verifyInputs(inv *invocation, cfg *Config) ([]byte, []byte, error) {
+	if len(inv.files) == 0 {
+		return nil, nil, ErrUsage
+	}
+
+	sig, err := os.ReadFile(inv.files[0])
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var payload []byte
+
+	if len(inv.files) == 1 || inv.files[1] == "-" {
+		payload, err = io.ReadAll(cfg.Stdin)
+	} else {
+		payload, err = os.ReadFile(inv.files[1])
+	}
+
+	return sig, payload, err
+}
+
+// Verify and report in gpg status lines, exit status 0 for a good
+// signature, 1 for a bad one and 2 when it cannot be checked
+func verify(inv *invocation, cfg *Config, st io.Writer) int {
+	sig, payload, err := verifyInputs(inv, cfg)
+
+	if err != nil {
+		fmt.Fprintln(cfg.Stderr, err)
+		return 2
+	}
+
+	trustedData, err := os.ReadFile(cfg.TrustedKeys)
+
+	if err != nil {
+		fmt.Fprintln(cfg.Stderr, err)
+		return 2
+	}
+
+	keys, err := parseTrustedKeys(trustedData)
+
+	if err != nil {
+		fmt.Fprintln(cfg.Stderr, err)
+		return 2
+	}
+
+	status(st, "NEWSIG")
+
+	if block, _ := pem.Decode(sig); block != nil && block.Type == BlockSignedMessage {
+		return verifyCMS(block.Bytes, payload, keys, cfg, st)
+	}
+
+	if blockType, der, err := openpgp.Dearmor(sig); err == nil && blockType == openpgp.BlockSignature {
+		return verifyOpenPGP(der, payload, keys, cfg, st)
+	}
+
+	fmt.Fprintln(cfg.Stderr, ErrUnknownArmor)
+	status(st, "NODATA 4")
+
+	return 2
+}
+
+func verifyOpenPGP(der, payload []byte, keys *trustedKeys, cfg *Config, st io.Writer) int {
+	s, err := openpgp.ParseSignature(der)
+
+	if err != nil {
+		fmt.Fprintln(cfg.Stderr, err)
+		status(st, "NODATA 3")
+		return 2
+	}
+
+	fpr := strings.ToUpper(hex.EncodeToString(s.IssuerFingerprint))
+	keyID := fpr[:min(16, len(fpr))]
+	created := s.CreationTime.UTC()
+
+	fmt.Fprintf(cfg.Stderr, "gpg: Signature made %s\ngpg:                using SLH-DSA key %s\n", created.Format(time.UnixDate), fpr)
+
+	_, k, err := openpgp.VerifyDetached(keys.openpgp, payload, der)
+
+	switch {
+	case errors.Is(err, openpgp.ErrUnknownIssuer):
+		status(st, "ERRSIG %s %d %d %02x %d 9 %s", keyID, s.Algorithm, s.Hash, s.Type, created.Unix(), fpr)
+		status(st, "NO_PUBKEY %s", keyID)
+		fmt.Fprintln(cfg.Stderr, "gpg: Can't check signature: No public key")
+
+		return 2
+	case err != nil:
+		uid := ""
+		for _, k := range keys.openpgp {
+			if bytes.Equal(k.Fingerprint(), s.IssuerFingerprint) {
+				uid = k.UserID
+			}
+		}
+
+		status(st, "BADSIG %s %s", keyID, uid)
+		fmt.Fprintf(cfg.Stderr, "gpg: BAD signature from \"%s\"\n", uid)
+
+		return 1
+	}
+
+	status(st, "KEY_CONSIDERED %s 0", fpr)
+	status(st, "SIG_ID - %s %d", created.Format(time.DateOnly), created.Unix())
+	status(st, "GOODSIG %s %s", keyID, k.UserID)
+	status(st, "VALIDSIG %s %s %d 0 6 0 %d %d %02X %s", fpr, created.Format(time.DateOnly), created.Unix(), s.Algorithm, s.Hash, s.Type, fpr)
+	status(st, "TRUST_FULLY 0 pgp")
+	fmt.Fprintf(cfg.Stderr, "gpg: Good signature from \"%s\"\n", k.UserID)
+
+	return 0
+}
+
+func verifyCMS(der, payload []byte, keys *trustedKeys, cfg *Config, st io.Writer) int {
+	sd, err := cms.Parse(der)
+
+	if err != nil || len(sd.Signers) == 0 {
+		fmt.Fprintln(cfg.Stderr, "gpgsm: malformed signature")
+		status(st, "NODATA 3")
+		return 2
+	}
+
+	si := sd.Signers[0]
+	ski := strings.ToUpper(hex.EncodeToString(si.SubjectKeyID))
+	created := si.SigningTime.UTC()
+
+	for _, k := range keys.jwks {
+		pk, err := k.PublicKey()
+
+		if err != nil || k.Alg != si.ParameterSet || !bytes.Equal(cms.SubjectKeyID(pk), si.SubjectKeyID) {
+			continue
+		}
+
+		name := k.Kid
+		if name == "" {
+			name = k.Thumbprint()
+		}
+
+		// Git signatures are detached, attached content signs something other than the payload
+		if sd.Content != nil {
+			err = cms.ErrAttachedContent
+		} else {
+			_, err = sd.Verify(payload, []cms.VerifierKey{{ParameterSet: k.Alg, PublicKey: pk}})
+		}
+
+		if err != nil {
+			status(st, "BADSIG %s %s", ski, name)
+			fmt.Fprintf(cfg.Stderr, "gpgsm: BAD signature from \"%s\"\n", name)
+
+			return 1
+		}
+
+		status(st, "GOODSIG %s %s", ski, name)
+		status(st, "VALIDSIG %s %s %d 0 0 0 0 0 00 %s", ski, created.Format(time.DateOnly), created.Unix(), ski)
+		status(st, "TRUST_FULLY 0 chain")
+		fmt.Fprintf(cfg.Stderr, "gpgsm: Signature made %s\ngpgsm: Good signature from \"%s\"\n", created.Format(time.UnixDate), name)
+
+		return 0
+	}
+
+	status(st, "ERRSIG %s 0 0 00 %d 9", ski, created.Unix())
+	status(st, "NO_PUBKEY %s", ski)
+	fmt.Fprintln(cfg.Stderr, "gpgsm: can't check signature: no public key")
+
+	return 2
+}
@@ -0,0 +1,197 @@
+package gitsign_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/cms"
+	"github.com/skuuzie/go-slhdsa/gitsign"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+	"github.com/skuuzie/go-slhdsa/jose"
+	"github.com/skuuzie/go-slhdsa/openpgp"
+)
+
+const commit = "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\nauthor A U Thor <author@example.com> 1700000000 +0000\ncommitter A U Thor <author@example.com> 1700000000 +0000\n\nInitial commit\n"
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func newOpenPGPKey(t *testing.T, dir, uid string) (string, []byte) {
+	k, err := openpgp.GenerateKey(slhdsa.ParameterSet.SLHDSA_SHAKE_128f, uid)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := k.SerializePrivate()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public, err := k.SerializePublic()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return writeFile(t, dir, uid+".asc", openpgp.Armor(openpgp.BlockSecretKey, secret)), openpgp.Armor(openpgp.BlockPublicKey, public)
+}
+
+func newJWK(t *testing.T, dir string) (string, []byte) {
+	sk, _ := testkey.New(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	k, err := jose.NewPrivateJWK(slhdsa.ParameterSet.SLHDSA_SHA2_128f, sk)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k.Kid = "ci@example.com"
+
+	secret, err := json.Marshal(k)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	public, err := json.Marshal(k.Public())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return writeFile(t, dir, "signing.jwk", secret), public
+}
+
+// Run as git does, returning the exit status, stdout and stderr
+func run(cfg gitsign.Config, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	cfg.Stdin, cfg.Stdout, cfg.Stderr = strings.NewReader(stdin), &stdout, &stderr
+
+	code := gitsign.Run(args, &cfg)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func signAndVerify(t *testing.T, cfg gitsign.Config, keyPath, armor string) {
+	code, sig, stderr := run(cfg, commit, "--status-fd=2", "-bsau", keyPath)
+
+	if code != 0 || !strings.Contains(stderr, "\n[GNUPG:] SIG_CREATED ") {
+		t.Fatalf("sign exited %d: %s", code, stderr)
+	}
+
+	if !strings.HasPrefix(sig, "-----BEGIN "+armor+"-----\n") {
+		t.Fatalf("unexpected signature armor %q", sig)
+	}
+
+	sigPath := writeFile(t, t.TempDir(), "sig", []byte(sig))
+
+	code, status, _ := run(cfg, commit, "--keyid-format=long", "--status-fd=1", "--verify", sigPath, "-")
+
+	if code != 0 || !strings.Contains(status, "\n[GNUPG:] GOODSIG ") || !strings.Contains(status, "\n[GNUPG:] VALIDSIG ") {
+		t.Errorf("verify exited %d: %s", code, status)
+	}
+
+	code, status, _ = run(cfg, commit+"tampered", "--status-fd=1", "--verify", sigPath, "-")
+
+	if code != 1 || !strings.Contains(status, "\n[GNUPG:] BADSIG ") {
+		t.Errorf("tampered commit: verify exited %d: %s", code, status)
+	}
+}
+
+func TestOpenPGP(t *testing.T) {
+	dir := t.TempDir()
+	keyPath, public := newOpenPGPKey(t, dir, "alice")
+	_, otherPublic := newOpenPGPKey(t, dir, "bob")
+
+	trusted := append([]byte("# team keys\n\n"), otherPublic...)
+	trusted = append(trusted, public...)
+
+	cfg := gitsign.Config{TrustedKeys: writeFile(t, dir, "trusted_keys", trusted)}
+	signAndVerify(t, cfg, keyPath, openpgp.BlockSignature)
+
+	// The committer identity git passes is not a file, the default key is used
+	cfg.DefaultKey = keyPath
+	code, _, stderr := run(cfg, commit, "--status-fd=2", "-bsau", "A U Thor <author@example.com>")
+
+	if code != 0 {
+		t.Errorf("default key: sign exited %d: %s", code, stderr)
+	}
+}
+
+func TestX509(t *testing.T) {
+	dir := t.TempDir()
+	keyPath, public := newJWK(t, dir)
+
+	cfg := gitsign.Config{TrustedKeys: writeFile(t, dir, "trusted_keys", append(public, '\n'))}
+	signAndVerify(t, cfg, keyPath, gitsign.BlockSignedMessage)
+
+	// A trusted key's attached signature over other content does not vouch for the commit
+	data, _ := os.ReadFile(keyPath)
+	k, _ := jose.ParseJWK(data)
+	sk, _ := k.PrivateKey()
+	der, err := cms.Sign([]byte("other content"), []cms.Signer{{ParameterSet: k.Alg, PrivateKey: sk}}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigPath := writeFile(t, dir, "attached.sig", pem.EncodeToMemory(&pem.Block{Type: gitsign.BlockSignedMessage, Bytes: der}))
+	code, status, _ := run(cfg, commit, "--status-fd=1", "--verify", sigPath, "-")
+
+	if code != 1 || !strings.Contains(status, "\n[GNUPG:] BADSIG ") || strings.Contains(status, "GOODSIG") {
+		t.Errorf("attached signature: verify exited %d: %s", code, status)
+	}
+}
+
+func TestUnknownKey(t *testing.T) {
+	dir := t.TempDir()
+	keyPath, _ := newOpenPGPKey(t, dir, "alice")
+	_, otherPublic := newOpenPGPKey(t, dir, "bob")
+
+	cfg := gitsign.Config{TrustedKeys: writeFile(t, dir, "trusted_keys", otherPublic)}
+
+	_, sig, _ := run(cfg, commit, "-bsau", keyPath)
+	sigPath := writeFile(t, dir, "sig", []byte(sig))
+
+	code, status, _ := run(cfg, commit, "--status-fd=1", "--verify", sigPath, "-")
+
+	if code != 2 || !strings.Contains(status, "\n[GNUPG:] NO_PUBKEY ") || strings.Contains(status, "GOODSIG") {
+		t.Errorf("verify exited %d: %s", code, status)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"--status-fd=2", "-bsau"},
+		{"--status-fd=x", "--verify", "sig", "-"},
+		{"-bsau", "key", "--verify", "sig", "-"},
+		{"--export", "key"},
+	} {
+		if code, _, _ := run(gitsign.Config{}, "", args...); code != 2 {
+			t.Errorf("%q: exited %d", args, code)
+		}
+	}
+
+	dir := t.TempDir()
+	cfg := gitsign.Config{TrustedKeys: writeFile(t, dir, "trusted_keys", []byte("not a key\n"))}
+	sigPath := writeFile(t, dir, "sig", []byte("-----BEGIN PGP SIGNATURE-----\n\n-----END PGP SIGNATURE-----\n"))
+
+	if code, _, stderr := run(cfg, commit, "--verify", sigPath, "-"); code != 2 || !strings.Contains(stderr, "malformed trusted keys") {
+		t.Errorf("malformed trusted keys: exited %d: %s", code, stderr)
+	}
+}
@@ -0,0 +1,70 @@
+package httpsig
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Round tripper signing each request before sending it
+type Transport struct {
+	Signer *Signer
+
+	// Underlying transport, http.DefaultTransport when nil
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	// Round trippers must not modify the caller's request
+	r = r.Clone(r.Context())
+
+	if err := t.Signer.SignRequest(r); err != nil {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+
+		return nil, err
+	}
+
+	return base.RoundTrip(r)
+}
+
+// Client sending requests signed by the signer
+func NewClient(s *Signer) *http.Client {
+	return &http.Client{Transport: &Transport{Signer: s}}
+}
+
+type contextKey struct{}
+
+// Verified signature stored in the request context by the middleware
+func FromContext(ctx context.Context) (*Signature, bool) {
+	sig, ok := ctx.Value(contextKey{}).(*Signature)
+
+	return sig, ok
+}
+
+// Wrap a handler, rejecting requests without a valid signature with 401
+// Unauthorized, or 413 when the body is over MaxBodySize, and passing the
+// verified signature in the request context
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sig, err := v.VerifyRequest(r)
+
+		if errors.Is(err, ErrBodyTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, sig)))
+	})
+}
@@ -0,0 +1,387 @@
+// Package httpsig implements HTTP Message Signatures (RFC 9421) with SLH-DSA
+// keys, including a signing client transport and a verifying server
+// middleware.
+//
+// No HTTP signature algorithm is registered for SLH-DSA, the alg parameter
+// carries the lower-case parameter set name such as "slh-dsa-sha2-128s".
+package httpsig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+var (
+	ErrMalformed            = errors.New("httpsig: malformed signature field")
+	ErrNoSignature          = errors.New("httpsig: message has no signature")
+	ErrUnknownKey           = errors.New("httpsig: unknown key")
+	ErrInvalidSignature     = errors.New("httpsig: invalid signature")
+	ErrExpired              = errors.New("httpsig: signature expired")
+	ErrAlgorithmMismatch    = errors.New("httpsig: algorithm does not match the key")
+	ErrMissingComponent     = errors.New("httpsig: covered component missing from message")
+	ErrUnsupportedComponent = errors.New("httpsig: unsupported component")
+	ErrNotCovered           = errors.New("httpsig: required component not covered")
+	ErrTagMismatch          = errors.New("httpsig: unexpected tag")
+	ErrContentDigest        = errors.New("httpsig: content digest mismatch")
+	ErrBodyTooLarge         = errors.New("httpsig: body exceeds the maximum size")
+)
+
+// Signature parameters (RFC 9421 Section 2.3), zero values are omitted
+type Params struct {
+	Created time.Time
+	Expires time.Time
+	Nonce   string
+	Alg     string
+	KeyID   string
+	Tag     string
+}
+
+// Verified signature
+type Signature struct {
+	Label      string
+	Components []string
+	Params     Params
+}
+
+// Maps the keyid parameter of a signature to a public key
+type KeyResolver interface {
+	ResolveKey(keyID string) (slhdsa.TrustedKey, error)
+}
+
+// Adapter for ordinary functions as key resolvers
+type KeyResolverFunc func(keyID string) (slhdsa.TrustedKey, error)
+
+func (f KeyResolverFunc) ResolveKey(keyID string) (slhdsa.TrustedKey, error) {
+	return f(keyID)
+}
+
+// Static key resolver
+type Keys map[string]slhdsa.TrustedKey
+
+func (k Keys) ResolveKey(keyID string) (slhdsa.TrustedKey, error) {
+	key, ok := k[keyID]
+
+	if !ok {
+		return slhdsa.TrustedKey{}, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// Algorithm name of a parameter set used in the alg parameter
+func Algorithm(paramSet string) string {
+	return strings.ToLower(paramSet)
+}
+
+// Request or response being signed or verified
+type message struct {
+	req  *http.Request
+	resp *http.Response
+}
+
+func (m message) header() http.Header {
+	if m.resp != nil {
+		return m.resp.Header
+	}
+
+	return m.req.Header
+}
+
+func (m message) request() *http.Request {
+	if m.resp != nil {
+		return m.resp.Request
+	}
+
+	return m.req
+}
+
+func scheme(r *http.Request) string {
+	if r.URL.Scheme != "" {
+		return strings.ToLower(r.URL.Scheme)
+	}
+
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// Host and port in lower case without the default port of the scheme
+func authority(r *http.Request) string {
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+
+	host = strings.ToLower(host)
+
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		if (port == "80" && scheme(r) == "http") || (port == "443" && scheme(r) == "https") {
+			if strings.Contains(h, ":") {
+				return "[" + h + "]"
+			}
+
+			return h
+		}
+	}
+
+	return host
+}
+
+func requestTarget(r *http.Request) string {
+	if r.RequestURI != "" {
+		return r.RequestURI
+	}
+
+	return r.URL.RequestURI()
+}
+
+// Percent-encode a query parameter name or value (RFC 9421 Section 2.2.8)
+func queryEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// Component values for one identifier, one per line of the signature base
+func componentValues(m message, id item) ([]string, error) {
+	name, ok := id.value.(string)
+
+	if !ok {
+		return nil, ErrUnsupportedComponent
+	}
+
+	if _, ok := id.param("req"); ok {
+		if m.resp == nil || m.resp.Request == nil {
+			return nil, ErrMissingComponent
+		}
+
+		m = message{req: m.resp.Request}
+	}
+
+	if !strings.HasPrefix(name, "@") {
+		if len(id.params) > 1 || (len(id.params) == 1 && id.params[0].key != "req") {
+			return nil, ErrUnsupportedComponent
+		}
+
+		// Values returns the header's own slice, trim a copy
+		values := slices.Clone(m.header().Values(name))
+
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrMissingComponent, name)
+		}
+
+		for i, v := range values {
+			values[i] = strings.TrimSpace(v)
+		}
+
+		return []string{strings.Join(values, ", ")}, nil
+	}
+
+	if name == "@status" {
+		if m.resp == nil {
+			return nil, fmt.Errorf("%w: %s", ErrMissingComponent, name)
+		}
+
+		return []string{strconv.Itoa(m.resp.StatusCode)}, nil
+	}
+
+	r := m.request()
+
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingComponent, name)
+	}
+
+	switch name {
+	case "@method":
+		return []string{r.Method}, nil
+	case "@target-uri":
+		return []string{scheme(r) + "://" + authority(r) + requestTarget(r)}, nil
+	case "@authority":
+		return []string{authority(r)}, nil
+	case "@scheme":
+		return []string{scheme(r)}, nil
+	case "@request-target":
+		return []string{requestTarget(r)}, nil
+	case "@path":
+		if p := r.URL.EscapedPath(); p != "" {
+			return []string{p}, nil
+		}
+
+		return []string{"/"}, nil
+	case "@query":
+		return []string{"?" + r.URL.RawQuery}, nil
+	case "@query-param":
+		v, _ := id.param("name")
+		param, ok := v.(string)
+
+		if !ok {
+			return nil, ErrUnsupportedComponent
+		}
+
+		query, err := url.ParseQuery(r.URL.RawQuery)
+
+		if err != nil {
+			return nil, err
+		}
+
+		values, ok := query[param]
+
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingComponent, serializeItem(id))
+		}
+
+		out := make([]string, len(values))
+		for i, v := range values {
+			out[i] = queryEscape(v)
+		}
+
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedComponent, name)
+}
+
+// Parse component identifiers given as serialized strings such as
+// "@method", "content-type" or `@query-param;name="id"`
+func parseComponents(components []string) ([]item, error) {
+	items := make([]item, len(components))
+
+	for i, c := range components {
+		if !strings.HasPrefix(c, `"`) {
+			name, params, _ := strings.Cut(c, ";")
+			c = serializeBareItem(strings.ToLower(name))
+
+			if params != "" {
+				c += ";" + params
+			}
+		}
+
+		it, err := parseItem(c)
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedComponent, c)
+		}
+
+		items[i] = it
+	}
+
+	return items, nil
+}
+
+func (p Params) serialize() []param {
+	var params []param
+
+	if !p.Created.IsZero() {
+		params = append(params, param{"created", p.Created.Unix()})
+	}
+
+	if !p.Expires.IsZero() {
+		params = append(params, param{"expires", p.Expires.Unix()})
+	}
+
+	if p.Nonce != "" {
+		params = append(params, param{"nonce", p.Nonce})
+	}
+
+	if p.Alg != "" {
+		params = append(params, param{"alg", p.Alg})
+	}
+
+	if p.KeyID != "" {
+		params = append(params, param{"keyid", p.KeyID})
+	}
+
+	if p.Tag != "" {
+		params = append(params, param{"tag", p.Tag})
+	}
+
+	return params
+}
+
+func parseParams(params []param) (Params, error) {
+	var p Params
+
+	for _, pp := range params {
+		switch v := pp.value.(type) {
+		case int64:
+			switch pp.key {
+			case "created":
+				p.Created = time.Unix(v, 0)
+			case "expires":
+				p.Expires = time.Unix(v, 0)
+			default:
+				return p, ErrMalformed
+			}
+		case string:
+			switch pp.key {
+			case "nonce":
+				p.Nonce = v
+			case "alg":
+				p.Alg = v
+			case "keyid":
+				p.KeyID = v
+			case "tag":
+				p.Tag = v
+			default:
+				return p, ErrMalformed
+			}
+		default:
+			return p, ErrMalformed
+		}
+	}
+
+	return p, nil
+}
+
+// Signature base (RFC 9421 Section 2.5) and the serialized signature parameters
+func signatureBase(m message, components []item, params []param) ([]byte, string, error) {
+	var b strings.Builder
+	seen := map[string]bool{}
+
+	for _, c := range components {
+		id := serializeItem(c)
+
+		if seen[id] {
+			return nil, "", ErrMalformed
+		}
+
+		seen[id] = true
+
+		values, err := componentValues(m, c)
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, v := range values {
+			b.WriteString(id + ": " + v + "\n")
+		}
+	}
+
+	sigParams := serializeInnerList(components, params)
+	b.WriteString(`"@signature-params": ` + sigParams)
+
+	return []byte(b.String()), sigParams, nil
+}
+
+// Signature base of a request for the given components and parameters
+func SignatureBase(r *http.Request, components []string, p Params) ([]byte, error) {
+	items, err := parseComponents(components)
+
+	if err != nil {
+		return nil, err
+	}
+
+	base, _, err := signatureBase(message{req: r}, items, p.serialize())
+
+	return base, err
+}
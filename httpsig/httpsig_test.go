@@ -0,0 +1,296 @@
+package httpsig_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/httpsig"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+)
+
+func newRequest() *http.Request {
+	r, _ := http.NewRequest("POST", "http://example.com/foo?param=Value&Pet=dog", strings.NewReader(`{"hello": "world"}`))
+	r.Header.Set("Date", "Tue, 20 Apr 2021 02:07:55 GMT")
+	r.Header.Set("Content-Type", "application/json")
+
+	return r
+}
+
+func TestSignatureBase(t *testing.T) {
+	// RFC 9421 Appendix B.2.2
+	r := newRequest()
+	r.Header.Set("Content-Digest", "sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:")
+
+	base, err := httpsig.SignatureBase(r, []string{"@authority", "content-digest", `@query-param;name="Pet"`}, httpsig.Params{
+		Created: time.Unix(1618884473, 0),
+		KeyID:   "test-key-rsa-pss",
+		Tag:     "header-example",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `"@authority": example.com
+"content-digest": sha-512=:WZDPaVn/7XgHaAy8pmojAkGWoRx2UFChF41A2svX+TaPm+AbwAgBWnrIiYllu7BNNyealdVLvRwEmTHWXvJwew==:
+"@query-param";name="Pet": dog
+"@signature-params": ("@authority" "content-digest" "@query-param";name="Pet");created=1618884473;keyid="test-key-rsa-pss";tag="header-example"`
+
+	if string(base) != want {
+		t.Errorf("unexpected signature base\n%s", base)
+	}
+}
+
+func newSigner(t *testing.T, keyID string) (*httpsig.Signer, slhdsa.TrustedKey) {
+	paramSet := slhdsa.ParameterSet.SLHDSA_SHA2_128f
+	sk, pk := testkey.New(t, paramSet)
+	s := &httpsig.Signer{KeyID: keyID, ParameterSet: paramSet, PrivateKey: sk}
+
+	return s, slhdsa.TrustedKey{ParameterSet: paramSet, PublicKey: pk}
+}
+
+func TestTransportMiddleware(t *testing.T) {
+	s, key := newSigner(t, "mesh-a")
+	s.Components = []string{"@method", "@target-uri", "content-type", "content-digest"}
+	s.Nonce = true
+
+	v := &httpsig.Verifier{Keys: httpsig.Keys{"mesh-a": key}, Required: []string{"@method", "content-digest"}, MaxAge: time.Minute}
+
+	srv := httptest.NewServer(v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sig, _ := httpsig.FromContext(r.Context())
+		body, _ := io.ReadAll(r.Body)
+		io.WriteString(w, sig.Params.KeyID+" "+string(body))
+	})))
+	defer srv.Close()
+
+	resp, err := httpsig.NewClient(s).Post(srv.URL+"/api?x=1", "application/json", strings.NewReader(`{"a":1}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != `mesh-a {"a":1}` {
+		t.Fatalf("unexpected response %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = http.Post(srv.URL, "text/plain", strings.NewReader("unsigned"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unsigned request got status %d", resp.StatusCode)
+	}
+}
+
+func TestVerifyFailures(t *testing.T) {
+	s, key := newSigner(t, "mesh-a")
+	s.Expires = time.Minute
+	s.Tag = "mesh"
+
+	signed := func() *http.Request {
+		r := newRequest()
+
+		if err := s.SignRequest(r); err != nil {
+			t.Fatal(err)
+		}
+
+		return r
+	}
+
+	v := &httpsig.Verifier{Keys: httpsig.Keys{"mesh-a": key}, Tag: "mesh"}
+
+	sig, err := v.VerifyRequest(signed())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sig.Label != "sig1" || len(sig.Components) != 3 || sig.Params.Alg != "slh-dsa-sha2-128f" {
+		t.Errorf("unexpected signature %+v", sig)
+	}
+
+	r := signed()
+	r.Body = io.NopCloser(strings.NewReader(`{"hello": "mallory"}`))
+
+	if _, err := v.VerifyRequest(r); !errors.Is(err, httpsig.ErrContentDigest) {
+		t.Errorf("expected ErrContentDigest, got %v", err)
+	}
+
+	r = signed()
+	r.Method = "PUT"
+
+	if _, err := v.VerifyRequest(r); !errors.Is(err, httpsig.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	if _, err := v.VerifyRequest(newRequest()); !errors.Is(err, httpsig.ErrNoSignature) {
+		t.Errorf("expected ErrNoSignature, got %v", err)
+	}
+
+	cases := map[error]httpsig.Verifier{
+		httpsig.ErrUnknownKey:  {Keys: httpsig.Keys{}},
+		httpsig.ErrTagMismatch: {Keys: v.Keys, Tag: "other"},
+		httpsig.ErrNotCovered:  {Keys: v.Keys, Required: []string{"content-type"}},
+		httpsig.ErrExpired:     {Keys: v.Keys, Now: func() time.Time { return time.Now().Add(time.Hour) }},
+		httpsig.ErrNoSignature: {Keys: v.Keys, Label: "sig2"},
+	}
+
+	for expected, c := range cases {
+		if _, err := c.VerifyRequest(signed()); !errors.Is(err, expected) {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+	}
+}
+
+func TestHeaderValuesUntouched(t *testing.T) {
+	s, key := newSigner(t, "mesh-a")
+	s.Components = []string{"@method", "@target-uri", "content-digest", "x-padded"}
+
+	r := newRequest()
+	r.Header["X-Padded"] = []string{"  a  ", "b "}
+
+	if err := s.SignRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	v := &httpsig.Verifier{Keys: httpsig.Keys{"mesh-a": key}}
+
+	if _, err := v.VerifyRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	// Trimming for the signature base leaves the message itself alone
+	if got := r.Header["X-Padded"]; len(got) != 2 || got[0] != "  a  " || got[1] != "b " {
+		t.Errorf("header rewritten to %q", got)
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	s, key := newSigner(t, "mesh-a")
+	body := `{"hello": "world"}`
+
+	s.MaxBodySize = 4
+
+	if err := s.SignRequest(newRequest()); !errors.Is(err, httpsig.ErrBodyTooLarge) {
+		t.Errorf("signing: expected ErrBodyTooLarge, got %v", err)
+	}
+
+	s.MaxBodySize = 0
+	r := newRequest()
+
+	if err := s.SignRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	for limit, want := range map[int64]error{4: httpsig.ErrBodyTooLarge, int64(len(body)): nil, -1: nil} {
+		v := &httpsig.Verifier{Keys: httpsig.Keys{"mesh-a": key}, MaxBodySize: limit}
+		r.Body = io.NopCloser(strings.NewReader(body))
+
+		if _, err := v.VerifyRequest(r); !errors.Is(err, want) {
+			t.Errorf("limit %d: expected %v, got %v", limit, want, err)
+		}
+	}
+
+	// The middleware answers oversized bodies before calling the handler
+	v := &httpsig.Verifier{Keys: httpsig.Keys{"mesh-a": key}, MaxBodySize: 4}
+	h := v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called")
+	}))
+
+	w := httptest.NewRecorder()
+	r.Body = io.NopCloser(strings.NewReader(body))
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("middleware status %d", w.Code)
+	}
+}
+
+func TestDefaultRequired(t *testing.T) {
+	s, key := newSigner(t, "mesh-a")
+	v := &httpsig.Verifier{Keys: httpsig.Keys{"mesh-a": key}}
+
+	// A signature over no components is valid for any request
+	base, err := httpsig.SignatureBase(newRequest(), nil, httpsig.Params{KeyID: "mesh-a"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, _ := slhdsa.New(s.ParameterSet)
+	sig, err := ctx.GenerateSignature(s.PrivateKey, base, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := newRequest()
+	r.Header.Set("Signature-Input", `sig1=();keyid="mesh-a"`)
+	r.Header.Set("Signature", "sig1=:"+base64.StdEncoding.EncodeToString(sig)+":")
+
+	if _, err := v.VerifyRequest(r); !errors.Is(err, httpsig.ErrNotCovered) {
+		t.Errorf("empty coverage: expected ErrNotCovered, got %v", err)
+	}
+
+	// Without Required, the method, target URI and body digest must be covered
+	for _, components := range [][]string{{"@method"}, {"@method", "@target-uri"}} {
+		s.Components = components
+		r := newRequest()
+
+		if err := s.SignRequest(r); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := v.VerifyRequest(r); !errors.Is(err, httpsig.ErrNotCovered) {
+			t.Errorf("%v: expected ErrNotCovered, got %v", components, err)
+		}
+	}
+
+	r, _ = http.NewRequest("GET", "http://example.com/foo", nil)
+	s.Components = []string{"@method", "@target-uri"}
+
+	if err := s.SignRequest(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := v.VerifyRequest(r); err != nil {
+		t.Errorf("request without body: %v", err)
+	}
+}
+
+func TestResponse(t *testing.T) {
+	s, key := newSigner(t, "server")
+	s.Components = []string{"@status", "content-type", "@method;req", "@path;req"}
+
+	resp := &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"text/plain"}}, Request: newRequest()}
+
+	if err := s.SignResponse(resp); err != nil {
+		t.Fatal(err)
+	}
+
+	v := &httpsig.Verifier{Keys: httpsig.Keys{"server": key}}
+
+	if _, err := v.VerifyResponse(resp); err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Request.URL.Path = "/bar"
+
+	if _, err := v.VerifyResponse(resp); !errors.Is(err, httpsig.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+package httpsig
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// Minimal Structured Field Values (RFC 8941) codec covering dictionaries of
+// inner lists and items as used by Signature-Input, Signature and
+// Content-Digest. Decimals are not supported.
+
+// Token bare item, serialized without quotes
+type token string
+
+type param struct {
+	key   string
+	value any
+}
+
+// Bare item (int64, string, token, []byte or bool) with parameters
+type item struct {
+	value  any
+	params []param
+}
+
+func (it *item) param(key string) (any, bool) {
+	for _, p := range it.params {
+		if p.key == key {
+			return p.value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Dictionary member, inner is set when the value is an inner list
+type member struct {
+	key     string
+	inner   []item
+	isInner bool
+	item    item
+}
+
+func isLCAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isTokenChar(c byte) bool {
+	return isLCAlpha(c) || c >= 'A' && c <= 'Z' || isDigit(c) || strings.IndexByte("!#$%&'*+-.^_`|~:/", c) >= 0
+}
+
+type sfParser struct {
+	s   string
+	err error
+}
+
+func (p *sfParser) fail() {
+	if p.err == nil {
+		p.err = ErrMalformed
+	}
+}
+
+func (p *sfParser) peek() byte {
+	if len(p.s) == 0 {
+		return 0
+	}
+
+	return p.s[0]
+}
+
+func (p *sfParser) skip(chars string) {
+	for len(p.s) > 0 && strings.IndexByte(chars, p.s[0]) >= 0 {
+		p.s = p.s[1:]
+	}
+}
+
+func (p *sfParser) key() string {
+	if c := p.peek(); !isLCAlpha(c) && c != '*' {
+		p.fail()
+		return ""
+	}
+
+	i := 1
+	for i < len(p.s) && (isLCAlpha(p.s[i]) || isDigit(p.s[i]) || strings.IndexByte("_-.*", p.s[i]) >= 0) {
+		i++
+	}
+
+	k := p.s[:i]
+	p.s = p.s[i:]
+
+	return k
+}
+
+func (p *sfParser) bareItem() any {
+	c := p.peek()
+
+	switch {
+	case c == '-' || isDigit(c):
+		i := 1
+		for i < len(p.s) && isDigit(p.s[i]) {
+			i++
+		}
+
+		n, err := strconv.ParseInt(p.s[:i], 10, 64)
+
+		if err != nil || i > 16 || (i < len(p.s) && p.s[i] == '.') {
+			p.fail()
+			return nil
+		}
+
+		p.s = p.s[i:]
+
+		return n
+
+	case c == '"':
+		var b strings.Builder
+
+		for i := 1; i < len(p.s); i++ {
+			switch ch := p.s[i]; {
+			case ch == '\\' && i+1 < len(p.s) && (p.s[i+1] == '"' || p.s[i+1] == '\\'):
+				i++
+				b.WriteByte(p.s[i])
+			case ch == '"':
+				p.s = p.s[i+1:]
+				return b.String()
+			case ch < 0x20 || ch > 0x7e || ch == '\\':
+				p.fail()
+				return nil
+			default:
+				b.WriteByte(ch)
+			}
+		}
+
+		p.fail()
+
+		return nil
+
+	case c == ':':
+		end := strings.IndexByte(p.s[1:], ':')
+
+		if end < 0 {
+			p.fail()
+			return nil
+		}
+
+		b, err := base64.StdEncoding.DecodeString(p.s[1 : end+1])
+
+		if err != nil {
+			p.fail()
+			return nil
+		}
+
+		p.s = p.s[end+2:]
+
+		return b
+
+	case c == '?':
+		if len(p.s) < 2 || (p.s[1] != '0' && p.s[1] != '1') {
+			p.fail()
+			return nil
+		}
+
+		v := p.s[1] == '1'
+		p.s = p.s[2:]
+
+		return v
+
+	case isLCAlpha(c) || c >= 'A' && c <= 'Z' || c == '*':
+		i := 1
+		for i < len(p.s) && isTokenChar(p.s[i]) {
+			i++
+		}
+
+		t := token(p.s[:i])
+		p.s = p.s[i:]
+
+		return t
+	}
+
+	p.fail()
+
+	return nil
+}
+
+func (p *sfParser) params() []param {
+	var params []param
+
+	for p.err == nil && p.peek() == ';' {
+		p.s = p.s[1:]
+		p.skip(" ")
+
+		k := p.key()
+		var v any = true
+
+		if p.peek() == '=' {
+			p.s = p.s[1:]
+			v = p.bareItem()
+		}
+
+		params = append(params, param{key: k, value: v})
+	}
+
+	return params
+}
+
+func (p *sfParser) item() item {
+	v := p.bareItem()
+
+	return item{value: v, params: p.params()}
+}
+
+func (p *sfParser) innerList() []item {
+	p.s = p.s[1:]
+	items := []item{}
+
+	for p.err == nil {
+		p.skip(" ")
+
+		if p.peek() == ')' {
+			p.s = p.s[1:]
+			return items
+		}
+
+		items = append(items, p.item())
+
+		if c := p.peek(); c != ' ' && c != ')' {
+			p.fail()
+		}
+	}
+
+	return nil
+}
+
+// Parse a dictionary field value
+func parseDictionary(s string) ([]member, error) {
+	p := &sfParser{s: strings.Trim(s, " \t")}
+
+	var members []member
+
+	for p.err == nil && len(p.s) > 0 {
+		m := member{key: p.key()}
+
+		switch {
+		case p.peek() != '=':
+			m.item = item{value: true, params: p.params()}
+		case len(p.s) > 1 && p.s[1] == '(':
+			p.s = p.s[1:]
+			m.isInner = true
+			m.inner = p.innerList()
+			m.item.params = p.params()
+		default:
+			p.s = p.s[1:]
+			m.item = p.item()
+		}
+
+		members = append(members, m)
+		p.skip(" \t")
+
+		if len(p.s) > 0 {
+			if p.peek() != ',' {
+				p.fail()
+			}
+
+			p.s = p.s[1:]
+			p.skip(" \t")
+
+			if len(p.s) == 0 {
+				p.fail()
+			}
+		}
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return members, nil
+}
+
+// Parse a single item such as a component identifier
+func parseItem(s string) (item, error) {
+	p := &sfParser{s: s}
+	it := p.item()
+
+	if p.err == nil && len(p.s) != 0 {
+		p.fail()
+	}
+
+	return it, p.err
+}
+
+func serializeBareItem(v any) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case string:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+	case token:
+		return string(v)
+	case []byte:
+		return ":" + base64.StdEncoding.EncodeToString(v) + ":"
+	case bool:
+		if v {
+			return "?1"
+		}
+
+		return "?0"
+	}
+
+	return ""
+}
+
+func serializeParams(params []param) string {
+	var b strings.Builder
+
+	for _, p := range params {
+		b.WriteString(";" + p.key)
+
+		if v, ok := p.value.(bool); !ok || !v {
+			b.WriteString("=" + serializeBareItem(p.value))
+		}
+	}
+
+	return b.String()
+}
+
+func serializeItem(it item) string {
+	return serializeBareItem(it.value) + serializeParams(it.params)
+}
+
+func serializeInnerList(items []item, params []param) string {
+	s := make([]string, len(items))
+	for i, it := range items {
+		s[i] = serializeItem(it)
+	}
+
+	return "(" + strings.Join(s, " ") + ")" + serializeParams(params)
+}
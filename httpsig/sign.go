@@ -0,0 +1,455 @@
+package httpsig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Components covered when a signer lists none
+var DefaultComponents = []string{"@method", "@target-uri", "content-digest"}
+
+// Largest body read for a content digest unless configured otherwise
+const DefaultMaxBodySize = 10 << 20
+
+// Signs messages with an SLH-DSA private key
+type Signer struct {
+	KeyID        string
+	ParameterSet string
+	PrivateKey   slhdsa.PrivateKey
+
+	// Signature label, "sig1" when empty
+	Label string
+
+	// Covered component identifiers, DefaultComponents when empty. A
+	// covered content-digest field is computed from the body when missing.
+	Components []string
+
+	Tag string
+
+	// Signature lifetime recorded in the expires parameter, none when zero
+	Expires time.Duration
+
+	// Add a random nonce to each signature
+	Nonce bool
+
+	// Largest body read to compute a content digest, DefaultMaxBodySize
+	// when zero and unlimited when negative
+	MaxBodySize int64
+
+	// Clock, time.Now when nil
+	Now func() time.Time
+}
+
+func (s *Signer) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+
+	return time.Now()
+}
+
+func (s *Signer) sign(m message, body *io.ReadCloser) error {
+	components := s.Components
+	if len(components) == 0 {
+		components = DefaultComponents
+	}
+
+	items, err := parseComponents(components)
+
+	if err != nil {
+		return err
+	}
+
+	for _, it := range items {
+		if _, req := it.param("req"); it.value == "content-digest" && !req && m.header().Get("Content-Digest") == "" {
+			if err := setContentDigest(m.header(), body, s.MaxBodySize); err != nil {
+				return err
+			}
+		}
+	}
+
+	p := Params{Created: s.now(), Alg: Algorithm(s.ParameterSet), KeyID: s.KeyID, Tag: s.Tag}
+
+	if s.Expires > 0 {
+		p.Expires = p.Created.Add(s.Expires)
+	}
+
+	if s.Nonce {
+		var nonce [16]byte
+		rand.Read(nonce[:])
+		p.Nonce = base64.RawURLEncoding.EncodeToString(nonce[:])
+	}
+
+	base, sigParams, err := signatureBase(m, items, p.serialize())
+
+	if err != nil {
+		return err
+	}
+
+	ctx, err := slhdsa.New(s.ParameterSet)
+
+	if err != nil {
+		return err
+	}
+
+	sig, err := ctx.GenerateSignature(s.PrivateKey, base, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return err
+	}
+
+	label := s.Label
+	if label == "" {
+		label = "sig1"
+	}
+
+	m.header().Add("Signature-Input", label+"="+sigParams)
+	m.header().Add("Signature", label+"="+serializeBareItem(sig))
+
+	return nil
+}
+
+// Sign a request, adding Signature-Input and Signature fields
+func (s *Signer) SignRequest(r *http.Request) error {
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+
+	return s.sign(message{req: r}, &r.Body)
+}
+
+// Sign a response, components with the req parameter refer to resp.Request
+func (s *Signer) SignResponse(resp *http.Response) error {
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+
+	return s.sign(message{resp: resp}, &resp.Body)
+}
+
+// Read at most limit bytes of the body, replaced with an in-memory copy
+func readBody(body *io.ReadCloser, limit int64) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	if limit == 0 {
+		limit = DefaultMaxBodySize
+	}
+
+	var r io.Reader = *body
+	if limit > 0 {
+		r = io.LimitReader(r, limit+1)
+	}
+
+	data, err := io.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && int64(len(data)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+
+	(*body).Close()
+	*body = io.NopCloser(bytes.NewReader(data))
+
+	return data, nil
+}
+
+// Read the body and set Content-Digest (RFC 9530) to its SHA-256 digest,
+// the body is replaced with an in-memory copy
+func setContentDigest(h http.Header, body *io.ReadCloser, limit int64) error {
+	data, err := readBody(body, limit)
+
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(data)
+	h.Set("Content-Digest", "sha-256="+serializeBareItem(digest[:]))
+
+	return nil
+}
+
+// Check Content-Digest against the body, the body is replaced with an
+// in-memory copy
+func checkContentDigest(h http.Header, body *io.ReadCloser, limit int64) error {
+	members, err := parseDictionary(strings.Join(h.Values("Content-Digest"), ", "))
+
+	if err != nil {
+		return err
+	}
+
+	data, err := readBody(body, limit)
+
+	if err != nil {
+		return err
+	}
+
+	checked := false
+
+	for _, m := range members {
+		want, ok := m.item.value.([]byte)
+
+		if !ok || m.isInner {
+			return ErrMalformed
+		}
+
+		var got []byte
+
+		switch m.key {
+		case "sha-256":
+			d := sha256.Sum256(data)
+			got = d[:]
+		case "sha-512":
+			d := sha512.Sum512(data)
+			got = d[:]
+		default:
+			continue
+		}
+
+		if subtle.ConstantTimeCompare(got, want) != 1 {
+			return ErrContentDigest
+		}
+
+		checked = true
+	}
+
+	if !checked {
+		return ErrContentDigest
+	}
+
+	return nil
+}
+
+// Verifies message signatures against keys from a resolver
+type Verifier struct {
+	Keys KeyResolver
+
+	// Only verify the signature with this label, any signature when empty
+	Label string
+
+	// Components every accepted signature must cover. When empty, requests
+	// must cover @method and @target-uri, responses @status, and messages
+	// with a body content-digest.
+	Required []string
+
+	// Required tag parameter, not checked when empty
+	Tag string
+
+	// Reject signatures created longer ago, not checked when zero
+	MaxAge time.Duration
+
+	// Largest body read to check a content digest, DefaultMaxBodySize when
+	// zero and unlimited when negative
+	MaxBodySize int64
+
+	// Clock, time.Now when nil
+	Now func() time.Time
+}
+
+func (v *Verifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+
+	return time.Now()
+}
+
+// Components a signature must cover, defaulting to those identifying the message
+func (v *Verifier) required(m message, body *io.ReadCloser) []string {
+	if len(v.Required) > 0 {
+		return v.Required
+	}
+
+	var required []string
+	var length int64
+
+	if m.resp != nil {
+		required, length = []string{"@status"}, m.resp.ContentLength
+	} else {
+		required, length = []string{"@method", "@target-uri"}, m.req.ContentLength
+	}
+
+	if *body != nil && *body != http.NoBody && length != 0 {
+		required = append(required, "content-digest")
+	}
+
+	return required
+}
+
+func (v *Verifier) verifyOne(m message, body *io.ReadCloser, label string, input member, sig []byte) (*Signature, error) {
+	if !input.isInner {
+		return nil, ErrMalformed
+	}
+
+	p, err := parseParams(input.item.params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Tag != "" && p.Tag != v.Tag {
+		return nil, ErrTagMismatch
+	}
+
+	now := v.now()
+
+	if !p.Expires.IsZero() && now.After(p.Expires) {
+		return nil, ErrExpired
+	}
+
+	if v.MaxAge > 0 && (p.Created.IsZero() || now.Sub(p.Created) > v.MaxAge) {
+		return nil, ErrExpired
+	}
+
+	covered := make([]string, len(input.inner))
+	for i, c := range input.inner {
+		covered[i] = serializeItem(c)
+	}
+
+	// A signature over no components would authenticate any message
+	if len(input.inner) == 0 {
+		return nil, fmt.Errorf("%w: no components", ErrNotCovered)
+	}
+
+	required, err := parseComponents(v.required(m, body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range required {
+		if !slices.Contains(covered, serializeItem(r)) {
+			return nil, fmt.Errorf("%w: %s", ErrNotCovered, serializeItem(r))
+		}
+	}
+
+	key, err := v.Keys.ResolveKey(p.KeyID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Alg != "" && p.Alg != Algorithm(key.ParameterSet) {
+		return nil, ErrAlgorithmMismatch
+	}
+
+	base, _, err := signatureBase(m, input.inner, input.item.params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, err := slhdsa.New(key.ParameterSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := ctx.VerifySignature(key.PublicKey, base, sig, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil || !ok {
+		return nil, ErrInvalidSignature
+	}
+
+	// The signature only covers the digest field, check it against the body
+	if slices.Contains(covered, `"content-digest"`) {
+		if err := checkContentDigest(m.header(), body, v.MaxBodySize); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, len(input.inner))
+	for i, c := range input.inner {
+		names[i] = c.value.(string)
+		if len(c.params) > 0 {
+			names[i] += serializeParams(c.params)
+		}
+	}
+
+	return &Signature{Label: label, Components: names, Params: p}, nil
+}
+
+func (v *Verifier) verify(m message, body *io.ReadCloser) (*Signature, error) {
+	h := m.header()
+	inputs, err := parseDictionary(strings.Join(h.Values("Signature-Input"), ", "))
+
+	if err != nil {
+		return nil, err
+	}
+
+	sigs, err := parseDictionary(strings.Join(h.Values("Signature"), ", "))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var firstErr error
+
+	for _, input := range inputs {
+		if v.Label != "" && input.key != v.Label {
+			continue
+		}
+
+		var sig []byte
+
+		for _, s := range sigs {
+			if b, ok := s.item.value.([]byte); s.key == input.key && ok && !s.isInner {
+				sig = b
+			}
+		}
+
+		if sig == nil {
+			if firstErr == nil {
+				firstErr = ErrMalformed
+			}
+
+			continue
+		}
+
+		result, err := v.verifyOne(m, body, input.key, input, sig)
+
+		if err == nil {
+			return result, nil
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if firstErr == nil {
+		return nil, ErrNoSignature
+	}
+
+	return nil, firstErr
+}
+
+// Verify a signature on the request, returns the first signature accepted
+//
+// When content-digest is covered the body is read and checked, the request
+// body is replaced with an in-memory copy.
+func (v *Verifier) VerifyRequest(r *http.Request) (*Signature, error) {
+	return v.verify(message{req: r}, &r.Body)
+}
+
+// Verify a signature on the response, components with the req parameter
+// refer to resp.Request
+func (v *Verifier) VerifyResponse(resp *http.Response) (*Signature, error) {
+	return v.verify(message{resp: resp}, &resp.Body)
+}
@@ -0,0 +1,69 @@
+// Package merkle holds the RFC 9162 Merkle tree hashing and proof
+// verification shared by the batch and tlog packages.
+package merkle
+
+import (
+	"crypto/sha256"
+	"math/bits"
+)
+
+// SHA-256 tree hash
+type Hash [sha256.Size]byte
+
+// Hash of a leaf (RFC 9162 Section 2.1.1)
+func LeafHash(data []byte) Hash {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(data)
+
+	return Hash(h.Sum(nil))
+}
+
+// Hash of an interior node (RFC 9162 Section 2.1.1)
+func NodeHash(left, right Hash) Hash {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left[:])
+	h.Write(right[:])
+
+	return Hash(h.Sum(nil))
+}
+
+// Largest power of two smaller than n, for n > 1
+func Split(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
+
+// Root implied by a leaf hash and its inclusion proof (RFC 9162 Section 2.1.3.2)
+//
+// Reports false when the proof length does not fit the index and size.
+func RootFromInclusionProof(leaf Hash, index, size uint64, proof []Hash) (Hash, bool) {
+	if index >= size {
+		return Hash{}, false
+	}
+
+	fn, sn := index, size-1
+	r := leaf
+
+	for _, p := range proof {
+		if sn == 0 {
+			return Hash{}, false
+		}
+
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return r, sn == 0
+}
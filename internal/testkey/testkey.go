@@ -0,0 +1,25 @@
+// Package testkey generates SLH-DSA key pairs for tests.
+package testkey
+
+import (
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Generate a key pair, failing the test on error
+func New(t testing.TB, paramSet string) (slhdsa.PrivateKey, slhdsa.PublicKey) {
+	ctx, err := slhdsa.New(paramSet)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sk, pk, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sk, pk
+}
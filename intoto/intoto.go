@@ -0,0 +1,116 @@
+// Package intoto builds in-toto Statement v1 attestations and SLSA provenance
+// predicates and signs them in DSSE envelopes with SLH-DSA keys.
+package intoto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/skuuzie/go-slhdsa/dsse"
+)
+
+const (
+	// DSSE payload type of in-toto statements
+	PayloadType = "application/vnd.in-toto+json"
+
+	StatementType = "https://in-toto.io/Statement/v1"
+)
+
+var (
+	ErrWrongPayloadType = errors.New("intoto: envelope does not hold an in-toto statement")
+	ErrMalformed        = errors.New("intoto: malformed statement")
+	ErrWrongPredicate   = errors.New("intoto: unexpected predicate type")
+)
+
+// Artifact reference (in-toto ResourceDescriptor v1)
+type ResourceDescriptor struct {
+	Name             string            `json:"name,omitempty"`
+	URI              string            `json:"uri,omitempty"`
+	Digest           map[string]string `json:"digest,omitempty"`
+	Content          []byte            `json:"content,omitempty"`
+	DownloadLocation string            `json:"downloadLocation,omitempty"`
+	MediaType        string            `json:"mediaType,omitempty"`
+	Annotations      map[string]any    `json:"annotations,omitempty"`
+}
+
+// Resource descriptor of named content with its SHA-256 digest
+func Subject(name string, data []byte) ResourceDescriptor {
+	h := sha256.Sum256(data)
+
+	return ResourceDescriptor{Name: name, Digest: map[string]string{"sha256": hex.EncodeToString(h[:])}}
+}
+
+// in-toto Statement v1
+type Statement struct {
+	Type          string               `json:"_type"`
+	Subject       []ResourceDescriptor `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     json.RawMessage      `json:"predicate,omitempty"`
+}
+
+// Build a statement about the subjects, the predicate is JSON encoded
+func NewStatement(subjects []ResourceDescriptor, predicateType string, predicate any) (*Statement, error) {
+	if len(subjects) == 0 || predicateType == "" {
+		return nil, ErrMalformed
+	}
+
+	raw, err := json.Marshal(predicate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Statement{Type: StatementType, Subject: subjects, PredicateType: predicateType, Predicate: raw}, nil
+}
+
+// Decode the predicate into v
+func (s *Statement) DecodePredicate(v any) error {
+	return json.Unmarshal(s.Predicate, v)
+}
+
+// Whether a subject of the given name has the SHA-256 digest of data
+func (s *Statement) HasSubject(name string, data []byte) bool {
+	want := Subject(name, data).Digest["sha256"]
+
+	for _, sub := range s.Subject {
+		if sub.Name == name && sub.Digest["sha256"] == want {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign the statement into a DSSE envelope
+func Sign(s *Statement, signers ...dsse.Signer) (*dsse.Envelope, error) {
+	payload, err := json.Marshal(s)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dsse.Sign(PayloadType, payload, signers...)
+}
+
+// Verify the envelope and decode the statement it carries
+func Verify(e *dsse.Envelope, verifiers ...dsse.Verifier) (*Statement, []string, error) {
+	if e.PayloadType != PayloadType {
+		return nil, nil, ErrWrongPayloadType
+	}
+
+	keyIDs, err := dsse.Verify(e, verifiers...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var s Statement
+
+	if err := json.Unmarshal(e.Payload, &s); err != nil || s.Type != StatementType || len(s.Subject) == 0 {
+		return nil, nil, ErrMalformed
+	}
+
+	return &s, keyIDs, nil
+}
@@ -0,0 +1,75 @@
+package intoto_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/dsse"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+	"github.com/skuuzie/go-slhdsa/intoto"
+)
+
+func TestProvenance(t *testing.T) {
+	paramSet := slhdsa.ParameterSet.SLHDSA_SHA2_128f
+	sk, pk := testkey.New(t, paramSet)
+	signer, _ := dsse.NewSigner(paramSet, sk, "")
+	verifier, _ := dsse.NewVerifier(paramSet, pk, "")
+
+	artifact := []byte("binary contents")
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	s, err := intoto.NewProvenanceStatement(
+		[]intoto.ResourceDescriptor{intoto.Subject("app-linux-amd64", artifact)},
+		&intoto.Provenance{
+			BuildDefinition: intoto.BuildDefinition{
+				BuildType:          "https://example.com/build/v1",
+				ExternalParameters: map[string]string{"ref": "refs/tags/v1.0.0"},
+			},
+			RunDetails: intoto.RunDetails{
+				Builder:  intoto.Builder{ID: "https://example.com/builder"},
+				Metadata: &intoto.BuildMetadata{InvocationID: "42", StartedOn: &started},
+			},
+		})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := intoto.Sign(s, signer)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.PayloadType != intoto.PayloadType {
+		t.Errorf("unexpected payload type %q", e.PayloadType)
+	}
+
+	got, keyIDs, err := intoto.Verify(e, verifier)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keyIDs) != 1 || !got.HasSubject("app-linux-amd64", artifact) || got.HasSubject("app-linux-amd64", []byte("other")) {
+		t.Errorf("unexpected statement %+v", got)
+	}
+
+	p, err := got.Provenance()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.RunDetails.Builder.ID != "https://example.com/builder" || !p.RunDetails.Metadata.StartedOn.Equal(started) {
+		t.Errorf("unexpected provenance %+v", p)
+	}
+
+	e.PayloadType = "application/json"
+
+	if _, _, err := intoto.Verify(e, verifier); !errors.Is(err, intoto.ErrWrongPayloadType) {
+		t.Errorf("expected ErrWrongPayloadType, got %v", err)
+	}
+}
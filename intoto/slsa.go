@@ -0,0 +1,57 @@
+package intoto
+
+import "time"
+
+// Predicate type of SLSA provenance v1
+const ProvenancePredicateType = "https://slsa.dev/provenance/v1"
+
+// SLSA provenance v1 predicate
+type Provenance struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   any                  `json:"externalParameters"`
+	InternalParameters   any                  `json:"internalParameters,omitempty"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+type RunDetails struct {
+	Builder    Builder              `json:"builder"`
+	Metadata   *BuildMetadata       `json:"metadata,omitempty"`
+	Byproducts []ResourceDescriptor `json:"byproducts,omitempty"`
+}
+
+type Builder struct {
+	ID                  string               `json:"id"`
+	Version             map[string]string    `json:"version,omitempty"`
+	BuilderDependencies []ResourceDescriptor `json:"builderDependencies,omitempty"`
+}
+
+type BuildMetadata struct {
+	InvocationID string     `json:"invocationId,omitempty"`
+	StartedOn    *time.Time `json:"startedOn,omitempty"`
+	FinishedOn   *time.Time `json:"finishedOn,omitempty"`
+}
+
+// Statement carrying SLSA provenance for the subjects
+func NewProvenanceStatement(subjects []ResourceDescriptor, p *Provenance) (*Statement, error) {
+	return NewStatement(subjects, ProvenancePredicateType, p)
+}
+
+// Decode the SLSA provenance predicate of a statement
+func (s *Statement) Provenance() (*Provenance, error) {
+	if s.PredicateType != ProvenancePredicateType {
+		return nil, ErrWrongPredicate
+	}
+
+	var p Provenance
+
+	if err := s.DecodePredicate(&p); err != nil {
+		return nil, ErrMalformed
+	}
+
+	return &p, nil
+}
@@ -0,0 +1,305 @@
+// Package jcs implements the JSON Canonicalization Scheme (RFC 8785).
+package jcs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	ErrDuplicateKey = errors.New("jcs: duplicate object key")
+	ErrInvalidUTF8  = errors.New("jcs: invalid UTF-8")
+	ErrSurrogate    = errors.New("jcs: unpaired surrogate escape")
+	ErrNumber       = errors.New("jcs: number out of range")
+	ErrTrailingData = errors.New("jcs: trailing data after JSON value")
+)
+
+// Canonical form of a JSON text
+func Transform(data []byte) ([]byte, error) {
+	if !utf8.Valid(data) {
+		return nil, ErrInvalidUTF8
+	}
+
+	if err := checkSurrogates(data); err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var b bytes.Buffer
+
+	if err := value(d, &b); err != nil {
+		return nil, err
+	}
+
+	if _, err := d.Token(); err != io.EOF {
+		return nil, ErrTrailingData
+	}
+
+	return b.Bytes(), nil
+}
+
+// Reject \u escapes of lone surrogates, which encoding/json decodes to
+// U+FFFD so that distinct inputs would share one canonical form
+func checkSurrogates(data []byte) error {
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			inString = !inString
+		case c == '\\' && inString:
+			i++
+
+			if i == len(data) || data[i] != 'u' {
+				continue
+			}
+
+			r := escapedRune(data[i+1:])
+			i += 4
+
+			if utf16.IsSurrogate(r) {
+				// A high surrogate must be followed by an escaped low one
+				if r >= 0xdc00 || i+2 >= len(data) || data[i+1] != '\\' || data[i+2] != 'u' {
+					return ErrSurrogate
+				}
+
+				if low := escapedRune(data[i+3:]); low < 0xdc00 || low > 0xdfff {
+					return ErrSurrogate
+				}
+
+				i += 6
+			}
+		}
+	}
+
+	return nil
+}
+
+// Code unit of the four hex digits of a \u escape, -1 when malformed
+func escapedRune(hex []byte) rune {
+	if len(hex) < 4 {
+		return -1
+	}
+
+	n, err := strconv.ParseUint(string(hex[:4]), 16, 16)
+
+	if err != nil {
+		return -1
+	}
+
+	return rune(n)
+}
+
+// Canonical JSON encoding of a value, encoded with encoding/json first
+func Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Transform(data)
+}
+
+func value(d *json.Decoder, b *bytes.Buffer) error {
+	tok, err := d.Token()
+
+	if err != nil {
+		return err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		if tok == '[' {
+			return array(d, b)
+		}
+
+		return object(d, b)
+	case string:
+		writeString(b, tok)
+	case json.Number:
+		f, err := strconv.ParseFloat(string(tok), 64)
+
+		if err != nil {
+			return ErrNumber
+		}
+
+		s, err := FormatNumber(f)
+
+		if err != nil {
+			return err
+		}
+
+		b.WriteString(s)
+	case bool:
+		b.WriteString(strconv.FormatBool(tok))
+	case nil:
+		b.WriteString("null")
+	}
+
+	return nil
+}
+
+func array(d *json.Decoder, b *bytes.Buffer) error {
+	b.WriteByte('[')
+
+	for i := 0; d.More(); i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		if err := value(d, b); err != nil {
+			return err
+		}
+	}
+
+	b.WriteByte(']')
+	_, err := d.Token()
+
+	return err
+}
+
+type property struct {
+	key   string
+	utf16 []uint16
+	value []byte
+}
+
+// Object members sorted by the UTF-16 code units of their keys
+func object(d *json.Decoder, b *bytes.Buffer) error {
+	var props []property
+
+	for d.More() {
+		tok, err := d.Token()
+
+		if err != nil {
+			return err
+		}
+
+		key := tok.(string)
+
+		if slices.ContainsFunc(props, func(p property) bool { return p.key == key }) {
+			return ErrDuplicateKey
+		}
+
+		var v bytes.Buffer
+
+		if err := value(d, &v); err != nil {
+			return err
+		}
+
+		props = append(props, property{key: key, utf16: utf16.Encode([]rune(key)), value: v.Bytes()})
+	}
+
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+
+	slices.SortFunc(props, func(a, b property) int {
+		return slices.Compare(a.utf16, b.utf16)
+	})
+
+	b.WriteByte('{')
+
+	for i, p := range props {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		writeString(b, p.key)
+		b.WriteByte(':')
+		b.Write(p.value)
+	}
+
+	b.WriteByte('}')
+
+	return nil
+}
+
+// String with only the escapes required by RFC 8785 Section 3.2.2.2
+func writeString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				b.WriteString(`\u00`)
+				b.WriteString(strconv.FormatInt(int64(r)>>4, 16))
+				b.WriteString(strconv.FormatInt(int64(r)&0xf, 16))
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	b.WriteByte('"')
+}
+
+// Serialize a number like ECMAScript Number.prototype.toString
+func FormatNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", ErrNumber
+	}
+
+	if f == 0 {
+		return "0", nil
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// Shortest round-trip digits and decimal exponent
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	n := e + 1
+	k := len(digits)
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k), nil
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:], nil
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits, nil
+	}
+
+	out := digits[:1]
+	if k > 1 {
+		out += "." + digits[1:]
+	}
+
+	if n-1 >= 0 {
+		return sign + out + "e+" + strconv.Itoa(n-1), nil
+	}
+
+	return sign + out + "e" + strconv.Itoa(n-1), nil
+}
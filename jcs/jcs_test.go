@@ -0,0 +1,70 @@
+package jcs_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/skuuzie/go-slhdsa/jcs"
+)
+
+func TestTransform(t *testing.T) {
+	cases := map[string]string{
+		// RFC 8785 Section 3.2.2
+		`{
+		  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		  "literals": [null, true, false]
+		}`: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+
+		// RFC 8785 Section 3.2.3
+		`{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh",
+		  "1": "One", "\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control", "\u00f6": "Latin Small Letter O With Diaeresis"}`: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+
+		`[{"b": [], "a": {}}, "<&>"]`: `[{"a":{},"b":[]},"<&>"]`,
+		`["\\ud800", "\ud83d\ude00"]`: "[\"\\\\ud800\",\"\U0001f600\"]",
+	}
+
+	for in, want := range cases {
+		got, err := jcs.Transform([]byte(in))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != want {
+			t.Errorf("got %s\nwant %s", got, want)
+		}
+	}
+
+	for _, in := range []string{`{"a":1,"a":2}`, `{"a":1} {}`, "\"\xff\"", `1e400`, `"\ud800"`, `"\udc00x"`, `{"\ud83d\u0041": 1}`, `["\\\ud800"]`} {
+		if _, err := jcs.Transform([]byte(in)); err == nil {
+			t.Errorf("accepted %q", in)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	// Samples from the RFC 8785 Appendix B table
+	cases := map[float64]string{
+		math.Float64frombits(0x8000000000000000): "0",
+		math.Float64frombits(0x0000000000000001): "5e-324",
+		math.Float64frombits(0x7fefffffffffffff): "1.7976931348623157e+308",
+		math.Float64frombits(0x4340000000000000): "9007199254740992",
+		math.Float64frombits(0x444b1ae4d6e2ef50): "1e+21",
+		math.Float64frombits(0x3eb0c6f7a0b5ed8d): "0.000001",
+		math.Float64frombits(0x3eb0c6f7a0b5ed8c): "9.999999999999997e-7",
+		-1.5:                                     "-1.5",
+	}
+
+	for f, want := range cases {
+		got, err := jcs.FormatNumber(f)
+
+		if err != nil || got != want {
+			t.Errorf("%v: got %q, want %q", f, got, want)
+		}
+	}
+
+	if _, err := jcs.FormatNumber(math.NaN()); err == nil {
+		t.Error("formatted NaN")
+	}
+}
@@ -0,0 +1,149 @@
+package jose_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+	"github.com/skuuzie/go-slhdsa/jose"
+)
+
+func newKey(t *testing.T, paramSet, kid string) *jose.JWK {
+	sk, _ := testkey.New(t, paramSet)
+
+	k, err := jose.NewPrivateJWK(paramSet, sk)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k.Kid = kid
+
+	return k
+}
+
+func TestJWKRoundTrip(t *testing.T) {
+	k := newKey(t, slhdsa.ParameterSet.SLHDSA_SHAKE_128f, "k1")
+
+	encoded, _ := json.Marshal(k)
+	parsed, err := jose.ParseJWK(encoded)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Kty != "AKP" || parsed.Alg != "SLH-DSA-SHAKE-128f" {
+		t.Errorf("unexpected key %+v", parsed)
+	}
+
+	if parsed.Thumbprint() != k.Public().Thumbprint() {
+		t.Error("thumbprint depends on the private part")
+	}
+
+	if _, err := k.Public().PrivateKey(); err != jose.ErrNoPrivateKey {
+		t.Errorf("expected ErrNoPrivateKey, got %v", err)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	k := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "k1")
+	other := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "k2")
+
+	token, err := jose.SignCompact([]byte("hello"), k, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, h, err := jose.VerifyCompact(token, []*jose.JWK{other.Public(), k.Public()})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(payload) != "hello" || h.Algorithm() != "SLH-DSA-SHA2-128f" || h.KeyID() != "k1" {
+		t.Errorf("unexpected result %q %v", payload, h)
+	}
+
+	if _, _, err := jose.VerifyCompact(token, []*jose.JWK{other.Public()}); err == nil {
+		t.Error("verified with an untrusted key")
+	}
+
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + ".aGVsbG8h." + parts[2]
+	if _, _, err := jose.VerifyCompact(tampered, []*jose.JWK{k.Public()}); err != jose.ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestJSONSerialization(t *testing.T) {
+	k1 := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "k1")
+	k2 := newKey(t, slhdsa.ParameterSet.SLHDSA_SHAKE_128f, "k2")
+
+	data, err := jose.SignJSON([]byte("payload"), []*jose.JWK{k1, k2}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, headers, err := jose.VerifyJSON(data, []*jose.JWK{k2.Public()})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(payload) != "payload" || len(headers) != 1 || headers[0].KeyID() != "k2" {
+		t.Errorf("unexpected result %q %v", payload, headers)
+	}
+}
+
+func TestJWT(t *testing.T) {
+	k := newKey(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f, "k1")
+	now := time.Unix(1700000000, 0)
+
+	type customClaims struct {
+		jose.Claims
+		Scope string `json:"scope"`
+	}
+
+	token, err := jose.SignJWT(customClaims{
+		Claims: jose.Claims{
+			Issuer:   "issuer",
+			Audience: jose.Audience{"api"},
+			Expiry:   jose.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt: jose.NewNumericDate(now),
+		},
+		Scope: "read",
+	}, k)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var claims customClaims
+	h, err := jose.ParseJWT(token, []*jose.JWK{k.Public()}, &claims, jose.Validation{Issuer: "issuer", Audience: "api", Now: now})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Scope != "read" || h["typ"] != "JWT" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	cases := map[error]jose.Validation{
+		jose.ErrTokenExpired:    {Now: now.Add(2 * time.Hour)},
+		jose.ErrInvalidIssuer:   {Issuer: "other", Now: now},
+		jose.ErrInvalidAudience: {Audience: "other", Now: now},
+		jose.ErrIssuedInFuture:  {Now: now.Add(-time.Minute)},
+	}
+
+	for expected, v := range cases {
+		if _, err := jose.ParseJWT(token, []*jose.JWK{k.Public()}, &claims, v); err != expected {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Package jose implements JWS, JWK and JWT with SLH-DSA algorithm identifiers
+// (draft-ietf-cose-sphincs-plus).
+package jose
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Key type of algorithm key pairs
+const KeyTypeAKP = "AKP"
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("jose: unsupported algorithm")
+	ErrInvalidKey           = errors.New("jose: invalid key")
+	ErrNoPrivateKey         = errors.New("jose: key has no private part")
+)
+
+// JOSE algorithm name of an SLH-DSA parameter set
+//
+// The registered names are the parameter set names themselves.
+func Algorithm(paramSet string) (string, error) {
+	if _, err := slhdsa.New(paramSet); err != nil {
+		return "", ErrUnsupportedAlgorithm
+	}
+
+	return paramSet, nil
+}
+
+// JSON Web Key of an SLH-DSA key pair
+type JWK struct {
+	Kty  string `json:"kty"`
+	Alg  string `json:"alg"`
+	Kid  string `json:"kid,omitempty"`
+	Use  string `json:"use,omitempty"`
+	Pub  string `json:"pub"`
+	Priv string `json:"priv,omitempty"`
+}
+
+// Build a public JWK
+func NewPublicJWK(paramSet string, pk slhdsa.PublicKey) (*JWK, error) {
+	alg, err := Algorithm(paramSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWK{
+		Kty: KeyTypeAKP,
+		Alg: alg,
+		Pub: base64.RawURLEncoding.EncodeToString(pk.KeyBytes),
+	}, nil
+}
+
+// Build a private JWK, the public part is derived from the private key
+func NewPrivateJWK(paramSet string, sk slhdsa.PrivateKey) (*JWK, error) {
+	pk, err := slhdsa.PublicKeyFromPrivateKey(paramSet, sk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := NewPublicJWK(paramSet, pk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	k.Priv = base64.RawURLEncoding.EncodeToString(sk.KeyBytes)
+
+	return k, nil
+}
+
+// Parse a JSON encoded JWK
+func ParseJWK(data []byte) (*JWK, error) {
+	var k JWK
+
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+
+	if k.Kty != KeyTypeAKP {
+		return nil, ErrInvalidKey
+	}
+
+	if _, err := k.PublicKey(); err != nil {
+		return nil, err
+	}
+
+	if k.Priv != "" {
+		if _, err := k.PrivateKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &k, nil
+}
+
+// Decoded SLH-DSA public key
+func (k *JWK) PublicKey() (slhdsa.PublicKey, error) {
+	ctx, err := slhdsa.New(k.Alg)
+
+	if err != nil {
+		return slhdsa.PublicKey{}, ErrUnsupportedAlgorithm
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(k.Pub)
+
+	if err != nil {
+		return slhdsa.PublicKey{}, ErrInvalidKey
+	}
+
+	return ctx.GetPublicKeyFromBytes(raw)
+}
+
+// Decoded SLH-DSA private key
+func (k *JWK) PrivateKey() (slhdsa.PrivateKey, error) {
+	if k.Priv == "" {
+		return slhdsa.PrivateKey{}, ErrNoPrivateKey
+	}
+
+	ctx, err := slhdsa.New(k.Alg)
+
+	if err != nil {
+		return slhdsa.PrivateKey{}, ErrUnsupportedAlgorithm
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(k.Priv)
+
+	if err != nil {
+		return slhdsa.PrivateKey{}, ErrInvalidKey
+	}
+
+	sk, err := ctx.GetPrivateKeyFromBytes(raw)
+
+	if err != nil {
+		return slhdsa.PrivateKey{}, err
+	}
+
+	pub, _ := base64.RawURLEncoding.DecodeString(k.Pub)
+	if string(raw[len(raw)/2:]) != string(pub) {
+		return slhdsa.PrivateKey{}, errors.New("jose: private key does not match public key")
+	}
+
+	return sk, nil
+}
+
+// Copy of the key without its private part
+func (k *JWK) Public() *JWK {
+	pub := *k
+	pub.Priv = ""
+
+	return &pub
+}
+
+// RFC 7638 thumbprint over the required members `alg`, `kty` and `pub`
+func (k *JWK) Thumbprint() string {
+	// Members in lexicographic order, json.Marshal escapes are not needed for these values
+	canonical := `{"alg":"` + k.Alg + `","kty":"` + k.Kty + `","pub":"` + k.Pub + `"}`
+	h := sha256.Sum256([]byte(canonical))
+
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+// JSON Web Key Set
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// Key with the given key ID, nil if absent
+func (s *JWKSet) Lookup(kid string) *JWK {
+	for _, k := range s.Keys {
+		if k.Kid == kid {
+			return k
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package jose
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+var (
+	ErrMalformed        = errors.New("jose: malformed JWS")
+	ErrNoMatchingKey    = errors.New("jose: no trusted key matches the JWS header")
+	ErrInvalidSignature = errors.New("jose: invalid signature")
+)
+
+// JOSE header parameters
+type Header map[string]any
+
+// Value of the `alg` header parameter
+func (h Header) Algorithm() string {
+	alg, _ := h["alg"].(string)
+	return alg
+}
+
+// Value of the `kid` header parameter
+func (h Header) KeyID() string {
+	kid, _ := h["kid"].(string)
+	return kid
+}
+
+// Signature entry of the general JWS JSON serialization
+type Signature struct {
+	Protected string `json:"protected"`
+	Header    Header `json:"header,omitempty"`
+	Signature string `json:"signature"`
+}
+
+// General JWS JSON serialization
+type JSONWebSignature struct {
+	Payload    string      `json:"payload"`
+	Signatures []Signature `json:"signatures"`
+}
+
+// Flattened JWS JSON serialization
+type flattenedJWS struct {
+	Payload   string `json:"payload"`
+	Protected string `json:"protected"`
+	Header    Header `json:"header,omitempty"`
+	Signature string `json:"signature"`
+}
+
+// Encode the protected header of `key`, merged with `extra`
+func protectedHeader(key *JWK, extra Header) (string, error) {
+	h := Header{}
+
+	for name, v := range extra {
+		h[name] = v
+	}
+
+	h["alg"] = key.Alg
+
+	if key.Kid != "" {
+		if _, ok := h["kid"]; !ok {
+			h["kid"] = key.Kid
+		}
+	}
+
+	encoded, err := json.Marshal(h)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// SLH-DSA signature over the JWS signing input `protected.payload`
+func sign(key *JWK, protected, payload string) (string, error) {
+	sk, err := key.PrivateKey()
+
+	if err != nil {
+		return "", err
+	}
+
+	ctx, _ := slhdsa.New(key.Alg)
+	sig, err := ctx.GenerateSignature(sk, []byte(protected+"."+payload), nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Decode the protected header and verify the signature with the trusted keys it selects
+func verifyWithKeys(protected, payload, signature string, keys []*JWK) (Header, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(protected)
+
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	var h Header
+
+	if err := json.Unmarshal(raw, &h); err != nil {
+		return nil, ErrMalformed
+	}
+
+	if _, ok := h["crit"]; ok {
+		return nil, errors.New("jose: critical header parameters are not supported")
+	}
+
+	alg := h.Algorithm()
+	if _, err := slhdsa.New(alg); err != nil {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	var lastErr error = ErrNoMatchingKey
+
+	for _, k := range keys {
+		if k.Alg != alg {
+			continue
+		}
+
+		if kid := h.KeyID(); kid != "" && k.Kid != kid {
+			continue
+		}
+
+		if lastErr = verify(k, protected, payload, signature); lastErr == nil {
+			return h, nil
+		}
+	}
+
+	return nil, lastErr
+}
+
+// Verify an encoded signature with the scheme instance selected by `key.Alg`
+func verify(key *JWK, protected, payload, signature string) error {
+	pk, err := key.PublicKey()
+
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+
+	if err != nil {
+		return ErrMalformed
+	}
+
+	ctx, _ := slhdsa.New(key.Alg)
+	ok, err := ctx.VerifySignature(pk, []byte(protected+"."+payload), sig, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil || !ok {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Produce a JWS compact serialization of `payload`
+//
+// `extra` may be nil, `alg` and `kid` are taken from the key
+func SignCompact(payload []byte, key *JWK, extra Header) (string, error) {
+	protected, err := protectedHeader(key, extra)
+
+	if err != nil {
+		return "", err
+	}
+
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := sign(key, protected, encodedPayload)
+
+	if err != nil {
+		return "", err
+	}
+
+	return protected + "." + encodedPayload + "." + sig, nil
+}
+
+// Verify a JWS compact serialization against the trusted keys
+func VerifyCompact(token string, keys []*JWK) ([]byte, Header, error) {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return nil, nil, ErrMalformed
+	}
+
+	h, err := verifyWithKeys(parts[0], parts[1], parts[2], keys)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+
+	if err != nil {
+		return nil, nil, ErrMalformed
+	}
+
+	return payload, h, nil
+}
+
+// Produce a general JWS JSON serialization with one signature per key
+func SignJSON(payload []byte, keys []*JWK, extra Header) ([]byte, error) {
+	jws := JSONWebSignature{Payload: base64.RawURLEncoding.EncodeToString(payload)}
+
+	for _, key := range keys {
+		protected, err := protectedHeader(key, extra)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sig, err := sign(key, protected, jws.Payload)
+
+		if err != nil {
+			return nil, err
+		}
+
+		jws.Signatures = append(jws.Signatures, Signature{Protected: protected, Signature: sig})
+	}
+
+	return json.Marshal(jws)
+}
+
+// Verify a general or flattened JWS JSON serialization
+//
+// Returns the payload and the protected headers of the signatures that verified
+// against the trusted keys, at least one signature must verify.
+func VerifyJSON(data []byte, keys []*JWK) ([]byte, []Header, error) {
+	var jws JSONWebSignature
+
+	if err := json.Unmarshal(data, &jws); err != nil {
+		return nil, nil, ErrMalformed
+	}
+
+	if jws.Signatures == nil {
+		var flat flattenedJWS
+
+		if err := json.Unmarshal(data, &flat); err != nil {
+			return nil, nil, ErrMalformed
+		}
+
+		jws.Payload = flat.Payload
+		jws.Signatures = []Signature{{Protected: flat.Protected, Header: flat.Header, Signature: flat.Signature}}
+	}
+
+	var verified []Header
+	var lastErr error = ErrNoMatchingKey
+
+	for _, s := range jws.Signatures {
+		h, err := verifyWithKeys(s.Protected, jws.Payload, s.Signature, keys)
+
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		verified = append(verified, h)
+	}
+
+	if len(verified) == 0 {
+		return nil, nil, lastErr
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+
+	if err != nil {
+		return nil, nil, ErrMalformed
+	}
+
+	return payload, verified, nil
+}
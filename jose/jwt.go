@@ -0,0 +1,173 @@
+package jose
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	ErrTokenExpired     = errors.New("jose: token is expired")
+	ErrTokenNotYetValid = errors.New("jose: token is not valid yet")
+	ErrIssuedInFuture   = errors.New("jose: token is issued in the future")
+	ErrInvalidIssuer    = errors.New("jose: unexpected issuer")
+	ErrInvalidAudience  = errors.New("jose: token is not intended for this audience")
+	ErrInvalidSubject   = errors.New("jose: unexpected subject")
+	ErrMissingClaim     = errors.New("jose: required claim is missing")
+)
+
+// NumericDate in seconds since the epoch
+type NumericDate int64
+
+// NumericDate of a time
+func NewNumericDate(t time.Time) *NumericDate {
+	d := NumericDate(t.Unix())
+	return &d
+}
+
+// Time of a NumericDate
+func (d NumericDate) Time() time.Time {
+	return time.Unix(int64(d), 0)
+}
+
+// Audience that unmarshals from either a single string or an array of strings
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var multi []string
+
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return err
+	}
+
+	*a = multi
+
+	return nil
+}
+
+// Registered JWT claims (RFC 7519 Section 4.1)
+type Claims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	Expiry    *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// JWT claim validation rules
+type Validation struct {
+	// Expected `iss`, not checked if empty
+	Issuer string
+
+	// Audience the token must include, not checked if empty
+	Audience string
+
+	// Expected `sub`, not checked if empty
+	Subject string
+
+	// Require the `exp` claim
+	RequireExpiry bool
+
+	// Allowed clock skew
+	Leeway time.Duration
+
+	// Validation time, defaults to the current time
+	Now time.Time
+}
+
+// Validate the registered claims
+func (c *Claims) Validate(v Validation) error {
+	now := v.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return ErrInvalidIssuer
+	}
+
+	if v.Subject != "" && c.Subject != v.Subject {
+		return ErrInvalidSubject
+	}
+
+	if v.Audience != "" && !slices.Contains(c.Audience, v.Audience) {
+		return ErrInvalidAudience
+	}
+
+	if c.Expiry == nil {
+		if v.RequireExpiry {
+			return ErrMissingClaim
+		}
+	} else if !now.Before(c.Expiry.Time().Add(v.Leeway)) {
+		return ErrTokenExpired
+	}
+
+	if c.NotBefore != nil && now.Add(v.Leeway).Before(c.NotBefore.Time()) {
+		return ErrTokenNotYetValid
+	}
+
+	if c.IssuedAt != nil && now.Add(v.Leeway).Before(c.IssuedAt.Time()) {
+		return ErrIssuedInFuture
+	}
+
+	return nil
+}
+
+// Produce a signed JWT
+//
+// `claims` is any JSON-marshalable value, typically a struct embedding Claims.
+func SignJWT(claims any, key *JWK) (string, error) {
+	payload, err := json.Marshal(claims)
+
+	if err != nil {
+		return "", err
+	}
+
+	return SignCompact(payload, key, Header{"typ": "JWT"})
+}
+
+// Verify a JWT against the trusted keys, decode it into `claims` and validate
+// its registered claims
+//
+// `claims` must be a pointer, it receives the decoded payload.
+func ParseJWT(token string, keys []*JWK, claims any, v Validation) (Header, error) {
+	payload, h, err := VerifyCompact(token, keys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, err
+	}
+
+	var registered Claims
+
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return nil, err
+	}
+
+	if err := registered.Validate(v); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
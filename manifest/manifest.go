@@ -0,0 +1,441 @@
+// Package manifest signs directory trees as a whole.
+//
+// A manifest lists every regular file and symbolic link below a root with
+// its permission bits, size and SHA-512 digest, sorted by path:
+//
+//	slhdsa manifest v1
+//	exclude *.tmp
+//	f 0644 5 <hex sha512> docs/README
+//	l 0777 6 <hex sha512 of link target> latest
+//
+// Directories are not listed, so empty directories are not covered. Paths
+// are relative, slash separated, and Go-quoted when they contain control
+// characters, invalid UTF-8 or a leading quote. The manifest text is signed
+// as a signed note (package note), so one manifest may carry signatures by
+// several keys.
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/skuuzie/go-slhdsa/note"
+)
+
+// First line of every manifest
+const header = "slhdsa manifest v1"
+
+var (
+	ErrMalformed       = errors.New("manifest: malformed manifest")
+	ErrUnsupportedFile = errors.New("manifest: unsupported file type")
+	ErrBadPattern      = errors.New("manifest: malformed exclude pattern")
+)
+
+// Manifest entry of a file or symbolic link
+type Entry struct {
+	// Slash separated path relative to the root
+	Path string
+
+	// Permission bits, with fs.ModeSymlink set for symbolic links
+	Mode fs.FileMode
+
+	// Size of the file contents or link target
+	Size int64
+
+	// SHA-512 of the file contents or link target
+	Digest []byte
+}
+
+// Contents of a directory tree
+type Manifest struct {
+	// path.Match patterns of paths left out, matching a directory leaves
+	// out everything below it
+	Exclude []string
+
+	// Entries sorted by path
+	Entries []Entry
+}
+
+func excluded(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Digest and size of the contents of r
+func digest(r io.Reader) ([]byte, int64, error) {
+	h := sha512.New()
+	n, err := io.Copy(h, r)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return h.Sum(nil), n, nil
+}
+
+func fileEntry(name, rel string, d fs.DirEntry) (Entry, error) {
+	info, err := d.Info()
+
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e := Entry{Path: rel, Mode: info.Mode().Perm()}
+
+	switch {
+	case info.Mode().IsRegular():
+		f, err := os.Open(name)
+
+		if err != nil {
+			return Entry{}, err
+		}
+
+		defer f.Close()
+
+		if e.Digest, e.Size, err = digest(f); err != nil {
+			return Entry{}, err
+		}
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(name)
+
+		if err != nil {
+			return Entry{}, err
+		}
+
+		e.Mode |= fs.ModeSymlink
+		e.Digest, e.Size, _ = digest(strings.NewReader(filepath.ToSlash(target)))
+	default:
+		return Entry{}, fmt.Errorf("%w: %s", ErrUnsupportedFile, rel)
+	}
+
+	return e, nil
+}
+
+// Build the manifest of the tree below root, leaving out paths matching
+// the exclude patterns
+//
+// Symbolic links are recorded, not followed.
+func Build(root string, exclude ...string) (*Manifest, error) {
+	for _, p := range exclude {
+		if _, err := path.Match(p, ""); err != nil || !isValidPath(p) {
+			return nil, fmt.Errorf("%w: %q", ErrBadPattern, p)
+		}
+	}
+
+	m := &Manifest{Exclude: exclude}
+
+	err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if name == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, name)
+
+		if err != nil {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if excluded(exclude, rel) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		e, err := fileEntry(name, rel, d)
+
+		if err != nil {
+			return err
+		}
+
+		m.Entries = append(m.Entries, e)
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// WalkDir order sorts "a/b" before "a.b", sort by the plain path bytes
+	slices.SortFunc(m.Entries, func(a, b Entry) int { return strings.Compare(a.Path, b.Path) })
+
+	return m, nil
+}
+
+// Patterns and unquoted paths are single-line printable UTF-8
+func isValidPath(p string) bool {
+	if p == "" || strings.HasPrefix(p, `"`) || !utf8.ValidString(p) {
+		return false
+	}
+
+	for _, r := range p {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return false
+		}
+	}
+
+	return true
+}
+
+func encodePath(p string) string {
+	if isValidPath(p) {
+		return p
+	}
+
+	return strconv.Quote(p)
+}
+
+func decodePath(s string) (string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		if !isValidPath(s) {
+			return "", ErrMalformed
+		}
+
+		return s, nil
+	}
+
+	return strconv.Unquote(s)
+}
+
+// Text encoding of the manifest
+func (m *Manifest) Marshal() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(header + "\n")
+
+	for _, p := range m.Exclude {
+		fmt.Fprintf(&buf, "exclude %s\n", p)
+	}
+
+	for _, e := range m.Entries {
+		kind := "f"
+		if e.Mode&fs.ModeSymlink != 0 {
+			kind = "l"
+		}
+
+		fmt.Fprintf(&buf, "%s %04o %d %x %s\n", kind, e.Mode.Perm(), e.Size, e.Digest, encodePath(e.Path))
+	}
+
+	return buf.Bytes()
+}
+
+// Parse the text encoding of a manifest
+func Parse(text []byte) (*Manifest, error) {
+	sc := bufio.NewScanner(bytes.NewReader(text))
+	sc.Buffer(nil, 1<<20)
+
+	if !sc.Scan() || sc.Text() != header {
+		return nil, ErrMalformed
+	}
+
+	m := &Manifest{}
+
+	for sc.Scan() {
+		line := sc.Text()
+
+		if p, ok := strings.CutPrefix(line, "exclude "); ok {
+			if len(m.Entries) != 0 || !isValidPath(p) {
+				return nil, ErrMalformed
+			}
+
+			m.Exclude = append(m.Exclude, p)
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 5)
+
+		if len(fields) != 5 {
+			return nil, ErrMalformed
+		}
+
+		var e Entry
+
+		switch fields[0] {
+		case "f":
+		case "l":
+			e.Mode = fs.ModeSymlink
+		default:
+			return nil, ErrMalformed
+		}
+
+		perm, err1 := strconv.ParseUint(fields[1], 8, 32)
+		size, err2 := strconv.ParseInt(fields[2], 10, 64)
+		digest, err3 := hex.DecodeString(fields[3])
+		name, err4 := decodePath(fields[4])
+
+		if err := errors.Join(err1, err2, err3, err4); err != nil || perm&^0o777 != 0 || size < 0 || len(digest) != sha512.Size {
+			return nil, ErrMalformed
+		}
+
+		e.Mode |= fs.FileMode(perm)
+		e.Size, e.Digest, e.Path = size, digest, name
+
+		if n := len(m.Entries); n > 0 && m.Entries[n-1].Path >= name {
+			return nil, ErrMalformed
+		}
+
+		m.Entries = append(m.Entries, e)
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, ErrMalformed
+	}
+
+	return m, nil
+}
+
+// Sign the manifest as a signed note
+func Sign(m *Manifest, signers ...note.Signer) ([]byte, error) {
+	return note.Sign(&note.Note{Text: string(m.Marshal())}, signers...)
+}
+
+// Verify a signed manifest against the known keys and parse it
+func Open(signed []byte, known note.Verifiers) (*Manifest, error) {
+	n, err := note.Open(signed, known)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse([]byte(n.Text))
+}
+
+// Kinds of differences between a manifest and a tree
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Missing
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Missing:
+		return "missing"
+	case Modified:
+		return "modified"
+	}
+
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Difference of one path
+type Change struct {
+	Kind ChangeKind
+	Path string
+
+	// Signed entry, nil when added
+	Want *Entry
+
+	// Entry found in the tree, nil when missing
+	Got *Entry
+}
+
+// Differing fields of a modified entry, such as "mode 0644 -> 0600"
+func (c Change) Detail() string {
+	if c.Kind != Modified {
+		return ""
+	}
+
+	var details []string
+
+	if c.Want.Mode&fs.ModeSymlink != c.Got.Mode&fs.ModeSymlink {
+		details = append(details, "type")
+	} else if c.Want.Mode != c.Got.Mode {
+		details = append(details, fmt.Sprintf("mode %04o -> %04o", c.Want.Mode.Perm(), c.Got.Mode.Perm()))
+	}
+
+	if c.Want.Size != c.Got.Size {
+		details = append(details, fmt.Sprintf("size %d -> %d", c.Want.Size, c.Got.Size))
+	}
+
+	if !bytes.Equal(c.Want.Digest, c.Got.Digest) {
+		details = append(details, "digest")
+	}
+
+	return strings.Join(details, ", ")
+}
+
+// Differences from the signed manifest `want` to the manifest `got` of a
+// tree, sorted by path
+func Diff(want, got *Manifest) []Change {
+	var changes []Change
+
+	i, j := 0, 0
+
+	for i < len(want.Entries) || j < len(got.Entries) {
+		switch {
+		case j == len(got.Entries) || (i < len(want.Entries) && want.Entries[i].Path < got.Entries[j].Path):
+			changes = append(changes, Change{Kind: Missing, Path: want.Entries[i].Path, Want: &want.Entries[i]})
+			i++
+		case i == len(want.Entries) || got.Entries[j].Path < want.Entries[i].Path:
+			changes = append(changes, Change{Kind: Added, Path: got.Entries[j].Path, Got: &got.Entries[j]})
+			j++
+		default:
+			w, g := &want.Entries[i], &got.Entries[j]
+
+			if w.Mode != g.Mode || w.Size != g.Size || !bytes.Equal(w.Digest, g.Digest) {
+				changes = append(changes, Change{Kind: Modified, Path: w.Path, Want: w, Got: g})
+			}
+
+			i++
+			j++
+		}
+	}
+
+	return changes
+}
+
+// Verify the signed manifest and compare it with the tree below root,
+// using the exclude patterns of the manifest
+//
+// Returns the signed manifest and the differences, an empty list when the
+// tree matches.
+func Verify(root string, signed []byte, known note.Verifiers) (*Manifest, []Change, error) {
+	want, err := Open(signed, known)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	got, err := Build(root, want.Exclude...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return want, Diff(want, got), nil
+}
@@ -0,0 +1,214 @@
+package manifest_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/manifest"
+	"github.com/skuuzie/go-slhdsa/note"
+)
+
+func newKey(t *testing.T, name string) (note.Signer, note.Verifier) {
+	skey, vkey, err := note.GenerateKey(name, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := note.NewSigner(skey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := note.NewVerifier(vkey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s, v
+}
+
+func writeTree(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+
+	for name, data := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestBuild(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"a/b":        "hello",
+		"a.b":        "",
+		"with space": "x",
+		"tmp/junk":   "junk",
+		"x.tmp":      "junk",
+	})
+
+	if err := os.Symlink("a/b", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := manifest.Build(root, "tmp", "*.tmp")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, e := range m.Entries {
+		paths = append(paths, e.Path)
+	}
+
+	// Sorted by bytes, '.' before '/'
+	if got := strings.Join(paths, ","); got != "a.b,a/b,link,with space" {
+		t.Fatalf("entries %s", got)
+	}
+
+	if e := m.Entries[1]; e.Mode != 0o644 || e.Size != 5 || len(e.Digest) != 64 {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	if e := m.Entries[2]; e.Mode&os.ModeSymlink == 0 || e.Size != 3 {
+		t.Errorf("unexpected link entry %+v", e)
+	}
+
+	text := m.Marshal()
+
+	if !strings.HasPrefix(string(text), "slhdsa manifest v1\nexclude tmp\nexclude *.tmp\nf 0644 0 cf83e135") {
+		t.Errorf("unexpected manifest\n%s", text)
+	}
+
+	parsed, err := manifest.Parse(text)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(parsed.Marshal()) != string(text) {
+		t.Errorf("round trip changed the manifest\n%s", parsed.Marshal())
+	}
+
+	if _, err := manifest.Build(root, "["); !errors.Is(err, manifest.ErrBadPattern) {
+		t.Errorf("bad pattern: %v", err)
+	}
+}
+
+func TestQuotedPaths(t *testing.T) {
+	m := &manifest.Manifest{Entries: []manifest.Entry{
+		{Path: "\"quoted\"", Mode: 0o600, Digest: make([]byte, 64)},
+		{Path: "line\nbreak", Mode: 0o600, Digest: make([]byte, 64)},
+	}}
+
+	text := m.Marshal()
+
+	if !strings.Contains(string(text), ` "line\nbreak"`) {
+		t.Errorf("path not quoted\n%s", text)
+	}
+
+	parsed, err := manifest.Parse(text)
+
+	if err != nil || parsed.Entries[0].Path != "\"quoted\"" || parsed.Entries[1].Path != "line\nbreak" {
+		t.Errorf("quoted paths round trip failed: %v", err)
+	}
+
+	for _, bad := range []string{
+		"slhdsa manifest v2\n",
+		"slhdsa manifest v1\nf 0644 0 00 a\n",
+		"slhdsa manifest v1\nd 0755 0 " + strings.Repeat("00", 64) + " a\n",
+		"slhdsa manifest v1\nf 0644 0 " + strings.Repeat("00", 64) + " b\nf 0644 0 " + strings.Repeat("00", 64) + " a\n",
+	} {
+		if _, err := manifest.Parse([]byte(bad)); !errors.Is(err, manifest.ErrMalformed) {
+			t.Errorf("%q: %v", bad, err)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	s, v := newKey(t, "dist.example.com")
+	root := writeTree(t, map[string]string{
+		"bin/tool":  "binary",
+		"docs/a.md": "a",
+		"docs/b.md": "b",
+	})
+
+	m, err := manifest.Build(root)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := manifest.Sign(m, s)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known := note.VerifierList(v)
+
+	if _, changes, err := manifest.Verify(root, signed, known); err != nil || len(changes) != 0 {
+		t.Fatalf("unchanged tree: %v %v", changes, err)
+	}
+
+	os.WriteFile(filepath.Join(root, "docs/a.md"), []byte("A"), 0o644)
+	os.WriteFile(filepath.Join(root, "docs/c.md"), []byte("c"), 0o644)
+	os.Remove(filepath.Join(root, "docs/b.md"))
+
+	want := []string{"modified docs/a.md", "missing docs/b.md", "added docs/c.md"}
+
+	if runtime.GOOS != "windows" {
+		os.Chmod(filepath.Join(root, "bin/tool"), 0o755)
+		want = append([]string{"modified bin/tool"}, want...)
+	}
+
+	_, changes, err := manifest.Verify(root, signed, known)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Kind.String()+" "+c.Path)
+	}
+
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("changes %v, want %v", got, want)
+	}
+
+	if d := changes[len(changes)-3].Detail(); d != "digest" {
+		t.Errorf("detail %q", d)
+	}
+
+	// Signatures are checked before the tree
+	_, other := newKey(t, "dist.example.com")
+
+	var unverified *note.UnverifiedNoteError
+	if _, _, err := manifest.Verify(root, signed, note.VerifierList(other)); !errors.As(err, &unverified) {
+		t.Errorf("untrusted key: %v", err)
+	}
+
+	tampered := strings.Replace(string(signed), "bin/tool", "bin/evil", 1)
+
+	var invalid *note.InvalidSignatureError
+	if _, _, err := manifest.Verify(root, []byte(tampered), known); !errors.As(err, &invalid) {
+		t.Errorf("tampered manifest: %v", err)
+	}
+}
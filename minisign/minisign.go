@@ -0,0 +1,557 @@
+// Package minisign implements an SLH-DSA variant of the minisign signature
+// and key file formats.
+//
+// Files keep the minisign layout: an untrusted comment line, a base64 line,
+// and for signatures a trusted comment line followed by a base64 global
+// signature over the file signature and the trusted comment. The binary
+// header is the algorithm tag "SL", one byte naming the parameter set and
+// the 8-byte key ID. Files are signed as the SHA-512 digest of their
+// contents, like minisign's prehashed "ED" signatures.
+//
+// Secret keys are encrypted with AES-256-GCM under a key derived by
+// PBKDF2-SHA256, in place of minisign's scrypt which the standard library
+// lacks.
+package minisign
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Signature algorithm tag
+const Algorithm = "SL"
+
+// Key derivation tags of secret key files
+const (
+	kdfNone   = "\x00\x00"
+	kdfPBKDF2 = "PG"
+)
+
+// PBKDF2 iterations for new secret keys
+const DefaultIterations = 600000
+
+// Most PBKDF2 iterations accepted from a secret key file
+const MaxIterations = 16 * DefaultIterations
+
+const (
+	untrustedPrefix = "untrusted comment: "
+	trustedPrefix   = "trusted comment: "
+)
+
+var (
+	ErrMalformed              = errors.New("minisign: malformed file")
+	ErrUnsupportedAlgorithm   = errors.New("minisign: unsupported algorithm")
+	ErrKeyIDMismatch          = errors.New("minisign: signature was made by a different key")
+	ErrInvalidSignature       = errors.New("minisign: invalid signature")
+	ErrInvalidGlobalSignature = errors.New("minisign: invalid trusted comment signature")
+	ErrWrongPassword          = errors.New("minisign: wrong password or corrupted secret key")
+	ErrPasswordRequired       = errors.New("minisign: secret key is encrypted")
+	ErrMultilineComment       = errors.New("minisign: comments must be a single line")
+)
+
+// Parameter sets in identifier order, the identifier is the index plus one
+var parameterSets = []string{
+	slhdsa.ParameterSet.SLHDSA_SHA2_128s,
+	slhdsa.ParameterSet.SLHDSA_SHA2_128f,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192s,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192f,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256s,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256f,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128s,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128f,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192s,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192f,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256s,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256f,
+}
+
+func paramSetID(paramSet string) (byte, error) {
+	for i, p := range parameterSets {
+		if p == paramSet {
+			return byte(i + 1), nil
+		}
+	}
+
+	return 0, ErrUnsupportedAlgorithm
+}
+
+func paramSetFromID(id byte) (string, error) {
+	if id == 0 || int(id) > len(parameterSets) {
+		return "", ErrUnsupportedAlgorithm
+	}
+
+	return parameterSets[id-1], nil
+}
+
+// Random identifier shared by a key pair and its signatures
+type KeyID [8]byte
+
+// Upper-case hex of the little-endian key ID, as minisign prints it
+func (id KeyID) String() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+type PublicKey struct {
+	KeyID        KeyID
+	ParameterSet string
+	Key          slhdsa.PublicKey
+}
+
+type PrivateKey struct {
+	KeyID        KeyID
+	ParameterSet string
+	Key          slhdsa.PrivateKey
+}
+
+// Generate a key pair with a random key ID
+func GenerateKey(paramSet string) (*PrivateKey, error) {
+	ctx, err := slhdsa.New(paramSet)
+
+	if err != nil {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	sk, _, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		return nil, err
+	}
+
+	k := &PrivateKey{ParameterSet: paramSet, Key: sk}
+	rand.Read(k.KeyID[:])
+
+	return k, nil
+}
+
+func (k *PrivateKey) Public() (*PublicKey, error) {
+	pk, err := slhdsa.PublicKeyFromPrivateKey(k.ParameterSet, k.Key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublicKey{KeyID: k.KeyID, ParameterSet: k.ParameterSet, Key: pk}, nil
+}
+
+// Algorithm tag, parameter set and key ID
+func header(paramSet string, id KeyID) ([]byte, error) {
+	p, err := paramSetID(paramSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append([]byte(Algorithm), p), id[:]...), nil
+}
+
+func parseHeader(data []byte) (string, KeyID, []byte, error) {
+	var id KeyID
+
+	if len(data) < 11 || string(data[:2]) != Algorithm {
+		return "", id, nil, ErrUnsupportedAlgorithm
+	}
+
+	paramSet, err := paramSetFromID(data[2])
+
+	if err != nil {
+		return "", id, nil, err
+	}
+
+	copy(id[:], data[3:11])
+
+	return paramSet, id, data[11:], nil
+}
+
+// Untrusted comment and base64 payload lines of a key or signature file
+func readLines(data []byte, n int) ([]string, error) {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, 1<<20)
+
+	var lines []string
+
+	for s.Scan() {
+		lines = append(lines, strings.TrimRight(s.Text(), "\r"))
+	}
+
+	if s.Err() != nil || len(lines) < n {
+		return nil, ErrMalformed
+	}
+
+	return lines, nil
+}
+
+func checkComment(comment string) error {
+	if strings.ContainsAny(comment, "\r\n") {
+		return ErrMultilineComment
+	}
+
+	return nil
+}
+
+// Text encoding of a public key file
+func MarshalPublicKey(pk *PublicKey) ([]byte, error) {
+	hdr, err := header(pk.ParameterSet, pk.KeyID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Appendf(nil, "%sslhdsa minisign public key %s\n%s\n", untrustedPrefix, pk.KeyID, base64.StdEncoding.EncodeToString(append(hdr, pk.Key.KeyBytes...))), nil
+}
+
+// Parse a public key file, or the bare base64 line of one
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	line := strings.TrimSpace(string(data))
+
+	if strings.HasPrefix(line, untrustedPrefix) {
+		lines, err := readLines(data, 2)
+
+		if err != nil {
+			return nil, err
+		}
+
+		line = lines[1]
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(line)
+
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	paramSet, id, key, err := parseHeader(raw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	pk, err := ctx.GetPublicKeyFromBytes(key)
+
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return &PublicKey{KeyID: id, ParameterSet: paramSet, Key: pk}, nil
+}
+
+// AES-256-GCM keyed by PBKDF2-SHA256 of the password
+//
+// Every encryption draws a new salt and so a new key, the nonce is zero.
+func secretKeyAEAD(password, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, string(password), salt, iterations, 32)
+
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Text encoding of a secret key file, encrypted unless password is nil
+//
+//	"SL" || param || kdf(2) || salt(32) || iterations(4, LE) || E(keyID || sk || checksum(32))
+//
+// E is AES-256-GCM with the preceding fields as additional data, or the
+// identity for unencrypted keys.
+func MarshalPrivateKey(k *PrivateKey, password []byte) ([]byte, error) {
+	hdr, err := header(k.ParameterSet, k.KeyID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	payload := append(k.KeyID[:], k.Key.KeyBytes...)
+	checksum := sha256.Sum256(append(hdr[:3:3], payload...))
+	payload = append(payload, checksum[:]...)
+
+	salt := make([]byte, 32)
+	iterations := 0
+	kdf := kdfNone
+
+	if password != nil {
+		rand.Read(salt)
+		iterations = DefaultIterations
+		kdf = kdfPBKDF2
+	}
+
+	raw := append([]byte(nil), hdr[:3]...)
+	raw = append(raw, kdf...)
+	raw = append(raw, salt...)
+	raw = binary.LittleEndian.AppendUint32(raw, uint32(iterations))
+
+	if password == nil {
+		raw = append(raw, payload...)
+	} else {
+		aead, err := secretKeyAEAD(password, salt, iterations)
+
+		if err != nil {
+			return nil, err
+		}
+
+		raw = aead.Seal(raw, make([]byte, aead.NonceSize()), payload, raw)
+	}
+
+	return fmt.Appendf(nil, "%sslhdsa minisign secret key %s\n%s\n", untrustedPrefix, k.KeyID, base64.StdEncoding.EncodeToString(raw)), nil
+}
+
+// Whether a secret key file needs a password
+func IsEncrypted(data []byte) (bool, error) {
+	lines, err := readLines(data, 2)
+
+	if err != nil {
+		return false, err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+
+	if err != nil || len(raw) < 5 {
+		return false, ErrMalformed
+	}
+
+	return string(raw[3:5]) != kdfNone, nil
+}
+
+// Parse a secret key file, password is ignored for unencrypted keys
+func ParsePrivateKey(data, password []byte) (*PrivateKey, error) {
+	lines, err := readLines(data, 2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+
+	if err != nil || len(raw) < 3+2+32+4 {
+		return nil, ErrMalformed
+	}
+
+	if string(raw[:2]) != Algorithm {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	paramSet, err := paramSetFromID(raw[2])
+
+	if err != nil {
+		return nil, err
+	}
+
+	kdf := string(raw[3:5])
+	salt := raw[5:37]
+	iterations := int(binary.LittleEndian.Uint32(raw[37:41]))
+	payload := raw[41:]
+
+	switch kdf {
+	case kdfNone:
+	case kdfPBKDF2:
+		if password == nil {
+			return nil, ErrPasswordRequired
+		}
+
+		if iterations == 0 || iterations > MaxIterations {
+			return nil, ErrMalformed
+		}
+
+		aead, err := secretKeyAEAD(password, salt, iterations)
+
+		if err != nil {
+			return nil, err
+		}
+
+		payload, err = aead.Open(nil, make([]byte, aead.NonceSize()), payload, raw[:41])
+
+		if err != nil {
+			return nil, ErrWrongPassword
+		}
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	if len(payload) < 8+32 {
+		return nil, ErrMalformed
+	}
+
+	body, checksum := payload[:len(payload)-32], payload[len(payload)-32:]
+	want := sha256.Sum256(append(raw[:3:3], body...))
+
+	if subtle.ConstantTimeCompare(checksum, want[:]) != 1 {
+		return nil, ErrWrongPassword
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	sk, err := ctx.GetPrivateKeyFromBytes(body[8:])
+
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	k := &PrivateKey{ParameterSet: paramSet, Key: sk}
+	copy(k.KeyID[:], body[:8])
+
+	return k, nil
+}
+
+// Parsed signature file
+type Signature struct {
+	KeyID        KeyID
+	ParameterSet string
+
+	// Signature over the SHA-512 digest of the file
+	Sig []byte
+
+	UntrustedComment string
+	TrustedComment   string
+
+	// Signature over Sig followed by the trusted comment
+	GlobalSig []byte
+}
+
+func digest(r io.Reader) ([]byte, error) {
+	h := sha512.New()
+
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+// Sign the contents of r, returns the signature file
+func Sign(k *PrivateKey, r io.Reader, trustedComment, untrustedComment string) ([]byte, error) {
+	if err := checkComment(trustedComment); err != nil {
+		return nil, err
+	}
+
+	if err := checkComment(untrustedComment); err != nil {
+		return nil, err
+	}
+
+	hdr, err := header(k.ParameterSet, k.KeyID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := digest(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, _ := slhdsa.New(k.ParameterSet)
+	sig, err := ctx.GenerateSignature(k.Key, d, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return nil, err
+	}
+
+	global, err := ctx.GenerateSignature(k.Key, append(append([]byte(nil), sig...), trustedComment...), nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if untrustedComment == "" {
+		untrustedComment = "signature from slhdsa minisign secret key"
+	}
+
+	var b bytes.Buffer
+	b.WriteString(untrustedPrefix + untrustedComment + "\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(append(hdr, sig...)) + "\n")
+	b.WriteString(trustedPrefix + trustedComment + "\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(global) + "\n")
+
+	return b.Bytes(), nil
+}
+
+// Parse a signature file
+func ParseSignature(data []byte) (*Signature, error) {
+	lines, err := readLines(data, 4)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(lines[0], untrustedPrefix) || !strings.HasPrefix(lines[2], trustedPrefix) {
+		return nil, ErrMalformed
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	paramSet, id, sig, err := parseHeader(raw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return &Signature{
+		KeyID:            id,
+		ParameterSet:     paramSet,
+		Sig:              sig,
+		UntrustedComment: strings.TrimPrefix(lines[0], untrustedPrefix),
+		TrustedComment:   strings.TrimPrefix(lines[2], trustedPrefix),
+		GlobalSig:        global,
+	}, nil
+}
+
+// Verify a signature over the contents of r and its trusted comment
+func (pk *PublicKey) Verify(r io.Reader, sig *Signature) error {
+	if sig.KeyID != pk.KeyID {
+		return ErrKeyIDMismatch
+	}
+
+	if sig.ParameterSet != pk.ParameterSet {
+		return ErrUnsupportedAlgorithm
+	}
+
+	d, err := digest(r)
+
+	if err != nil {
+		return err
+	}
+
+	ctx, _ := slhdsa.New(pk.ParameterSet)
+
+	if ok, err := ctx.VerifySignature(pk.Key, d, sig.Sig, nil, slhdsa.PreHashAlgorithm.Pure); err != nil || !ok {
+		return ErrInvalidSignature
+	}
+
+	signed := append(append([]byte(nil), sig.Sig...), sig.TrustedComment...)
+
+	if ok, err := ctx.VerifySignature(pk.Key, signed, sig.GlobalSig, nil, slhdsa.PreHashAlgorithm.Pure); err != nil || !ok {
+		return ErrInvalidGlobalSignature
+	}
+
+	return nil
+}
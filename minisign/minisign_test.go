@@ -0,0 +1,141 @@
+package minisign_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/minisign"
+)
+
+func newKey(t *testing.T) (*minisign.PrivateKey, *minisign.PublicKey) {
+	k, err := minisign.GenerateKey(slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pk, err := k.Public()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k, pk
+}
+
+func TestKeyFiles(t *testing.T) {
+	k, pk := newKey(t)
+
+	data, err := minisign.MarshalPublicKey(pk)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(data), "untrusted comment: slhdsa minisign public key "+pk.KeyID.String()+"\nU0wC") {
+		t.Errorf("unexpected public key file %q", data[:80])
+	}
+
+	// The bare base64 line is accepted as well
+	for _, in := range [][]byte{data, bytes.Split(data, []byte("\n"))[1]} {
+		parsed, err := minisign.ParsePublicKey(in)
+
+		if err != nil || parsed.KeyID != pk.KeyID || !bytes.Equal(parsed.Key.KeyBytes, pk.Key.KeyBytes) {
+			t.Errorf("public key round trip failed: %v", err)
+		}
+	}
+
+	for _, password := range [][]byte{nil, []byte("correct horse")} {
+		data, err := minisign.MarshalPrivateKey(k, password)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		encrypted, _ := minisign.IsEncrypted(data)
+
+		if encrypted != (password != nil) {
+			t.Errorf("IsEncrypted = %v for password %q", encrypted, password)
+		}
+
+		parsed, err := minisign.ParsePrivateKey(data, password)
+
+		if err != nil || parsed.KeyID != k.KeyID || !bytes.Equal(parsed.Key.KeyBytes, k.Key.KeyBytes) {
+			t.Errorf("secret key round trip failed: %v", err)
+		}
+
+		if password == nil {
+			continue
+		}
+
+		if _, err := minisign.ParsePrivateKey(data, []byte("wrong")); !errors.Is(err, minisign.ErrWrongPassword) {
+			t.Errorf("expected ErrWrongPassword, got %v", err)
+		}
+
+		if _, err := minisign.ParsePrivateKey(data, nil); !errors.Is(err, minisign.ErrPasswordRequired) {
+			t.Errorf("expected ErrPasswordRequired, got %v", err)
+		}
+
+		// An iteration count past the cap is refused before deriving the key
+		lines := strings.Split(string(data), "\n")
+		raw, _ := base64.StdEncoding.DecodeString(lines[1])
+		binary.LittleEndian.PutUint32(raw[37:41], minisign.MaxIterations+1)
+		lines[1] = base64.StdEncoding.EncodeToString(raw)
+
+		if _, err := minisign.ParsePrivateKey([]byte(strings.Join(lines, "\n")), password); !errors.Is(err, minisign.ErrMalformed) {
+			t.Errorf("expected ErrMalformed for too many iterations, got %v", err)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	k, pk := newKey(t)
+	file := []byte("release-1.0.tar.gz contents")
+
+	data, err := minisign.Sign(k, bytes.NewReader(file), "timestamp:1700000000\tfile:release-1.0.tar.gz", "")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := minisign.ParseSignature(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sig.TrustedComment != "timestamp:1700000000\tfile:release-1.0.tar.gz" || sig.KeyID != k.KeyID {
+		t.Errorf("unexpected signature %q %v", sig.TrustedComment, sig.KeyID)
+	}
+
+	if err := pk.Verify(bytes.NewReader(file), sig); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pk.Verify(strings.NewReader("other contents"), sig); !errors.Is(err, minisign.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	// The untrusted comment may change, the trusted one may not
+	sig.UntrustedComment = "edited"
+	sig.TrustedComment = "timestamp:1800000000\tfile:release-1.0.tar.gz"
+
+	if err := pk.Verify(bytes.NewReader(file), sig); !errors.Is(err, minisign.ErrInvalidGlobalSignature) {
+		t.Errorf("expected ErrInvalidGlobalSignature, got %v", err)
+	}
+
+	_, other := newKey(t)
+
+	if err := other.Verify(bytes.NewReader(file), sig); !errors.Is(err, minisign.ErrKeyIDMismatch) {
+		t.Errorf("expected ErrKeyIDMismatch, got %v", err)
+	}
+
+	if _, err := minisign.Sign(k, bytes.NewReader(file), "two\nlines", ""); !errors.Is(err, minisign.ErrMultilineComment) {
+		t.Errorf("expected ErrMultilineComment, got %v", err)
+	}
+}
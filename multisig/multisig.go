@@ -0,0 +1,101 @@
+// Package multisig implements bundles of SLH-DSA signatures over one message
+// and k-of-n threshold policies verified against them.
+package multisig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// SLH-DSA context binding signatures to bundles
+var signatureContext = []byte("go-slhdsa multisig v1")
+
+var (
+	ErrInvalidPolicy           = errors.New("multisig: invalid policy")
+	ErrDuplicateSigner         = errors.New("multisig: key already signed the bundle")
+	ErrMalformedBundle         = errors.New("multisig: malformed bundle")
+	ErrThresholdNotMet         = errors.New("multisig: signature threshold not met")
+	ErrUnsupportedParameterSet = errors.New("multisig: unsupported SLH-DSA parameter set")
+)
+
+// Key ID of a public key, hex SHA-256 of the parameter set and key truncated to 16 bytes
+//
+// Unlike slhdsa.KeyID the parameter set is hashed in. Key bytes of one size
+// are valid under several parameter sets, the ID names the pair a bundle
+// signature is verified with. Changing it would also orphan existing bundles.
+func KeyID(paramSet string, pk slhdsa.PublicKey) string {
+	h := sha256.New()
+	h.Write([]byte(paramSet))
+	h.Write([]byte{0})
+	h.Write(pk.KeyBytes)
+
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// One signature of a bundle
+type Signature struct {
+	KeyID        string `json:"keyid"`
+	ParameterSet string `json:"alg"`
+	Signature    []byte `json:"sig"`
+}
+
+// Signatures of several signers over the same message
+type Bundle struct {
+	Signatures []Signature `json:"signatures"`
+}
+
+// Parse a JSON encoded bundle
+func ParseBundle(data []byte) (*Bundle, error) {
+	var b Bundle
+
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, ErrMalformedBundle
+	}
+
+	for _, s := range b.Signatures {
+		if s.KeyID == "" || len(s.Signature) == 0 {
+			return nil, ErrMalformedBundle
+		}
+	}
+
+	return &b, nil
+}
+
+// JSON encoding of the bundle
+func (b *Bundle) Marshal() ([]byte, error) {
+	return json.Marshal(b)
+}
+
+// Sign the message and add the signature to the bundle
+//
+// Signing with a key already present in the bundle fails with ErrDuplicateSigner.
+func (b *Bundle) Sign(paramSet string, sk slhdsa.PrivateKey, message []byte) error {
+	pk, err := slhdsa.PublicKeyFromPrivateKey(paramSet, sk)
+
+	if err != nil {
+		return ErrUnsupportedParameterSet
+	}
+
+	id := KeyID(paramSet, pk)
+
+	for _, s := range b.Signatures {
+		if s.KeyID == id {
+			return ErrDuplicateSigner
+		}
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	sig, err := ctx.GenerateSignature(sk, message, signatureContext, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return err
+	}
+
+	b.Signatures = append(b.Signatures, Signature{KeyID: id, ParameterSet: paramSet, Signature: sig})
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package multisig_test
+
+import (
+	"errors"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+	"github.com/skuuzie/go-slhdsa/multisig"
+)
+
+type signer struct {
+	paramSet string
+	sk       slhdsa.PrivateKey
+	pk       slhdsa.PublicKey
+}
+
+func newSigner(t *testing.T, paramSet string) signer {
+	sk, pk := testkey.New(t, paramSet)
+
+	return signer{paramSet, sk, pk}
+}
+
+func policy(threshold int, signers ...signer) *multisig.Policy {
+	p := &multisig.Policy{Threshold: threshold}
+	for _, s := range signers {
+		p.Keys = append(p.Keys, slhdsa.TrustedKey{ParameterSet: s.paramSet, PublicKey: s.pk})
+	}
+
+	return p
+}
+
+func TestTwoOfThree(t *testing.T) {
+	a := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	b := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHAKE_128f)
+	c := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	p := policy(2, a, b, c)
+	msg := []byte("release v1.2.3")
+
+	var bundle multisig.Bundle
+
+	if err := bundle.Sign(a.paramSet, a.sk, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := multisig.VerifyPolicy(p, msg, &bundle)
+
+	if !errors.Is(err, multisig.ErrThresholdNotMet) || res.ThresholdMet || len(res.Valid) != 1 {
+		t.Fatalf("one signature met the threshold: %+v %v", res, err)
+	}
+
+	// A repeated signature by the same key must not count twice
+	if err := bundle.Sign(a.paramSet, a.sk, msg); !errors.Is(err, multisig.ErrDuplicateSigner) {
+		t.Errorf("expected ErrDuplicateSigner, got %v", err)
+	}
+
+	dup := bundle
+	dup.Signatures = append(dup.Signatures, bundle.Signatures[0])
+
+	if _, err := multisig.VerifyPolicy(p, msg, &dup); !errors.Is(err, multisig.ErrThresholdNotMet) {
+		t.Errorf("duplicated signature met the threshold: %v", err)
+	}
+
+	if err := bundle.Sign(b.paramSet, b.sk, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := bundle.Marshal()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := multisig.ParseBundle(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = multisig.VerifyPolicy(p, msg, parsed)
+
+	if err != nil || !res.ThresholdMet {
+		t.Fatalf("threshold not met: %+v %v", res, err)
+	}
+
+	if len(res.Valid) != 2 || res.Valid[0] != multisig.KeyID(a.paramSet, a.pk) || res.Valid[1] != multisig.KeyID(b.paramSet, b.pk) {
+		t.Errorf("unexpected valid signers %v", res.Valid)
+	}
+
+	if _, err := multisig.VerifyPolicy(p, []byte("release v1.2.4"), parsed); !errors.Is(err, multisig.ErrThresholdNotMet) {
+		t.Errorf("verified another message: %v", err)
+	}
+}
+
+func TestInvalidAndUnknown(t *testing.T) {
+	a := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	b := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	outsider := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	msg := []byte("message")
+
+	var bundle multisig.Bundle
+	bundle.Sign(a.paramSet, a.sk, msg)
+	bundle.Sign(b.paramSet, b.sk, msg)
+	bundle.Sign(outsider.paramSet, outsider.sk, msg)
+	bundle.Signatures[1].Signature[0] ^= 1
+
+	res, err := multisig.VerifyPolicy(policy(1, a, b), msg, &bundle)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Valid) != 1 || len(res.Invalid) != 1 || len(res.Unknown) != 1 {
+		t.Errorf("unexpected result %+v", res)
+	}
+
+	if res.Unknown[0] != multisig.KeyID(outsider.paramSet, outsider.pk) {
+		t.Errorf("unexpected unknown signer %v", res.Unknown)
+	}
+}
+
+func TestPolicyValidation(t *testing.T) {
+	a := newSigner(t, slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+
+	for _, p := range []*multisig.Policy{policy(0, a), policy(2, a), policy(1, a, a)} {
+		if err := p.Validate(); !errors.Is(err, multisig.ErrInvalidPolicy) {
+			t.Errorf("expected ErrInvalidPolicy for %d of %d, got %v", p.Threshold, len(p.Keys), err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package multisig
+
+import (
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Requires valid signatures from at least Threshold distinct keys
+type Policy struct {
+	Threshold int
+	Keys      []slhdsa.TrustedKey
+}
+
+// Outcome of a policy verification
+type Result struct {
+	// Key IDs of policy keys with a valid signature, in policy order
+	Valid []string
+
+	// Key IDs of policy keys whose signature did not verify
+	Invalid []string
+
+	// Key IDs of signatures by keys outside the policy
+	Unknown []string
+
+	// Whether the number of valid signers reached the threshold
+	ThresholdMet bool
+}
+
+// Check that the threshold is reachable and keys are distinct and well formed
+func (p *Policy) Validate() error {
+	if p.Threshold < 1 || p.Threshold > len(p.Keys) {
+		return ErrInvalidPolicy
+	}
+
+	seen := make(map[string]bool, len(p.Keys))
+
+	for _, k := range p.Keys {
+		ctx, err := slhdsa.New(k.ParameterSet)
+
+		if err != nil {
+			return ErrInvalidPolicy
+		}
+
+		if _, err := ctx.GetPublicKeyFromBytes(k.PublicKey.KeyBytes); err != nil {
+			return ErrInvalidPolicy
+		}
+
+		id := KeyID(k.ParameterSet, k.PublicKey)
+		if seen[id] {
+			return ErrInvalidPolicy
+		}
+
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// Verify the bundle signatures over the message against the policy
+//
+// Each policy key counts at most once. The result reports every signer, the
+// error is ErrThresholdNotMet when fewer than Threshold keys validated.
+func VerifyPolicy(p *Policy, message []byte, b *Bundle) (*Result, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string][]Signature)
+	for _, s := range b.Signatures {
+		byID[s.KeyID] = append(byID[s.KeyID], s)
+	}
+
+	res := &Result{}
+
+	for _, k := range p.Keys {
+		id := KeyID(k.ParameterSet, k.PublicKey)
+		sigs, ok := byID[id]
+
+		if !ok {
+			continue
+		}
+
+		delete(byID, id)
+
+		if verifyAny(k, message, sigs) {
+			res.Valid = append(res.Valid, id)
+		} else {
+			res.Invalid = append(res.Invalid, id)
+		}
+	}
+
+	for _, s := range b.Signatures {
+		if _, ok := byID[s.KeyID]; ok {
+			res.Unknown = append(res.Unknown, s.KeyID)
+			delete(byID, s.KeyID)
+		}
+	}
+
+	res.ThresholdMet = len(res.Valid) >= p.Threshold
+
+	if !res.ThresholdMet {
+		return res, ErrThresholdNotMet
+	}
+
+	return res, nil
+}
+
+func verifyAny(k slhdsa.TrustedKey, message []byte, sigs []Signature) bool {
+	ctx, _ := slhdsa.New(k.ParameterSet)
+
+	for _, s := range sigs {
+		if s.ParameterSet != k.ParameterSet {
+			continue
+		}
+
+		if ok, err := ctx.VerifySignature(k.PublicKey, message, s.Signature, signatureContext, slhdsa.PreHashAlgorithm.Pure); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
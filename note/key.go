@@ -0,0 +1,224 @@
+package note
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Key algorithm bytes for SLH-DSA keys
+//
+// The checksum database registers only Ed25519 (1); these values are
+// provisional and only understood by verifiers built with this package.
+var algorithms = map[string]byte{
+	slhdsa.ParameterSet.SLHDSA_SHA2_128s:  0x20,
+	slhdsa.ParameterSet.SLHDSA_SHA2_128f:  0x21,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192s:  0x22,
+	slhdsa.ParameterSet.SLHDSA_SHA2_192f:  0x23,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256s:  0x24,
+	slhdsa.ParameterSet.SLHDSA_SHA2_256f:  0x25,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128s: 0x26,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128f: 0x27,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192s: 0x28,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192f: 0x29,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256s: 0x2a,
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256f: 0x2b,
+}
+
+func parameterSet(alg byte) (string, bool) {
+	for paramSet, b := range algorithms {
+		if b == alg {
+			return paramSet, true
+		}
+	}
+
+	return "", false
+}
+
+// Key hash, the first 4 bytes of SHA-256(name || "\n" || alg || key)
+func keyHash(name string, key []byte) uint32 {
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte("\n"))
+	h.Write(key)
+
+	return binary.BigEndian.Uint32(h.Sum(nil))
+}
+
+// Key names are non-empty, printable and free of spaces and '+'
+func isValidName(name string) bool {
+	if name == "" || !utf8.ValidString(name) || strings.Contains(name, "+") {
+		return false
+	}
+
+	for _, r := range name {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type verifier struct {
+	name     string
+	hash     uint32
+	paramSet string
+	pk       slhdsa.PublicKey
+}
+
+func (v *verifier) Name() string    { return v.name }
+func (v *verifier) KeyHash() uint32 { return v.hash }
+
+func (v *verifier) Verify(msg, sig []byte) bool {
+	ctx, _ := slhdsa.New(v.paramSet)
+	ok, err := ctx.VerifySignature(v.pk, msg, sig, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	return err == nil && ok
+}
+
+type signer struct {
+	verifier
+	sk slhdsa.PrivateKey
+}
+
+func (s *signer) Sign(msg []byte) ([]byte, error) {
+	ctx, _ := slhdsa.New(s.paramSet)
+
+	return ctx.GenerateSignature(s.sk, msg, nil, true, slhdsa.PreHashAlgorithm.Pure)
+}
+
+// Split "name+hash+base64" into its fields and check the hash against the key
+func parseKey(s string) (name string, hash uint32, key []byte, err error) {
+	name, rest, ok1 := strings.Cut(s, "+")
+	hashHex, keyB64, ok2 := strings.Cut(rest, "+")
+
+	if !ok1 || !ok2 || !isValidName(name) || len(hashHex) != 8 {
+		return "", 0, nil, errMalformedKey
+	}
+
+	h, err1 := strconv.ParseUint(hashHex, 16, 32)
+	key, err2 := base64.StdEncoding.DecodeString(keyB64)
+
+	if err1 != nil || err2 != nil || len(key) < 1 || uint32(h) != keyHash(name, key) {
+		return "", 0, nil, errMalformedKey
+	}
+
+	return name, uint32(h), key, nil
+}
+
+// Verifier of an encoded verifier key "name+hash+base64(alg || public key)"
+func NewVerifier(vkey string) (Verifier, error) {
+	name, hash, key, err := parseKey(vkey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	paramSet, ok := parameterSet(key[0])
+
+	if !ok {
+		return nil, errUnknownAlgorithm
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	pk, err := ctx.GetPublicKeyFromBytes(key[1:])
+
+	if err != nil {
+		return nil, errMalformedKey
+	}
+
+	return &verifier{name: name, hash: hash, paramSet: paramSet, pk: pk}, nil
+}
+
+// Signer of an encoded signer key "PRIVATE+KEY+name+hash+base64(alg || private key)"
+//
+// The hash is computed over the public key, so signer and verifier keys share it.
+func NewSigner(skey string) (Signer, error) {
+	rest, ok := strings.CutPrefix(skey, "PRIVATE+KEY+")
+
+	if !ok {
+		return nil, errMalformedKey
+	}
+
+	name, rest, ok1 := strings.Cut(rest, "+")
+	hashHex, keyB64, ok2 := strings.Cut(rest, "+")
+	key, err := base64.StdEncoding.DecodeString(keyB64)
+
+	if !ok1 || !ok2 || !isValidName(name) || err != nil || len(key) < 1 {
+		return nil, errMalformedKey
+	}
+
+	paramSet, ok := parameterSet(key[0])
+
+	if !ok {
+		return nil, errUnknownAlgorithm
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	sk, err := ctx.GetPrivateKeyFromBytes(key[1:])
+
+	if err != nil {
+		return nil, errMalformedKey
+	}
+
+	pk, _ := slhdsa.PublicKeyFromPrivateKey(paramSet, sk)
+	hash := keyHash(name, append([]byte{key[0]}, pk.KeyBytes...))
+
+	if fmt.Sprintf("%08x", hash) != hashHex {
+		return nil, errMalformedKey
+	}
+
+	return &signer{verifier{name: name, hash: hash, paramSet: paramSet, pk: pk}, sk}, nil
+}
+
+// Encoded verifier key of an SLH-DSA public key
+func NewVerifierKey(name, paramSet string, pk slhdsa.PublicKey) (string, error) {
+	alg, ok := algorithms[paramSet]
+
+	if !ok {
+		return "", errUnknownAlgorithm
+	}
+
+	if !isValidName(name) {
+		return "", errMalformedKey
+	}
+
+	key := append([]byte{alg}, pk.KeyBytes...)
+
+	return fmt.Sprintf("%s+%08x+%s", name, keyHash(name, key), base64.StdEncoding.EncodeToString(key)), nil
+}
+
+// Generate a key pair, returning the encoded signer and verifier keys
+func GenerateKey(name, paramSet string) (skey, vkey string, err error) {
+	ctx, err := slhdsa.New(paramSet)
+
+	if err != nil {
+		return "", "", errUnknownAlgorithm
+	}
+
+	sk, pk, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		return "", "", err
+	}
+
+	vkey, err = NewVerifierKey(name, paramSet, pk)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	key := append([]byte{algorithms[paramSet]}, pk.KeyBytes...)
+	skey = fmt.Sprintf("PRIVATE+KEY+%s+%08x+%s", name, keyHash(name, key),
+		base64.StdEncoding.EncodeToString(append([]byte{algorithms[paramSet]}, sk.KeyBytes...)))
+
+	return skey, vkey, nil
+}
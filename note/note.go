@@ -0,0 +1,255 @@
+// Package note implements the signed note format of the Go checksum database
+// (golang.org/x/mod/sumdb/note) with SLH-DSA keys.
+//
+// A signed note is UTF-8 text ending in a newline, followed by a blank line
+// and one line per signature:
+//
+//	— <key name> <base64(key hash || signature)>
+package note
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Most signatures accepted on a single note
+const maxSignatures = 100
+
+var (
+	errMalformedKey     = errors.New("note: malformed key")
+	errUnknownAlgorithm = errors.New("note: unknown key algorithm")
+	errMalformedNote    = errors.New("note: malformed note")
+	errInvalidSigner    = errors.New("note: invalid signer")
+	errMismatchedKey    = errors.New("note: verifier name or hash does not match")
+)
+
+// Verifies signatures of one key
+type Verifier interface {
+	Name() string
+	KeyHash() uint32
+	Verify(msg, sig []byte) bool
+}
+
+// Signs notes with one key
+type Signer interface {
+	Name() string
+	KeyHash() uint32
+	Sign(msg []byte) ([]byte, error)
+}
+
+// Set of trusted verifiers
+type Verifiers interface {
+	// Verifier for the key, an *UnknownVerifierError when not trusted
+	Verifier(name string, hash uint32) (Verifier, error)
+}
+
+// Signed note
+type Note struct {
+	Text string
+
+	// Signatures verified by a trusted key
+	Sigs []Signature
+
+	// Signatures by keys outside the trusted set
+	UnverifiedSigs []Signature
+}
+
+// One signature line of a note
+type Signature struct {
+	Name   string
+	Hash   uint32
+	Base64 string
+}
+
+// Signature by a key missing from the trusted set
+type UnknownVerifierError struct {
+	Name    string
+	KeyHash uint32
+}
+
+func (e *UnknownVerifierError) Error() string {
+	return fmt.Sprintf("note: unknown key %s+%08x", e.Name, e.KeyHash)
+}
+
+// Signature by a trusted key that fails to verify
+type InvalidSignatureError struct {
+	Name string
+	Hash uint32
+}
+
+func (e *InvalidSignatureError) Error() string {
+	return fmt.Sprintf("note: invalid signature for key %s+%08x", e.Name, e.Hash)
+}
+
+// Note without any signature from a trusted key
+type UnverifiedNoteError struct {
+	Note *Note
+}
+
+func (e *UnverifiedNoteError) Error() string {
+	return "note: no verifiable signatures"
+}
+
+type verifierList map[string][]Verifier
+
+// Verifiers trusting exactly the given keys
+func VerifierList(list ...Verifier) Verifiers {
+	m := make(verifierList)
+	for _, v := range list {
+		m[v.Name()] = append(m[v.Name()], v)
+	}
+
+	return m
+}
+
+func (m verifierList) Verifier(name string, hash uint32) (Verifier, error) {
+	for _, v := range m[name] {
+		if v.KeyHash() == hash {
+			return v, nil
+		}
+	}
+
+	return nil, &UnknownVerifierError{Name: name, KeyHash: hash}
+}
+
+// Note text is valid UTF-8 ending in a newline, without other control characters
+func isValidText(text string) bool {
+	if !strings.HasSuffix(text, "\n") || !utf8.ValidString(text) {
+		return false
+	}
+
+	for _, r := range text {
+		if r != '\n' && (unicode.IsControl(r) || r == utf8.RuneError) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Sign the note text, keeping existing signatures by other keys
+func Sign(n *Note, signers ...Signer) ([]byte, error) {
+	if !isValidText(n.Text) {
+		return nil, errMalformedNote
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(n.Text)
+	buf.WriteString("\n")
+
+	seen := make(map[string]bool)
+
+	for _, s := range signers {
+		name, hash := s.Name(), s.KeyHash()
+
+		if !isValidName(name) {
+			return nil, errInvalidSigner
+		}
+
+		sig, err := s.Sign([]byte(n.Text))
+
+		if err != nil {
+			return nil, err
+		}
+
+		seen[fmt.Sprintf("%s+%08x", name, hash)] = true
+		enc := base64.StdEncoding.EncodeToString(append(binary.BigEndian.AppendUint32(nil, hash), sig...))
+		fmt.Fprintf(&buf, "— %s %s\n", name, enc)
+	}
+
+	for _, list := range [][]Signature{n.Sigs, n.UnverifiedSigs} {
+		for _, sig := range list {
+			if !seen[fmt.Sprintf("%s+%08x", sig.Name, sig.Hash)] {
+				fmt.Fprintf(&buf, "— %s %s\n", sig.Name, sig.Base64)
+			}
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Parse a signed note and verify its signatures against the known keys
+//
+// Signatures by unknown keys are kept in UnverifiedSigs. An invalid signature
+// by a known key fails with *InvalidSignatureError, a note without any
+// verified signature with *UnverifiedNoteError.
+func Open(msg []byte, known Verifiers) (*Note, error) {
+	s := string(msg)
+
+	i := strings.LastIndex(s, "\n\n")
+	if i < 0 || !strings.HasSuffix(s, "\n") {
+		return nil, errMalformedNote
+	}
+
+	n := &Note{Text: s[:i+1]}
+
+	if !isValidText(n.Text) {
+		return nil, errMalformedNote
+	}
+
+	lines := strings.Split(strings.TrimSuffix(s[i+2:], "\n"), "\n")
+	if len(lines) > maxSignatures {
+		return nil, errMalformedNote
+	}
+
+	seen := make(map[string]bool)
+
+	for _, line := range lines {
+		rest, ok := strings.CutPrefix(line, "— ")
+		name, b64, ok2 := strings.Cut(rest, " ")
+
+		if !ok || !ok2 || !isValidName(name) {
+			return nil, errMalformedNote
+		}
+
+		sig, err := base64.StdEncoding.DecodeString(b64)
+
+		if err != nil || len(sig) < 5 {
+			return nil, errMalformedNote
+		}
+
+		hash := binary.BigEndian.Uint32(sig)
+		entry := Signature{Name: name, Hash: hash, Base64: b64}
+
+		v, err := known.Verifier(name, hash)
+
+		var unknown *UnknownVerifierError
+		if errors.As(err, &unknown) {
+			n.UnverifiedSigs = append(n.UnverifiedSigs, entry)
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if v.Name() != name || v.KeyHash() != hash {
+			return nil, errMismatchedKey
+		}
+
+		id := fmt.Sprintf("%s+%08x", name, hash)
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+
+		if !v.Verify([]byte(n.Text), sig[4:]) {
+			return nil, &InvalidSignatureError{Name: name, Hash: hash}
+		}
+
+		n.Sigs = append(n.Sigs, entry)
+	}
+
+	if len(n.Sigs) == 0 {
+		return nil, &UnverifiedNoteError{Note: n}
+	}
+
+	return n, nil
+}
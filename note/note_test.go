@@ -0,0 +1,125 @@
+package note_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/note"
+)
+
+const text = "example.com/log\n42\nqINS1GRFhWHwdkUeqLEoP4dGTGWERmrhWnuiMFHMcDA=\n"
+
+func newKeys(t *testing.T, name, paramSet string) (note.Signer, note.Verifier, string) {
+	skey, vkey, err := note.GenerateKey(name, paramSet)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := note.NewSigner(skey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := note.NewVerifier(vkey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.KeyHash() != v.KeyHash() || s.Name() != name {
+		t.Fatalf("signer and verifier disagree on the key %s %08x %08x", s.Name(), s.KeyHash(), v.KeyHash())
+	}
+
+	return s, v, vkey
+}
+
+func TestSignOpen(t *testing.T) {
+	s1, v1, vkey := newKeys(t, "log.example.com", slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	s2, v2, _ := newKeys(t, "witness.example.com", slhdsa.ParameterSet.SLHDSA_SHAKE_128f)
+
+	if !strings.HasPrefix(vkey, "log.example.com+") {
+		t.Errorf("unexpected verifier key %q", vkey)
+	}
+
+	msg, err := note.Sign(&note.Note{Text: text}, s1, s2)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(msg), text+"\n— log.example.com ") {
+		t.Errorf("unexpected note %q", msg[:len(text)+30])
+	}
+
+	n, err := note.Open(msg, note.VerifierList(v1, v2))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n.Text != text || len(n.Sigs) != 2 || len(n.UnverifiedSigs) != 0 {
+		t.Errorf("unexpected note %+v", n)
+	}
+
+	// Only one trusted key, the other signature is kept unverified
+	n, err = note.Open(msg, note.VerifierList(v2))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(n.Sigs) != 1 || n.Sigs[0].Name != "witness.example.com" || len(n.UnverifiedSigs) != 1 {
+		t.Errorf("unexpected note %+v", n)
+	}
+
+	// Re-signing keeps the existing signatures
+	s3, v3, _ := newKeys(t, "other.example.com", slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	msg2, err := note.Sign(n, s3)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := note.Open(msg2, note.VerifierList(v1, v2, v3)); err != nil || len(n.Sigs) != 3 {
+		t.Errorf("re-signed note: %v", err)
+	}
+}
+
+func TestOpenErrors(t *testing.T) {
+	s, v, _ := newKeys(t, "log.example.com", slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+	_, other, _ := newKeys(t, "log.example.com", slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+
+	msg, err := note.Sign(&note.Note{Text: text}, s)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var unverified *note.UnverifiedNoteError
+	if _, err := note.Open(msg, note.VerifierList(other)); !errors.As(err, &unverified) {
+		t.Errorf("expected UnverifiedNoteError, got %v", err)
+	}
+
+	tampered := []byte(strings.Replace(string(msg), "42", "43", 1))
+
+	var invalid *note.InvalidSignatureError
+	if _, err := note.Open(tampered, note.VerifierList(v)); !errors.As(err, &invalid) {
+		t.Errorf("expected InvalidSignatureError, got %v", err)
+	}
+
+	if _, err := note.Open([]byte(text), note.VerifierList(v)); err == nil {
+		t.Error("opened a note without signatures")
+	}
+
+	if _, err := note.Sign(&note.Note{Text: "no newline"}, s); err == nil {
+		t.Error("signed text without a trailing newline")
+	}
+
+	if _, err := note.NewVerifier("log.example.com+00000000+AQ=="); err == nil {
+		t.Error("accepted a verifier key with a wrong hash")
+	}
+}
@@ -0,0 +1,275 @@
+// Package oci signs and verifies container images stored in an OCI image
+// layout on disk with SLH-DSA keys.
+//
+// A signature is an artifact manifest whose subject is the signed image
+// manifest, found through the layout index like a registry referrer. Its
+// single layer is a simple signing payload naming the manifest digest, and
+// the SLH-DSA signature over that payload is carried in the layer annotations.
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Media types (OCI image spec v1.1)
+const (
+	MediaTypeImageIndex    = "application/vnd.oci.image.index.v1+json"
+	MediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeEmptyJSON     = "application/vnd.oci.empty.v1+json"
+)
+
+// Annotation naming a manifest in the layout index
+const AnnotationRefName = "org.opencontainers.image.ref.name"
+
+var (
+	ErrNotLayout      = errors.New("oci: not an OCI image layout")
+	ErrNotFound       = errors.New("oci: reference not found in layout")
+	ErrDigestMismatch = errors.New("oci: blob does not match its digest")
+	ErrInvalidDigest  = errors.New("oci: invalid digest")
+)
+
+// Content descriptor
+type Descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// Image or artifact manifest
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Subject       *Descriptor       `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Image index, the layout's index.json
+type Index struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Manifests     []Descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// OCI image layout directory
+type Layout struct {
+	dir string
+}
+
+// Digest of content as "sha256:<hex>"
+func Digest(data []byte) string {
+	h := sha256.Sum256(data)
+
+	return "sha256:" + hex.EncodeToString(h[:])
+}
+
+// Open an existing image layout
+func OpenLayout(dir string) (*Layout, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "oci-layout"))
+
+	if err != nil {
+		return nil, ErrNotLayout
+	}
+
+	var marker struct {
+		Version string `json:"imageLayoutVersion"`
+	}
+
+	if err := json.Unmarshal(data, &marker); err != nil || marker.Version != "1.0.0" {
+		return nil, ErrNotLayout
+	}
+
+	return &Layout{dir: dir}, nil
+}
+
+// Create an empty image layout, or open it when it already exists
+func CreateLayout(dir string) (*Layout, error) {
+	if l, err := OpenLayout(dir); err == nil {
+		return l, nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0o644); err != nil {
+		return nil, err
+	}
+
+	l := &Layout{dir: dir}
+
+	if err := l.writeIndex(&Index{SchemaVersion: 2, MediaType: MediaTypeImageIndex, Manifests: []Descriptor{}}); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *Layout) blobPath(digest string) (string, error) {
+	hexDigest, ok := strings.CutPrefix(digest, "sha256:")
+
+	if !ok || len(hexDigest) != 2*sha256.Size {
+		return "", ErrInvalidDigest
+	}
+
+	if _, err := hex.DecodeString(hexDigest); err != nil || strings.ToLower(hexDigest) != hexDigest {
+		return "", ErrInvalidDigest
+	}
+
+	return filepath.Join(l.dir, "blobs", "sha256", hexDigest), nil
+}
+
+// Read a blob and check it against its digest
+func (l *Layout) ReadBlob(digest string) ([]byte, error) {
+	path, err := l.blobPath(digest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if Digest(data) != digest {
+		return nil, ErrDigestMismatch
+	}
+
+	return data, nil
+}
+
+// Store a blob and return its descriptor
+func (l *Layout) WriteBlob(mediaType string, data []byte) (Descriptor, error) {
+	d := Descriptor{MediaType: mediaType, Digest: Digest(data), Size: int64(len(data))}
+	path, _ := l.blobPath(d.Digest)
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return Descriptor{}, err
+	}
+
+	return d, nil
+}
+
+// Read the layout index
+func (l *Layout) Index() (*Index, error) {
+	data, err := os.ReadFile(filepath.Join(l.dir, "index.json"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var idx Index
+
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("oci: index.json: %w", err)
+	}
+
+	return &idx, nil
+}
+
+// Replace index.json atomically
+func (l *Layout) writeIndex(idx *Index) error {
+	data, err := json.Marshal(idx)
+
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(l.dir, "index.json.tmp")
+
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(l.dir, "index.json"))
+}
+
+// Add a manifest descriptor to the layout index
+func (l *Layout) AddManifest(d Descriptor) error {
+	idx, err := l.Index()
+
+	if err != nil {
+		return err
+	}
+
+	idx.Manifests = append(idx.Manifests, d)
+
+	return l.writeIndex(idx)
+}
+
+// Descriptor of a manifest named by its ref.name annotation or by digest
+func (l *Layout) Resolve(ref string) (Descriptor, error) {
+	idx, err := l.Index()
+
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	for _, d := range idx.Manifests {
+		if d.Digest == ref || (ref != "" && d.Annotations[AnnotationRefName] == ref) {
+			return d, nil
+		}
+	}
+
+	return Descriptor{}, ErrNotFound
+}
+
+// Read and decode a manifest blob
+func (l *Layout) Manifest(d Descriptor) (*Manifest, error) {
+	data, err := l.ReadBlob(d.Digest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("oci: manifest %s: %w", d.Digest, err)
+	}
+
+	return &m, nil
+}
+
+// Manifests in the index whose subject is the given digest
+func (l *Layout) Referrers(digest string) ([]Descriptor, error) {
+	idx, err := l.Index()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Descriptor
+
+	for _, d := range idx.Manifests {
+		if d.MediaType != MediaTypeImageManifest || d.ArtifactType == "" {
+			continue
+		}
+
+		m, err := l.Manifest(d)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if m.Subject != nil && m.Subject.Digest == digest {
+			out = append(out, d)
+		}
+	}
+
+	return out, nil
+}
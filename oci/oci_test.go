@@ -0,0 +1,125 @@
+package oci_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+	"github.com/skuuzie/go-slhdsa/oci"
+)
+
+var paramSet = slhdsa.ParameterSet.SLHDSA_SHA2_128f
+
+// Store a minimal image manifest tagged with the given name
+func addImage(t *testing.T, l *oci.Layout, tag, content string) oci.Descriptor {
+	config, _ := l.WriteBlob("application/vnd.oci.image.config.v1+json", []byte(`{"architecture":"amd64","os":"linux"}`))
+	layer, _ := l.WriteBlob("application/vnd.oci.image.layer.v1.tar", []byte(content))
+
+	data, _ := json.Marshal(oci.Manifest{
+		SchemaVersion: 2,
+		MediaType:     oci.MediaTypeImageManifest,
+		Config:        config,
+		Layers:        []oci.Descriptor{layer},
+	})
+
+	d, err := l.WriteBlob(oci.MediaTypeImageManifest, data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.Annotations = map[string]string{oci.AnnotationRefName: tag}
+
+	if err := l.AddManifest(d); err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+func TestSignVerify(t *testing.T) {
+	dir := t.TempDir()
+	l, err := oci.CreateLayout(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addImage(t, l, "v1", "layer one")
+	addImage(t, l, "v2", "layer two")
+
+	sk, pk := testkey.New(t, paramSet)
+	_, otherPK := testkey.New(t, paramSet)
+	trusted := []slhdsa.TrustedKey{{ParameterSet: paramSet, PublicKey: pk}}
+
+	l, err = oci.OpenLayout(dir)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := l.Resolve("v1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigDesc, err := l.Sign(target, "registry.example.com/app:v1", paramSet, sk)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if refs, _ := l.Referrers(target.Digest); len(refs) != 1 || refs[0].Digest != sigDesc.Digest {
+		t.Errorf("unexpected referrers %v", refs)
+	}
+
+	keyIDs, err := l.Verify(target, trusted)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keyIDs) != 1 || keyIDs[0] != slhdsa.KeyID(pk) {
+		t.Errorf("unexpected key IDs %v", keyIDs)
+	}
+
+	if _, err := l.Verify(target, []slhdsa.TrustedKey{{ParameterSet: paramSet, PublicKey: otherPK}}); !errors.Is(err, oci.ErrNoSignature) {
+		t.Errorf("expected ErrNoSignature for an untrusted key, got %v", err)
+	}
+
+	unsigned, _ := l.Resolve("v2")
+
+	if _, err := l.Verify(unsigned, trusted); !errors.Is(err, oci.ErrNoSignature) {
+		t.Errorf("expected ErrNoSignature for an unsigned image, got %v", err)
+	}
+
+	// Replacing the manifest content under the same digest must be detected
+	path := filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(target.Digest, "sha256:"))
+	os.WriteFile(path, []byte(`{"schemaVersion":2}`), 0o644)
+
+	if _, err := l.Verify(target, trusted); !errors.Is(err, oci.ErrDigestMismatch) {
+		t.Errorf("expected ErrDigestMismatch, got %v", err)
+	}
+}
+
+func TestOpenLayout(t *testing.T) {
+	if _, err := oci.OpenLayout(t.TempDir()); !errors.Is(err, oci.ErrNotLayout) {
+		t.Errorf("expected ErrNotLayout, got %v", err)
+	}
+
+	l, _ := oci.CreateLayout(t.TempDir())
+
+	if _, err := l.Resolve("missing"); !errors.Is(err, oci.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := l.ReadBlob("sha256:../../etc/passwd"); !errors.Is(err, oci.ErrInvalidDigest) {
+		t.Errorf("expected ErrInvalidDigest, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package oci
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Media types of signature artifacts
+const (
+	ArtifactTypeSignature = "application/vnd.go-slhdsa.signature.v1"
+	MediaTypePayload      = "application/vnd.go-slhdsa.simplesigning.v1+json"
+)
+
+// Layer annotations carrying the signature
+const (
+	AnnotationSignature    = "dev.go-slhdsa.signature"
+	AnnotationParameterSet = "dev.go-slhdsa.parameter-set"
+	AnnotationKeyID        = "dev.go-slhdsa.key-id"
+)
+
+// Type recorded in signed payloads
+const payloadType = "slh-dsa container image signature"
+
+var (
+	ErrNoSignature          = errors.New("oci: no signature by a trusted key")
+	ErrUnsupportedMediaType = errors.New("oci: target is not an image manifest or index")
+)
+
+// Simple signing payload binding the signature to a manifest digest
+type Payload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]string `json:"optional,omitempty"`
+}
+
+// Sign the manifest described by target and store the signature artifact in the layout
+//
+// The reference is recorded in the payload identity and may be empty.
+func (l *Layout) Sign(target Descriptor, reference, paramSet string, sk slhdsa.PrivateKey) (Descriptor, error) {
+	if target.MediaType != MediaTypeImageManifest && target.MediaType != MediaTypeImageIndex {
+		return Descriptor{}, ErrUnsupportedMediaType
+	}
+
+	// Sign only what is actually stored under the digest
+	if _, err := l.ReadBlob(target.Digest); err != nil {
+		return Descriptor{}, err
+	}
+
+	pk, err := slhdsa.PublicKeyFromPrivateKey(paramSet, sk)
+
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	var p Payload
+	p.Critical.Identity.DockerReference = reference
+	p.Critical.Image.DockerManifestDigest = target.Digest
+	p.Critical.Type = payloadType
+
+	payload, err := json.Marshal(p)
+
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	sig, err := ctx.GenerateSignature(sk, payload, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	layer, err := l.WriteBlob(MediaTypePayload, payload)
+
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	layer.Annotations = map[string]string{
+		AnnotationSignature:    base64.StdEncoding.EncodeToString(sig),
+		AnnotationParameterSet: paramSet,
+		AnnotationKeyID:        slhdsa.KeyID(pk),
+	}
+
+	config, err := l.WriteBlob(MediaTypeEmptyJSON, []byte("{}"))
+
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	subject := Descriptor{MediaType: target.MediaType, Digest: target.Digest, Size: target.Size}
+	manifest, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		ArtifactType:  ArtifactTypeSignature,
+		Config:        config,
+		Layers:        []Descriptor{layer},
+		Subject:       &subject,
+	})
+
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	d, err := l.WriteBlob(MediaTypeImageManifest, manifest)
+
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	d.ArtifactType = ArtifactTypeSignature
+
+	if err := l.AddManifest(d); err != nil {
+		return Descriptor{}, err
+	}
+
+	return d, nil
+}
+
+// Verify that the manifest described by target is signed by a trusted key
+//
+// Returns the key IDs of the trusted keys with a valid signature.
+func (l *Layout) Verify(target Descriptor, keys []slhdsa.TrustedKey) ([]string, error) {
+	// The target itself must match its digest, or the signatures vouch for other content
+	if _, err := l.ReadBlob(target.Digest); err != nil {
+		return nil, err
+	}
+
+	referrers, err := l.Referrers(target.Digest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+
+	var keyIDs []string
+
+	for _, d := range referrers {
+		if d.ArtifactType != ArtifactTypeSignature {
+			continue
+		}
+
+		m, err := l.Manifest(d)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, layer := range m.Layers {
+			id, ok := l.verifyLayer(target.Digest, layer, keys)
+
+			if ok && !seen[id] {
+				seen[id] = true
+				keyIDs = append(keyIDs, id)
+			}
+		}
+	}
+
+	if len(keyIDs) == 0 {
+		return nil, ErrNoSignature
+	}
+
+	return keyIDs, nil
+}
+
+func (l *Layout) verifyLayer(digest string, layer Descriptor, keys []slhdsa.TrustedKey) (string, bool) {
+	if layer.MediaType != MediaTypePayload {
+		return "", false
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(layer.Annotations[AnnotationSignature])
+
+	if err != nil {
+		return "", false
+	}
+
+	payload, err := l.ReadBlob(layer.Digest)
+
+	if err != nil {
+		return "", false
+	}
+
+	var p Payload
+
+	if err := json.Unmarshal(payload, &p); err != nil || p.Critical.Type != payloadType || p.Critical.Image.DockerManifestDigest != digest {
+		return "", false
+	}
+
+	for _, k := range keys {
+		id := slhdsa.KeyID(k.PublicKey)
+
+		if k.ParameterSet != layer.Annotations[AnnotationParameterSet] || id != layer.Annotations[AnnotationKeyID] {
+			continue
+		}
+
+		ctx, err := slhdsa.New(k.ParameterSet)
+
+		if err != nil {
+			continue
+		}
+
+		if ok, err := ctx.VerifySignature(k.PublicKey, payload, sig, nil, slhdsa.PreHashAlgorithm.Pure); err == nil && ok {
+			return id, true
+		}
+	}
+
+	return "", false
+}
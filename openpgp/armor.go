@@ -0,0 +1,122 @@
+package openpgp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// Armor block types
+const (
+	BlockPublicKey = "PGP PUBLIC KEY BLOCK"
+	BlockSecretKey = "PGP PRIVATE KEY BLOCK"
+	BlockSignature = "PGP SIGNATURE"
+	BlockMessage   = "PGP MESSAGE"
+)
+
+var ErrInvalidArmor = errors.New("openpgp: invalid armor")
+
+// Encode data in ASCII armor (RFC 9580 Section 6)
+//
+// The optional CRC24 checksum is omitted as recommended for v6 data.
+func Armor(blockType string, data []byte) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("-----BEGIN " + blockType + "-----\n\n")
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 64 {
+		buf.WriteString(encoded[:64] + "\n")
+		encoded = encoded[64:]
+	}
+
+	if encoded != "" {
+		buf.WriteString(encoded + "\n")
+	}
+
+	buf.WriteString("-----END " + blockType + "-----\n")
+
+	return buf.Bytes()
+}
+
+// Decode the first ASCII armored block, returns its type and data
+//
+// Armor headers are skipped and a CRC24 checksum is checked when present.
+func Dearmor(armored []byte) (string, []byte, error) {
+	lines := strings.Split(strings.ReplaceAll(string(armored), "\r\n", "\n"), "\n")
+
+	start := -1
+	var blockType string
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+
+		if strings.HasPrefix(line, "-----BEGIN ") && strings.HasSuffix(line, "-----") {
+			blockType = strings.TrimSuffix(strings.TrimPrefix(line, "-----BEGIN "), "-----")
+			start = i + 1
+			break
+		}
+	}
+
+	if start < 0 {
+		return "", nil, ErrInvalidArmor
+	}
+
+	// Skip armor headers up to the blank line
+	for start < len(lines) && strings.TrimSpace(lines[start]) != "" {
+		if !strings.Contains(lines[start], ": ") {
+			break
+		}
+
+		start++
+	}
+
+	var encoded strings.Builder
+	var checksum string
+	end := "-----END " + blockType + "-----"
+
+	for _, line := range lines[start:] {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == end:
+			data, err := base64.StdEncoding.DecodeString(encoded.String())
+
+			if err != nil {
+				return "", nil, ErrInvalidArmor
+			}
+
+			if checksum != "" && checksum != base64.StdEncoding.EncodeToString(crc24(data)) {
+				return "", nil, errors.New("openpgp: armor checksum mismatch")
+			}
+
+			return blockType, data, nil
+		case strings.HasPrefix(line, "=") && len(line) == 5:
+			checksum = line[1:]
+		default:
+			encoded.WriteString(line)
+		}
+	}
+
+	return "", nil, ErrInvalidArmor
+}
+
+// CRC24 of RFC 9580 Section 6.1.1
+func crc24(data []byte) []byte {
+	crc := uint32(0xb704ce)
+
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+
+		for range 8 {
+			crc <<= 1
+
+			if crc&0x1000000 != 0 {
+				crc ^= 0x1864cfb
+			}
+		}
+	}
+
+	return []byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}
+}
@@ -0,0 +1,126 @@
+package openpgp
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+)
+
+const cleartextHeader = "-----BEGIN PGP SIGNED MESSAGE-----"
+
+// Canonical form of cleartext signed text: CRLF line endings, trailing
+// whitespace removed and no final line ending (RFC 9580 Section 7.2)
+func canonicalCleartext(text string) []byte {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return []byte(strings.Join(lines, "\r\n"))
+}
+
+// Produce a cleartext signed message
+func SignCleartext(k *Key, text string) ([]byte, error) {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	s, err := k.sign(SigTypeText, time.Now(), binaryData(canonicalCleartext(text)), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	// v6 signatures carry their hash algorithm, no Hash armor header is emitted
+	buf.WriteString(cleartextHeader + "\n\n")
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "-") {
+			buf.WriteString("- ")
+		}
+
+		buf.WriteString(line + "\n")
+	}
+
+	buf.Write(Armor(BlockSignature, s.Serialize()))
+
+	return buf.Bytes(), nil
+}
+
+// Verify a cleartext signed message against the trusted keys
+//
+// Returns the signed text with LF line endings and the key that made the signature.
+func VerifyCleartext(keys []*Key, message []byte) (string, *Key, error) {
+	lines := strings.Split(strings.ReplaceAll(string(message), "\r\n", "\n"), "\n")
+
+	i := 0
+	for i < len(lines) && strings.TrimRight(lines[i], " \t") != cleartextHeader {
+		i++
+	}
+
+	if i == len(lines) {
+		return "", nil, errors.New("openpgp: not a cleartext signed message")
+	}
+
+	// Skip armor headers up to the blank line
+	i++
+	for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+		i++
+	}
+
+	var textLines []string
+	sigStart := -1
+
+	for j := i + 1; j < len(lines); j++ {
+		line := lines[j]
+
+		if strings.HasPrefix(line, "-----BEGIN "+BlockSignature+"-----") {
+			sigStart = j
+			break
+		}
+
+		textLines = append(textLines, strings.TrimPrefix(line, "- "))
+	}
+
+	if sigStart < 0 {
+		return "", nil, ErrInvalidArmor
+	}
+
+	blockType, sigData, err := Dearmor([]byte(strings.Join(lines[sigStart:], "\n")))
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if blockType != BlockSignature {
+		return "", nil, ErrInvalidArmor
+	}
+
+	text := strings.Join(textLines, "\n")
+
+	s, err := ParseSignature(sigData)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if s.Type != SigTypeText {
+		return "", nil, ErrMalformed
+	}
+
+	for _, k := range keys {
+		if !bytes.Equal(k.Fingerprint(), s.IssuerFingerprint) {
+			continue
+		}
+
+		if err := s.verify(k, binaryData(canonicalCleartext(text))); err != nil {
+			return "", nil, err
+		}
+
+		return text, k, nil
+	}
+
+	return "", nil, ErrUnknownIssuer
+}
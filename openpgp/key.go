@@ -0,0 +1,303 @@
+package openpgp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+var (
+	ErrNoPrivateKey    = errors.New("openpgp: key has no private part")
+	ErrNoSelfSignature = errors.New("openpgp: key has no valid self-signature")
+)
+
+// Version 6 SLH-DSA primary key
+type Key struct {
+	ParameterSet string
+	CreationTime time.Time
+	PublicKey    slhdsa.PublicKey
+
+	// Private key, nil for public keys
+	PrivateKey *slhdsa.PrivateKey
+
+	// User ID certified by the key, may be empty
+	UserID string
+
+	// Verified self-signature packets of a parsed key, with the user ID
+	// packet they certify
+	selfSigs []byte
+	selfUID  string
+}
+
+// Generate a new SLH-DSA key
+func GenerateKey(paramSet, userID string) (*Key, error) {
+	if _, err := algorithmByParamSet(paramSet); err != nil {
+		return nil, err
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	sk, pk, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		ParameterSet: paramSet,
+		CreationTime: time.Now().Truncate(time.Second),
+		PublicKey:    pk,
+		PrivateKey:   &sk,
+		UserID:       userID,
+	}, nil
+}
+
+// Public key packet body (RFC 9580 Section 5.5.2)
+func (k *Key) publicBody() []byte {
+	alg, _ := algorithmByParamSet(k.ParameterSet)
+
+	var buf bytes.Buffer
+
+	buf.WriteByte(6)
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(k.CreationTime.Unix())))
+	buf.WriteByte(alg.id)
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(k.PublicKey.KeyBytes))))
+	buf.Write(k.PublicKey.KeyBytes)
+
+	return buf.Bytes()
+}
+
+// Feed the key into a signature hash (RFC 9580 Section 5.2.4)
+func (k *Key) hashKey(h hash.Hash) {
+	body := k.publicBody()
+
+	h.Write([]byte{0x9b})
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(body))))
+	h.Write(body)
+}
+
+// v6 fingerprint (RFC 9580 Section 5.5.4.3)
+func (k *Key) Fingerprint() []byte {
+	h := sha256.New()
+	k.hashKey(h)
+
+	return h.Sum(nil)
+}
+
+// v6 key ID, the leading 8 octets of the fingerprint
+func (k *Key) KeyID() []byte {
+	return k.Fingerprint()[:8]
+}
+
+// Copy of the key without its private part
+func (k *Key) Public() *Key {
+	pub := *k
+	pub.PrivateKey = nil
+
+	return &pub
+}
+
+func (k *Key) directKeyData() hashWriter {
+	return k.hashKey
+}
+
+func (k *Key) userIDData(uid string) hashWriter {
+	return func(h hash.Hash) {
+		k.hashKey(h)
+		h.Write([]byte{0xb4})
+		h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(uid))))
+		h.Write([]byte(uid))
+	}
+}
+
+// Self-signatures binding the key metadata and user ID
+func (k *Key) selfSignatures() ([]byte, error) {
+	var buf bytes.Buffer
+
+	keyFlags := subpacket{typ: subpacketKeyFlags, data: []byte{0x03}}
+	prefs := subpacket{typ: subpacketPreferredHash, data: []byte{HashSHA3_512, HashSHA3_256}}
+
+	direct, err := k.sign(SigTypeDirectKey, k.CreationTime, k.directKeyData(), []subpacket{keyFlags, prefs})
+
+	if err != nil {
+		return nil, err
+	}
+
+	buf.Write(direct.Serialize())
+
+	if k.UserID != "" {
+		primary := subpacket{typ: subpacketPrimaryUserID, data: []byte{1}}
+		cert, err := k.sign(SigTypePositiveCertification, k.CreationTime, k.userIDData(k.UserID), []subpacket{keyFlags, primary})
+
+		if err != nil {
+			return nil, err
+		}
+
+		writePacket(&buf, tagUserID, []byte(k.UserID))
+		buf.Write(cert.Serialize())
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Serialize a transferable public key
+//
+// Keys with a private part get fresh self-signatures, public keys read by
+// ReadKey keep the self-signatures they were read with.
+func (k *Key) SerializePublic() ([]byte, error) {
+	sigs, err := k.selfSignatures()
+
+	if err == ErrNoPrivateKey && k.selfSigs != nil && k.selfUID == k.UserID {
+		sigs, err = k.selfSigs, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writePacket(&buf, tagPublicKey, k.publicBody())
+	buf.Write(sigs)
+
+	return buf.Bytes(), nil
+}
+
+// Serialize a transferable secret key with unencrypted key material
+func (k *Key) SerializePrivate() ([]byte, error) {
+	sigs, err := k.selfSignatures()
+
+	if err != nil {
+		return nil, err
+	}
+
+	body := append(k.publicBody(), 0)
+	body = append(body, k.PrivateKey.KeyBytes...)
+
+	var buf bytes.Buffer
+	writePacket(&buf, tagSecretKey, body)
+	buf.Write(sigs)
+
+	return buf.Bytes(), nil
+}
+
+// Parse the common public key fields, returns the number of bytes consumed
+func parsePublicBody(body []byte) (*Key, int, error) {
+	if len(body) < 10 || body[0] != 6 {
+		return nil, 0, ErrMalformed
+	}
+
+	alg, err := algorithmByID(body[5])
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	n := int(binary.BigEndian.Uint32(body[6:10]))
+
+	if n > len(body)-10 {
+		return nil, 0, ErrMalformed
+	}
+
+	ctx, _ := slhdsa.New(alg.paramSet)
+	pk, err := ctx.GetPublicKeyFromBytes(body[10 : 10+n])
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &Key{
+		ParameterSet: alg.paramSet,
+		CreationTime: time.Unix(int64(binary.BigEndian.Uint32(body[1:5])), 0),
+		PublicKey:    pk,
+	}, 10 + n, nil
+}
+
+// Parse a transferable public or secret key and check its self-signatures
+func ReadKey(data []byte) (*Key, error) {
+	packets, err := readPackets(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(packets) == 0 {
+		return nil, ErrMalformed
+	}
+
+	k, n, err := parsePublicBody(packets[0].body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch packets[0].tag {
+	case tagPublicKey:
+		if n != len(packets[0].body) {
+			return nil, ErrMalformed
+		}
+	case tagSecretKey:
+		secret := packets[0].body[n:]
+
+		if len(secret) == 0 || secret[0] != 0 {
+			return nil, errors.New("openpgp: encrypted secret keys are not supported")
+		}
+
+		ctx, _ := slhdsa.New(k.ParameterSet)
+		sk, err := ctx.GetPrivateKeyFromBytes(secret[1:])
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !bytes.Equal(sk.KeyBytes[len(sk.KeyBytes)/2:], k.PublicKey.KeyBytes) {
+			return nil, errors.New("openpgp: secret key does not match public key")
+		}
+
+		k.PrivateKey = &sk
+	default:
+		return nil, ErrMalformed
+	}
+
+	var direct, cert []byte
+	var uid string
+
+	for _, p := range packets[1:] {
+		switch p.tag {
+		case tagUserID:
+			uid = string(p.body)
+		case tagSignature:
+			s, err := parseSignature(p.body)
+
+			if err != nil || !bytes.Equal(s.IssuerFingerprint, k.Fingerprint()) {
+				continue
+			}
+
+			switch {
+			case s.Type == SigTypeDirectKey && direct == nil && s.verify(k, k.directKeyData()) == nil:
+				var buf bytes.Buffer
+				writePacket(&buf, tagSignature, p.body)
+				direct = buf.Bytes()
+			case s.Type >= 0x10 && s.Type <= SigTypePositiveCertification && uid != "" && k.UserID == "" && s.verify(k, k.userIDData(uid)) == nil:
+				k.UserID = uid
+
+				var buf bytes.Buffer
+				writePacket(&buf, tagUserID, []byte(uid))
+				writePacket(&buf, tagSignature, p.body)
+				cert = buf.Bytes()
+			}
+		}
+	}
+
+	if direct == nil && cert == nil {
+		return nil, ErrNoSelfSignature
+	}
+
+	k.selfSigs = append(direct, cert...)
+	k.selfUID = k.UserID
+
+	return k, nil
+}
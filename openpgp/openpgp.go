@@ -0,0 +1,94 @@
+// Package openpgp implements OpenPGP v6 (RFC 9580) key and signature packets
+// for SLH-DSA as specified by draft-ietf-openpgp-pqc, together with ASCII
+// armor and the cleartext signature framework.
+package openpgp
+
+import (
+	"crypto/sha3"
+	"errors"
+	"hash"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Public key algorithm identifiers (draft-ietf-openpgp-pqc)
+const (
+	AlgorithmSLHDSASHAKE128s = 32
+	AlgorithmSLHDSASHAKE128f = 33
+	AlgorithmSLHDSASHAKE256s = 34
+)
+
+// Hash algorithm identifiers (RFC 9580 Section 9.5)
+const (
+	HashSHA3_256 = 12
+	HashSHA3_512 = 14
+)
+
+// Signature types (RFC 9580 Section 5.2.1)
+const (
+	SigTypeBinary                = 0x00
+	SigTypeText                  = 0x01
+	SigTypePositiveCertification = 0x13
+	SigTypeDirectKey             = 0x1f
+)
+
+// Packet tags (RFC 9580 Section 5)
+const (
+	tagSignature = 2
+	tagSecretKey = 5
+	tagPublicKey = 6
+	tagUserID    = 13
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("openpgp: unsupported public key algorithm")
+	ErrUnsupportedHash      = errors.New("openpgp: hash algorithm not allowed for SLH-DSA")
+	ErrMalformed            = errors.New("openpgp: malformed packet")
+	ErrUnknownIssuer        = errors.New("openpgp: signature made by unknown key")
+	ErrInvalidSignature     = errors.New("openpgp: invalid signature")
+)
+
+type algorithmInfo struct {
+	id       byte
+	paramSet string
+	hash     byte
+}
+
+// Parameter sets defined for OpenPGP and their mandated hash algorithm
+var algorithms = []algorithmInfo{
+	{AlgorithmSLHDSASHAKE128s, slhdsa.ParameterSet.SLHDSA_SHAKE_128s, HashSHA3_256},
+	{AlgorithmSLHDSASHAKE128f, slhdsa.ParameterSet.SLHDSA_SHAKE_128f, HashSHA3_256},
+	{AlgorithmSLHDSASHAKE256s, slhdsa.ParameterSet.SLHDSA_SHAKE_256s, HashSHA3_512},
+}
+
+func algorithmByParamSet(paramSet string) (algorithmInfo, error) {
+	for _, a := range algorithms {
+		if a.paramSet == paramSet {
+			return a, nil
+		}
+	}
+
+	return algorithmInfo{}, ErrUnsupportedAlgorithm
+}
+
+func algorithmByID(id byte) (algorithmInfo, error) {
+	for _, a := range algorithms {
+		if a.id == id {
+			return a, nil
+		}
+	}
+
+	return algorithmInfo{}, ErrUnsupportedAlgorithm
+}
+
+// Hash function and v6 salt size of a hash algorithm identifier
+func newHash(id byte) (hash.Hash, int, error) {
+	switch id {
+	case HashSHA3_256:
+		return sha3.New256(), 16, nil
+	case HashSHA3_512:
+		return sha3.New512(), 32, nil
+	}
+
+	return nil, 0, ErrUnsupportedHash
+}
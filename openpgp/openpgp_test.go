@@ -0,0 +1,185 @@
+package openpgp_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/openpgp"
+)
+
+func newKey(t *testing.T) *openpgp.Key {
+	k, err := openpgp.GenerateKey(slhdsa.ParameterSet.SLHDSA_SHAKE_128f, "Release Signing <release@example.org>")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k
+}
+
+func TestUnsupportedParameterSet(t *testing.T) {
+	if _, err := openpgp.GenerateKey(slhdsa.ParameterSet.SLHDSA_SHA2_128f, ""); err != openpgp.ErrUnsupportedAlgorithm {
+		t.Errorf("expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+	k := newKey(t)
+
+	pub, err := k.SerializePublic()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kind, data, err := openpgp.Dearmor(openpgp.Armor(openpgp.BlockPublicKey, pub))
+
+	if err != nil || kind != openpgp.BlockPublicKey {
+		t.Fatalf("dearmor: %v %v", kind, err)
+	}
+
+	parsed, err := openpgp.ReadKey(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(parsed.Fingerprint(), k.Fingerprint()) || parsed.UserID != k.UserID || parsed.PrivateKey != nil {
+		t.Errorf("unexpected key %+v", parsed)
+	}
+
+	// A public key re-serializes with the self-signatures it was read with
+	again, err := parsed.SerializePublic()
+
+	if err != nil || !bytes.Equal(again, pub) {
+		t.Errorf("public key re-serialized as %x, %v", again, err)
+	}
+
+	parsed.UserID = "Someone Else <someone@example.org>"
+
+	if _, err := parsed.SerializePublic(); err != openpgp.ErrNoPrivateKey {
+		t.Errorf("expected ErrNoPrivateKey for an uncertified user ID, got %v", err)
+	}
+
+	priv, err := k.SerializePrivate()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err = openpgp.ReadKey(priv)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.PrivateKey == nil || !bytes.Equal(parsed.PrivateKey.KeyBytes, k.PrivateKey.KeyBytes) {
+		t.Error("private key not recovered")
+	}
+
+	tampered := bytes.Replace(pub, []byte("release@"), []byte("attacker"), 1)
+	if parsed, err := openpgp.ReadKey(tampered); err != nil || parsed.UserID != "" {
+		t.Errorf("tampered user ID bound to key: %q %v", parsed.UserID, err)
+	}
+}
+
+func TestDetached(t *testing.T) {
+	k := newKey(t)
+	message := []byte("package-1.0.tar.gz")
+
+	sig, err := openpgp.SignDetached(k, message, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, signer, err := openpgp.VerifyDetached([]*openpgp.Key{k.Public()}, message, sig)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Hash != openpgp.HashSHA3_256 || !bytes.Equal(signer.Fingerprint(), k.Fingerprint()) {
+		t.Errorf("unexpected signature %+v", s)
+	}
+
+	if _, _, err := openpgp.VerifyDetached([]*openpgp.Key{k}, []byte("other"), sig); err != openpgp.ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	if _, _, err := openpgp.VerifyDetached([]*openpgp.Key{newKey(t)}, message, sig); err != openpgp.ErrUnknownIssuer {
+		t.Errorf("expected ErrUnknownIssuer, got %v", err)
+	}
+
+	textSig, _ := openpgp.SignDetached(k, []byte("line\n"), true)
+	if _, _, err := openpgp.VerifyDetached([]*openpgp.Key{k}, []byte("line\r\n"), textSig); err != nil {
+		t.Errorf("text signature: %v", err)
+	}
+}
+
+func TestLongSubpacket(t *testing.T) {
+	k := newKey(t)
+	message := []byte("package-1.0.tar.gz")
+
+	sig, err := openpgp.SignDetached(k, message, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a 9001-octet notation subpacket, its two-octet length starts with 226
+	body := sig[6:]
+	hashed := binary.BigEndian.Uint32(body[4:8])
+	sub := append([]byte{226, 105, 20}, make([]byte, 9000)...)
+
+	out := append([]byte{}, body[:4]...)
+	out = binary.BigEndian.AppendUint32(out, hashed+uint32(len(sub)))
+	out = append(out, body[8:8+hashed]...)
+	out = append(out, sub...)
+	out = append(out, body[8+hashed:]...)
+
+	packet := binary.BigEndian.AppendUint32([]byte{0xc2, 0xff}, uint32(len(out)))
+	packet = append(packet, out...)
+
+	if _, err := openpgp.ParseSignature(packet); err != nil {
+		t.Fatal(err)
+	}
+
+	// Parsed, but no longer matching the signed hashed area
+	if _, _, err := openpgp.VerifyDetached([]*openpgp.Key{k}, message, packet); err != openpgp.ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestCleartext(t *testing.T) {
+	k := newKey(t)
+	text := "Release notes\n- fixed a bug   \n-----\n"
+
+	signed, err := openpgp.SignCleartext(k, text)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(signed), "\n- -----\n") {
+		t.Error("dash-escaping missing")
+	}
+
+	got, _, err := openpgp.VerifyCleartext([]*openpgp.Key{k}, signed)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != strings.TrimSuffix(text, "\n") {
+		t.Errorf("unexpected text %q", got)
+	}
+
+	tampered := bytes.Replace(signed, []byte("fixed"), []byte("added"), 1)
+	if _, _, err := openpgp.VerifyCleartext([]*openpgp.Key{k}, tampered); err == nil {
+		t.Error("tampered message verified")
+	}
+}
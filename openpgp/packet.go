@@ -0,0 +1,169 @@
+package openpgp
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Raw OpenPGP packet
+type packet struct {
+	tag  byte
+	body []byte
+}
+
+// Serialize a packet in the OpenPGP packet format (RFC 9580 Section 4.2.1)
+func writePacket(buf *bytes.Buffer, tag byte, body []byte) {
+	buf.WriteByte(0xc0 | tag)
+	writeLength(buf, len(body))
+	buf.Write(body)
+}
+
+func writeLength(buf *bytes.Buffer, n int) {
+	switch {
+	case n < 192:
+		buf.WriteByte(byte(n))
+	case n < 8384:
+		n -= 192
+		buf.Write([]byte{byte(n>>8) + 192, byte(n)})
+	default:
+		buf.WriteByte(0xff)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+// Length header of packets and subpackets
+//
+// First octets 224 to 254 are two-octet lengths in subpackets (RFC 9580
+// Section 5.2.3.7) but partial body lengths in packet headers, which only
+// data packets may use and are not supported.
+func readLength(data []byte, subpacket bool) (int, int, error) {
+	if len(data) == 0 {
+		return 0, 0, ErrMalformed
+	}
+
+	switch {
+	case data[0] < 192:
+		return int(data[0]), 1, nil
+	case data[0] < 224 || (subpacket && data[0] < 255):
+		if len(data) < 2 {
+			return 0, 0, ErrMalformed
+		}
+
+		return (int(data[0])-192)<<8 + int(data[1]) + 192, 2, nil
+	case data[0] == 255:
+		if len(data) < 5 {
+			return 0, 0, ErrMalformed
+		}
+
+		return int(binary.BigEndian.Uint32(data[1:5])), 5, nil
+	}
+
+	return 0, 0, ErrMalformed
+}
+
+// Split a byte stream into packets, legacy format headers are accepted
+func readPackets(data []byte) ([]packet, error) {
+	var packets []packet
+
+	for len(data) > 0 {
+		hdr := data[0]
+
+		if hdr&0x80 == 0 {
+			return nil, ErrMalformed
+		}
+
+		var tag byte
+		var length, consumed int
+
+		if hdr&0x40 != 0 {
+			tag = hdr & 0x3f
+
+			n, c, err := readLength(data[1:], false)
+
+			if err != nil {
+				return nil, err
+			}
+
+			length, consumed = n, 1+c
+		} else {
+			tag = (hdr >> 2) & 0x0f
+			lenSize := []int{1, 2, 4, 0}[hdr&0x03]
+
+			if lenSize == 0 || len(data) < 1+lenSize {
+				return nil, ErrMalformed
+			}
+
+			for _, b := range data[1 : 1+lenSize] {
+				length = length<<8 | int(b)
+			}
+
+			consumed = 1 + lenSize
+		}
+
+		if length < 0 || length > len(data)-consumed {
+			return nil, ErrMalformed
+		}
+
+		packets = append(packets, packet{tag: tag, body: data[consumed : consumed+length]})
+		data = data[consumed+length:]
+	}
+
+	return packets, nil
+}
+
+// Signature subpacket types (RFC 9580 Section 5.2.3.7)
+const (
+	subpacketCreationTime      = 2
+	subpacketKeyExpiration     = 9
+	subpacketPreferredHash     = 21
+	subpacketPrimaryUserID     = 25
+	subpacketKeyFlags          = 27
+	subpacketIssuerFingerprint = 33
+)
+
+// Signature subpacket
+type subpacket struct {
+	typ      byte
+	critical bool
+	data     []byte
+}
+
+func serializeSubpackets(subpackets []subpacket) []byte {
+	var buf bytes.Buffer
+
+	for _, s := range subpackets {
+		writeLength(&buf, len(s.data)+1)
+
+		typ := s.typ
+		if s.critical {
+			typ |= 0x80
+		}
+
+		buf.WriteByte(typ)
+		buf.Write(s.data)
+	}
+
+	return buf.Bytes()
+}
+
+func parseSubpackets(data []byte) ([]subpacket, error) {
+	var subpackets []subpacket
+
+	for len(data) > 0 {
+		length, consumed, err := readLength(data, true)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if length <= 0 || length > len(data)-consumed {
+			return nil, ErrMalformed
+		}
+
+		body := data[consumed : consumed+length]
+		subpackets = append(subpackets, subpacket{typ: body[0] & 0x7f, critical: body[0]&0x80 != 0, data: body[1:]})
+		data = data[consumed+length:]
+	}
+
+	return subpackets, nil
+}
@@ -0,0 +1,349 @@
+package openpgp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"hash"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Version 6 signature packet
+type Signature struct {
+	Type         byte
+	Algorithm    byte
+	Hash         byte
+	CreationTime time.Time
+
+	// v6 fingerprint of the issuing key
+	IssuerFingerprint []byte
+
+	Salt []byte
+
+	// Raw SLH-DSA signature
+	Material []byte
+
+	hashedArea   []byte
+	unhashedArea []byte
+	hashTag      [2]byte
+	subpackets   []subpacket
+}
+
+// Feed the signed data of a signature type into the hash
+type hashWriter func(h hash.Hash)
+
+// Hashed portion of the packet followed by the v6 trailer (RFC 9580 Section 5.2.4)
+func (s *Signature) trailer() []byte {
+	var buf bytes.Buffer
+
+	buf.Write([]byte{6, s.Type, s.Algorithm, s.Hash})
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(s.hashedArea))))
+	buf.Write(s.hashedArea)
+
+	n := buf.Len()
+	buf.Write([]byte{6, 0xff})
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+
+	return buf.Bytes()
+}
+
+func (s *Signature) digest(data hashWriter) ([]byte, error) {
+	h, _, err := newHash(s.Hash)
+
+	if err != nil {
+		return nil, err
+	}
+
+	h.Write(s.Salt)
+	data(h)
+	h.Write(s.trailer())
+
+	return h.Sum(nil), nil
+}
+
+// Create a v6 signature with `k` over the data written by `data`
+func (k *Key) sign(sigType byte, created time.Time, data hashWriter, extra []subpacket) (*Signature, error) {
+	if k.PrivateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	alg, err := algorithmByParamSet(k.ParameterSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, saltSize, _ := newHash(alg.hash)
+
+	s := &Signature{
+		Type:              sigType,
+		Algorithm:         alg.id,
+		Hash:              alg.hash,
+		CreationTime:      created.Truncate(time.Second),
+		IssuerFingerprint: k.Fingerprint(),
+		Salt:              make([]byte, saltSize),
+	}
+	rand.Read(s.Salt)
+
+	s.subpackets = append([]subpacket{
+		{typ: subpacketCreationTime, critical: true, data: binary.BigEndian.AppendUint32(nil, uint32(s.CreationTime.Unix()))},
+		{typ: subpacketIssuerFingerprint, data: append([]byte{6}, s.IssuerFingerprint...)},
+	}, extra...)
+	s.hashedArea = serializeSubpackets(s.subpackets)
+
+	digest, err := s.digest(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	copy(s.hashTag[:], digest[:2])
+
+	ctx, _ := slhdsa.New(k.ParameterSet)
+	s.Material, err = ctx.GenerateSignature(*k.PrivateKey, digest, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Verify the signature made by `k` over the data written by `data`
+func (s *Signature) verify(k *Key, data hashWriter) error {
+	alg, err := algorithmByID(s.Algorithm)
+
+	if err != nil {
+		return err
+	}
+
+	if alg.paramSet != k.ParameterSet {
+		return ErrInvalidSignature
+	}
+
+	if s.Hash != alg.hash {
+		return ErrUnsupportedHash
+	}
+
+	digest, err := s.digest(data)
+
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(digest[:2], s.hashTag[:]) {
+		return ErrInvalidSignature
+	}
+
+	ctx, _ := slhdsa.New(k.ParameterSet)
+	ok, err := ctx.VerifySignature(k.PublicKey, digest, s.Material, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil || !ok {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Serialize the signature packet body
+func (s *Signature) body() []byte {
+	var buf bytes.Buffer
+
+	buf.Write([]byte{6, s.Type, s.Algorithm, s.Hash})
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(s.hashedArea))))
+	buf.Write(s.hashedArea)
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(s.unhashedArea))))
+	buf.Write(s.unhashedArea)
+	buf.Write(s.hashTag[:])
+	buf.WriteByte(byte(len(s.Salt)))
+	buf.Write(s.Salt)
+	buf.Write(s.Material)
+
+	return buf.Bytes()
+}
+
+// Serialize the signature as an OpenPGP packet
+func (s *Signature) Serialize() []byte {
+	var buf bytes.Buffer
+	writePacket(&buf, tagSignature, s.body())
+
+	return buf.Bytes()
+}
+
+func parseSignature(body []byte) (*Signature, error) {
+	if len(body) < 8 || body[0] != 6 {
+		return nil, ErrMalformed
+	}
+
+	s := &Signature{Type: body[1], Algorithm: body[2], Hash: body[3]}
+	rest := body[4:]
+
+	readArea := func() ([]byte, bool) {
+		if len(rest) < 4 {
+			return nil, false
+		}
+
+		n := binary.BigEndian.Uint32(rest)
+		rest = rest[4:]
+
+		if uint64(n) > uint64(len(rest)) {
+			return nil, false
+		}
+
+		area := rest[:n]
+		rest = rest[n:]
+
+		return area, true
+	}
+
+	var ok bool
+
+	if s.hashedArea, ok = readArea(); !ok {
+		return nil, ErrMalformed
+	}
+
+	if s.unhashedArea, ok = readArea(); !ok {
+		return nil, ErrMalformed
+	}
+
+	if len(rest) < 3 {
+		return nil, ErrMalformed
+	}
+
+	copy(s.hashTag[:], rest[:2])
+	saltSize := int(rest[2])
+	rest = rest[3:]
+
+	if len(rest) < saltSize {
+		return nil, ErrMalformed
+	}
+
+	s.Salt = rest[:saltSize]
+	s.Material = rest[saltSize:]
+
+	if _, expected, err := newHash(s.Hash); err != nil || expected != saltSize {
+		return nil, ErrUnsupportedHash
+	}
+
+	subpackets, err := parseSubpackets(s.hashedArea)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.subpackets = subpackets
+
+	for _, sp := range subpackets {
+		switch sp.typ {
+		case subpacketCreationTime:
+			if len(sp.data) != 4 {
+				return nil, ErrMalformed
+			}
+
+			s.CreationTime = time.Unix(int64(binary.BigEndian.Uint32(sp.data)), 0)
+		case subpacketIssuerFingerprint:
+			if len(sp.data) != 33 || sp.data[0] != 6 {
+				return nil, ErrMalformed
+			}
+
+			s.IssuerFingerprint = sp.data[1:]
+		case subpacketKeyExpiration, subpacketPreferredHash, subpacketPrimaryUserID, subpacketKeyFlags:
+		default:
+			if sp.critical {
+				return nil, ErrMalformed
+			}
+		}
+	}
+
+	if s.CreationTime.IsZero() || s.IssuerFingerprint == nil {
+		return nil, ErrMalformed
+	}
+
+	return s, nil
+}
+
+// Parse a single signature packet
+func ParseSignature(data []byte) (*Signature, error) {
+	packets, err := readPackets(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(packets) != 1 || packets[0].tag != tagSignature {
+		return nil, ErrMalformed
+	}
+
+	return parseSignature(packets[0].body)
+}
+
+// Hash writer of a binary document
+func binaryData(message []byte) hashWriter {
+	return func(h hash.Hash) {
+		h.Write(message)
+	}
+}
+
+// Hash writer of a text document with line endings canonicalized to CRLF
+func textData(message []byte) hashWriter {
+	return func(h hash.Hash) {
+		normalized := bytes.ReplaceAll(message, []byte("\r\n"), []byte("\n"))
+		h.Write(bytes.ReplaceAll(normalized, []byte("\n"), []byte("\r\n")))
+	}
+}
+
+// Create a detached signature packet over `message`
+//
+// With `text` set the message is signed as a canonical text document.
+func SignDetached(k *Key, message []byte, text bool) ([]byte, error) {
+	sigType, data := byte(SigTypeBinary), binaryData(message)
+	if text {
+		sigType, data = SigTypeText, textData(message)
+	}
+
+	s, err := k.sign(sigType, time.Now(), data, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Serialize(), nil
+}
+
+// Verify a detached signature packet against the trusted keys
+//
+// Returns the parsed signature and the key that made it.
+func VerifyDetached(keys []*Key, message, signature []byte) (*Signature, *Key, error) {
+	s, err := ParseSignature(signature)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var data hashWriter
+
+	switch s.Type {
+	case SigTypeBinary:
+		data = binaryData(message)
+	case SigTypeText:
+		data = textData(message)
+	default:
+		return nil, nil, ErrMalformed
+	}
+
+	for _, k := range keys {
+		if !bytes.Equal(k.Fingerprint(), s.IssuerFingerprint) {
+			continue
+		}
+
+		if err := s.verify(k, data); err != nil {
+			return nil, nil, err
+		}
+
+		return s, k, nil
+	}
+
+	return nil, nil, ErrUnknownIssuer
+}
@@ -0,0 +1,325 @@
+// Package signedconfig loads configuration files only after verifying an
+// SLH-DSA signature over their exact bytes.
+//
+// The signature is a PEM block, either in a detached file next to the
+// configuration (config.yaml.sig) or embedded at the end of the file:
+//
+//	-----BEGIN SLH-DSA SIGNATURE-----
+//	Key-Id: <hex SHA-256 of the public key>
+//	Parameter-Set: SLH-DSA-SHA2-128s
+//
+//	<base64 signature>
+//	-----END SLH-DSA SIGNATURE-----
+//
+// Embedded blocks may have every line prefixed, typically with "# ", so
+// YAML and TOML files stay parseable by other tools. The signed content is
+// everything before the block, and only that content is returned.
+//
+// Signatures are made with an SLH-DSA context string naming the kind of
+// file, such as "config:v1". The verifier supplies the context itself, so a
+// signature made for another purpose never verifies as a configuration.
+package signedconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// PEM type of signature blocks
+const BlockType = "SLH-DSA SIGNATURE"
+
+// Suffix of detached signature files
+const SignatureSuffix = ".sig"
+
+// Poll interval of Watch when none is given
+const DefaultWatchInterval = 5 * time.Second
+
+var (
+	ErrNoContext        = errors.New("signedconfig: context string is required")
+	ErrNoSignature      = errors.New("signedconfig: no signature block")
+	ErrMalformed        = errors.New("signedconfig: malformed signature block")
+	ErrUnknownKey       = errors.New("signedconfig: signed by an untrusted key")
+	ErrInvalidSignature = errors.New("signedconfig: invalid signature")
+	ErrNoCallback       = errors.New("signedconfig: onLoad callback is required")
+)
+
+// Signs configuration files
+type Signer struct {
+	ParameterSet string
+	PrivateKey   slhdsa.PrivateKey
+
+	// SLH-DSA context string, such as "config:v1"
+	Context string
+}
+
+// Detached signature block over the content
+func (s *Signer) Sign(content []byte) ([]byte, error) {
+	if s.Context == "" {
+		return nil, ErrNoContext
+	}
+
+	ctx, err := slhdsa.New(s.ParameterSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := ctx.GenerateSignature(s.PrivateKey, content, []byte(s.Context), true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := slhdsa.PublicKeyFromPrivateKey(s.ParameterSet, s.PrivateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type:    BlockType,
+		Headers: map[string]string{"Key-Id": slhdsa.KeyID(pk), "Parameter-Set": s.ParameterSet},
+		Bytes:   sig,
+	}), nil
+}
+
+// Content followed by an embedded signature block with every line
+// prefixed by `prefix`, a newline is added to content lacking one
+func (s *Signer) Embed(content []byte, prefix string) ([]byte, error) {
+	if strings.ContainsAny(prefix, "\r\n") {
+		return nil, ErrMalformed
+	}
+
+	content = bytes.Clone(content)
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+
+	block, err := s.Sign(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := content
+
+	for _, line := range strings.SplitAfter(string(block), "\n") {
+		if line != "" {
+			out = append(append(out, prefix...), line...)
+		}
+	}
+
+	return out, nil
+}
+
+// Split an embedded signature block off the content, the block must end
+// the file
+func splitEmbedded(data []byte) ([]byte, []byte, bool) {
+	begin := []byte("-----BEGIN " + BlockType + "-----")
+	i := bytes.LastIndex(data, begin)
+
+	if i < 0 {
+		return nil, nil, false
+	}
+
+	lineStart := bytes.LastIndexByte(data[:i], '\n') + 1
+	prefix := string(data[lineStart:i])
+
+	var block []byte
+
+	for _, line := range strings.SplitAfter(string(data[lineStart:]), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(line, prefix)
+
+		if !ok {
+			return nil, nil, false
+		}
+
+		block = append(block, rest...)
+	}
+
+	if b, rest := pem.Decode(block); b == nil || len(bytes.TrimSpace(rest)) != 0 {
+		return nil, nil, false
+	}
+
+	return data[:lineStart], block, true
+}
+
+// Verifies configuration against a trust set
+type Loader struct {
+	Keys []slhdsa.TrustedKey
+
+	// SLH-DSA context string the signatures must have been made with
+	Context string
+}
+
+// Verify a signature block over the content
+func (l *Loader) Verify(content, signature []byte) error {
+	if l.Context == "" {
+		return ErrNoContext
+	}
+
+	b, rest := pem.Decode(signature)
+
+	if b == nil || b.Type != BlockType || len(bytes.TrimSpace(rest)) != 0 {
+		return ErrMalformed
+	}
+
+	keyID, paramSet := b.Headers["Key-Id"], b.Headers["Parameter-Set"]
+
+	for _, k := range l.Keys {
+		if k.ParameterSet != paramSet || slhdsa.KeyID(k.PublicKey) != keyID {
+			continue
+		}
+
+		ctx, err := slhdsa.New(k.ParameterSet)
+
+		if err != nil {
+			return err
+		}
+
+		ok, err := ctx.VerifySignature(k.PublicKey, content, b.Bytes, []byte(l.Context), slhdsa.PreHashAlgorithm.Pure)
+
+		if err != nil || !ok {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	}
+
+	return ErrUnknownKey
+}
+
+// Read the file, with its detached signature file unless the signature is
+// embedded
+func read(path string) ([]byte, []byte, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if content, block, ok := splitEmbedded(data); ok {
+		return content, block, nil
+	}
+
+	sig, err := os.ReadFile(path + SignatureSuffix)
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, ErrNoSignature
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, sig, nil
+}
+
+// Read and verify a configuration file, returns the signed content
+func (l *Loader) Load(path string) ([]byte, error) {
+	content, sig, err := read(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.Verify(content, sig); err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// Watch a configuration file for new versions
+//
+// The file is loaded first, an error there is returned at once. Then the
+// file and its detached signature are polled every interval, or
+// DefaultWatchInterval when it is not positive, each new version is
+// verified and passed to onLoad, or its error to onError while the
+// previous version stays in effect. A file replaced before its signature
+// is reported once and loaded when the signature catches up. onLoad is
+// required, onError may be nil. Returns the context error when ctx is done.
+//
+// There is no rollback protection: an older version that still carries a
+// valid signature is loaded like any other change.
+func (l *Loader) Watch(ctx context.Context, path string, interval time.Duration, onLoad func([]byte), onError func(error)) error {
+	if onLoad == nil {
+		return ErrNoCallback
+	}
+
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	// Taken before loading, a change in between is loaded on the next poll
+	last := fingerprint(path)
+	content, err := l.Load(path)
+
+	if err != nil {
+		return err
+	}
+
+	onLoad(content)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+
+		fp := fingerprint(path)
+
+		if fp == last {
+			continue
+		}
+
+		last = fp
+		content, err := l.Load(path)
+
+		if err != nil {
+			if onError != nil {
+				onError(err)
+			}
+
+			continue
+		}
+
+		onLoad(content)
+	}
+}
+
+// Hash of the file and detached signature, zero for a missing file
+func fingerprint(path string) [sha256.Size]byte {
+	h := sha256.New()
+
+	for _, name := range []string{path, path + SignatureSuffix} {
+		data, err := os.ReadFile(name)
+
+		if err != nil {
+			h.Write([]byte{0})
+			continue
+		}
+
+		d := sha256.Sum256(data)
+		h.Write([]byte{1})
+		h.Write(d[:])
+	}
+
+	return [sha256.Size]byte(h.Sum(nil))
+}
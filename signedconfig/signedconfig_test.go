@@ -0,0 +1,209 @@
+package signedconfig_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+	"github.com/skuuzie/go-slhdsa/signedconfig"
+)
+
+const config = "listen: :8080\nupstream: https://backend.internal\n"
+
+func newSigner(t *testing.T, purpose string) (*signedconfig.Signer, slhdsa.TrustedKey) {
+	paramSet := slhdsa.ParameterSet.SLHDSA_SHA2_128f
+	sk, pk := testkey.New(t, paramSet)
+
+	return &signedconfig.Signer{ParameterSet: paramSet, PrivateKey: sk, Context: purpose}, slhdsa.TrustedKey{ParameterSet: paramSet, PublicKey: pk}
+}
+
+// Replace the file atomically, as deployment tools do
+func writeFile(t *testing.T, path string, data []byte) {
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Rename(path+".tmp", path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetached(t *testing.T) {
+	s, key := newSigner(t, "config:v1")
+	l := &signedconfig.Loader{Keys: []slhdsa.TrustedKey{key}, Context: "config:v1"}
+	path := filepath.Join(t.TempDir(), "agent.yaml")
+
+	writeFile(t, path, []byte(config))
+
+	if _, err := l.Load(path); !errors.Is(err, signedconfig.ErrNoSignature) {
+		t.Errorf("unsigned: %v", err)
+	}
+
+	sig, err := s.Sign([]byte(config))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, path+signedconfig.SignatureSuffix, sig)
+
+	content, err := l.Load(path)
+
+	if err != nil || string(content) != config {
+		t.Fatalf("load: %q %v", content, err)
+	}
+
+	writeFile(t, path, []byte(strings.Replace(config, "8080", "8081", 1)))
+
+	if _, err := l.Load(path); !errors.Is(err, signedconfig.ErrInvalidSignature) {
+		t.Errorf("tampered: %v", err)
+	}
+
+	// The context string binds the signature to its purpose
+	other := &signedconfig.Loader{Keys: l.Keys, Context: "policy:v1"}
+
+	if err := other.Verify([]byte(config), sig); !errors.Is(err, signedconfig.ErrInvalidSignature) {
+		t.Errorf("other context: %v", err)
+	}
+
+	if err := (&signedconfig.Loader{Keys: l.Keys}).Verify([]byte(config), sig); !errors.Is(err, signedconfig.ErrNoContext) {
+		t.Errorf("no context: %v", err)
+	}
+
+	_, untrusted := newSigner(t, "config:v1")
+
+	if err := (&signedconfig.Loader{Keys: []slhdsa.TrustedKey{untrusted}, Context: "config:v1"}).Verify([]byte(config), sig); !errors.Is(err, signedconfig.ErrUnknownKey) {
+		t.Errorf("untrusted key: %v", err)
+	}
+
+	if _, err := (&signedconfig.Signer{ParameterSet: s.ParameterSet, PrivateKey: s.PrivateKey}).Sign([]byte(config)); !errors.Is(err, signedconfig.ErrNoContext) {
+		t.Errorf("signing without context: %v", err)
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	s, key := newSigner(t, "config:v1")
+	l := &signedconfig.Loader{Keys: []slhdsa.TrustedKey{key}, Context: "config:v1"}
+	dir := t.TempDir()
+
+	for _, prefix := range []string{"# ", ""} {
+		signed, err := s.Embed([]byte(strings.TrimSuffix(config, "\n")), prefix)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(string(signed), "\n"+prefix+"-----BEGIN SLH-DSA SIGNATURE-----\n") {
+			t.Errorf("unexpected embedded block\n%s", signed)
+		}
+
+		path := filepath.Join(dir, "agent.toml")
+		writeFile(t, path, signed)
+
+		content, err := l.Load(path)
+
+		if err != nil || string(content) != config {
+			t.Errorf("prefix %q: %q %v", prefix, content, err)
+		}
+
+		// Content after the block is not covered
+		writeFile(t, path, append(signed, "debug = true\n"...))
+
+		if _, err := l.Load(path); !errors.Is(err, signedconfig.ErrNoSignature) {
+			t.Errorf("prefix %q, trailing content: %v", prefix, err)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	s, key := newSigner(t, "config:v1")
+	l := &signedconfig.Loader{Keys: []slhdsa.TrustedKey{key}, Context: "config:v1"}
+	path := filepath.Join(t.TempDir(), "agent.json")
+
+	write := func(content string, sign bool) {
+		if sign {
+			signed, err := s.Embed([]byte(content), "")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			writeFile(t, path, signed)
+		} else {
+			writeFile(t, path, []byte(content))
+		}
+	}
+
+	write(`{"v":1}`, true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	loaded := make(chan string, 10)
+	failed := make(chan error, 10)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- l.Watch(ctx, path, 5*time.Millisecond, func(b []byte) { loaded <- string(b) }, func(err error) { failed <- err })
+	}()
+
+	next := func() (string, error) {
+		select {
+		case v := <-loaded:
+			return v, nil
+		case err := <-failed:
+			return "", err
+		case <-time.After(5 * time.Second):
+			t.Fatal("no reload")
+		}
+
+		return "", nil
+	}
+
+	if v, err := next(); v != "{\"v\":1}\n" {
+		t.Fatalf("initial load %q %v", v, err)
+	}
+
+	write(`{"v":2}`, false)
+
+	if _, err := next(); !errors.Is(err, signedconfig.ErrNoSignature) {
+		t.Errorf("unsigned version: %v", err)
+	}
+
+	write(`{"v":3}`, true)
+
+	if v, err := next(); v != "{\"v\":3}\n" {
+		t.Errorf("signed version %q %v", v, err)
+	}
+
+	cancel()
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("watch returned %v", err)
+	}
+
+	// An initial version failing verification stops the watch at once
+	write(`{"v":4}`, false)
+
+	if err := l.Watch(context.Background(), path, time.Second, func([]byte) {}, nil); !errors.Is(err, signedconfig.ErrNoSignature) {
+		t.Errorf("unsigned initial version: %v", err)
+	}
+
+	// A zero interval falls back to the default instead of panicking
+	write(`{"v":5}`, true)
+	cancel()
+
+	if err := l.Watch(ctx, path, 0, func([]byte) {}, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("zero interval: %v", err)
+	}
+
+	if err := l.Watch(ctx, path, time.Second, nil, nil); !errors.Is(err, signedconfig.ErrNoCallback) {
+		t.Errorf("nil onLoad: %v", err)
+	}
+}
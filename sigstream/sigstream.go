@@ -0,0 +1,500 @@
+// Package sigstream authenticates long-lived byte streams, such as audit
+// logs, with few SLH-DSA signatures.
+//
+// The writer cuts the stream into chunks chained by hash and signs the
+// chain head every N chunks or T seconds, so one large signature covers
+// many chunks. The reader releases data only once a signature covering it
+// has verified, and detects truncation, reordering and gaps.
+//
+// A stream is a header followed by frames, integers big-endian:
+//
+//	header:    "SLHS" || 0x01 || len(parameter set) || parameter set || stream ID (16) || SHA-256(public key)
+//	data:      'D' || sequence (8) || length (4) || chunk
+//	signature: 'S' or 'F' || chunk count (8) || length (4) || signature
+//
+// The chain starts at h = SHA-256("slhdsa stream v1\x00" || header) and each
+// chunk advances it to h = SHA-256(h || sequence || chunk). Signatures are
+// over type || chunk count || h, and the final signature frame 'F' ends
+// the stream.
+package sigstream
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+const (
+	magic       = "SLHS\x01"
+	chainDomain = "slhdsa stream v1\x00"
+
+	frameData  = 'D'
+	frameSign  = 'S'
+	frameFinal = 'F'
+)
+
+// Defaults of the writer options
+const (
+	DefaultChunkSize = 64 << 10
+	DefaultSignEvery = 64
+)
+
+// Largest chunk accepted by readers
+const MaxChunkSize = 16 << 20
+
+var (
+	ErrMalformed        = errors.New("sigstream: malformed stream")
+	ErrUnknownKey       = errors.New("sigstream: stream signed by an untrusted key")
+	ErrInvalidSignature = errors.New("sigstream: invalid signature")
+	ErrGap              = errors.New("sigstream: chunks missing from the stream")
+	ErrReordered        = errors.New("sigstream: chunks replayed or out of order")
+	ErrTruncated        = errors.New("sigstream: stream ends before its final signature")
+	ErrPendingLimit     = errors.New("sigstream: too much data without a signature")
+	ErrClosed           = errors.New("sigstream: writer closed")
+)
+
+// Chain state shared by writer and reader
+type chain struct {
+	head  [sha256.Size]byte
+	count uint64
+}
+
+func newChain(header []byte) chain {
+	return chain{head: sha256.Sum256(append([]byte(chainDomain), header...))}
+}
+
+func (c *chain) add(chunk []byte) {
+	h := sha256.New()
+	h.Write(c.head[:])
+	h.Write(binary.BigEndian.AppendUint64(nil, c.count))
+	h.Write(chunk)
+	h.Sum(c.head[:0])
+	c.count++
+}
+
+// Message signed by a signature frame
+func (c *chain) message(typ byte) []byte {
+	msg := binary.BigEndian.AppendUint64([]byte{typ}, c.count)
+
+	return append(msg, c.head[:]...)
+}
+
+// Writer options
+type Options struct {
+	ParameterSet string
+	PrivateKey   slhdsa.PrivateKey
+
+	// Largest chunk, DefaultChunkSize when zero
+	ChunkSize int
+
+	// Chunks per signature, DefaultSignEvery when zero
+	SignEvery int
+
+	// Longest time data stays unsigned, no limit when zero
+	SignInterval time.Duration
+}
+
+// Signed stream writer
+//
+// Data is cut into chunks as it is written. Signatures are emitted after
+// every SignEvery chunks, SignInterval after data first waits unsigned, on
+// Flush and on Close.
+type Writer struct {
+	mu    sync.Mutex
+	w     io.Writer
+	opts  Options
+	chain chain
+
+	buf      []byte
+	unsigned int
+	timer    *time.Timer
+	err      error
+	closed   bool
+}
+
+// Start a signed stream on w, writing the header
+func NewWriter(w io.Writer, opts Options) (*Writer, error) {
+	pk, err := slhdsa.PublicKeyFromPrivateKey(opts.ParameterSet, opts.PrivateKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultChunkSize
+	}
+
+	if opts.ChunkSize > MaxChunkSize {
+		opts.ChunkSize = MaxChunkSize
+	}
+
+	if opts.SignEvery <= 0 {
+		opts.SignEvery = DefaultSignEvery
+	}
+
+	var streamID [16]byte
+
+	if _, err := rand.Read(streamID[:]); err != nil {
+		return nil, err
+	}
+
+	keyID := sha256.Sum256(pk.KeyBytes)
+
+	header := append([]byte(magic), byte(len(opts.ParameterSet)))
+	header = append(header, opts.ParameterSet...)
+	header = append(header, streamID[:]...)
+	header = append(header, keyID[:]...)
+
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w: w, opts: opts, chain: newChain(header)}, nil
+}
+
+// Write data to the stream
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.check(); err != nil {
+		return 0, err
+	}
+
+	n := len(p)
+
+	for len(p) > 0 {
+		k := min(len(p), w.opts.ChunkSize-len(w.buf))
+		w.buf = append(w.buf, p[:k]...)
+		p = p[k:]
+
+		if len(w.buf) == w.opts.ChunkSize {
+			if err := w.emitChunk(); err != nil {
+				return n - len(p), err
+			}
+		}
+	}
+
+	w.startTimer()
+
+	return n, nil
+}
+
+func (w *Writer) check() error {
+	if w.closed {
+		return ErrClosed
+	}
+
+	return w.err
+}
+
+// Emit the buffered chunk, signing when SignEvery chunks are unsigned
+func (w *Writer) emitChunk() error {
+	frame := []byte{frameData}
+	frame = binary.BigEndian.AppendUint64(frame, w.chain.count)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(w.buf)))
+
+	if _, err := w.w.Write(append(frame, w.buf...)); err != nil {
+		w.err = err
+		return err
+	}
+
+	w.chain.add(w.buf)
+	w.buf = w.buf[:0]
+	w.unsigned++
+
+	if w.unsigned >= w.opts.SignEvery {
+		return w.sign(frameSign)
+	}
+
+	return nil
+}
+
+func (w *Writer) sign(typ byte) error {
+	ctx, err := slhdsa.New(w.opts.ParameterSet)
+
+	if err != nil {
+		w.err = err
+		return err
+	}
+
+	sig, err := ctx.GenerateSignature(w.opts.PrivateKey, w.chain.message(typ), nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		w.err = err
+		return err
+	}
+
+	frame := []byte{typ}
+	frame = binary.BigEndian.AppendUint64(frame, w.chain.count)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(sig)))
+
+	if _, err := w.w.Write(append(frame, sig...)); err != nil {
+		w.err = err
+		return err
+	}
+
+	w.unsigned = 0
+
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+
+	return nil
+}
+
+// Arm the signing timer when data waits unsigned
+func (w *Writer) startTimer() {
+	if w.opts.SignInterval <= 0 || w.timer != nil || (w.unsigned == 0 && len(w.buf) == 0) {
+		return
+	}
+
+	var t *time.Timer
+
+	t = time.AfterFunc(w.opts.SignInterval, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		// Stopped or replaced after firing
+		if w.timer != t {
+			return
+		}
+
+		w.timer = nil
+
+		if w.check() == nil {
+			w.flush()
+		}
+	})
+
+	w.timer = t
+}
+
+func (w *Writer) flush() error {
+	if len(w.buf) > 0 {
+		if err := w.emitChunk(); err != nil {
+			return err
+		}
+	}
+
+	if w.unsigned > 0 {
+		return w.sign(frameSign)
+	}
+
+	return nil
+}
+
+// Emit buffered data as a chunk and sign everything written so far
+//
+// Errors of signatures made by the SignInterval timer are reported here
+// and by the following Write and Close calls.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.check(); err != nil {
+		return err
+	}
+
+	return w.flush()
+}
+
+// Emit buffered data and the final signature, the underlying writer is not
+// closed
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.check(); err != nil {
+		return err
+	}
+
+	w.closed = true
+
+	if len(w.buf) > 0 {
+		if err := w.emitChunk(); err != nil {
+			return err
+		}
+	}
+
+	return w.sign(frameFinal)
+}
+
+// Signed stream reader
+//
+// Read returns only data covered by a verified signature. A stream ending
+// without its final signature fails with ErrTruncated once the signed data
+// has been read, and unsigned data at its end is never returned.
+type Reader struct {
+	// Largest amount of unsigned data buffered while waiting for a
+	// signature, 64 MiB when zero
+	MaxPending int
+
+	r     io.Reader
+	key   slhdsa.TrustedKey
+	chain chain
+
+	pending  [][]byte
+	pendingN int
+	ready    [][]byte
+	err      error
+	done     bool
+}
+
+// Start reading a signed stream, the header must name a trusted key
+func NewReader(r io.Reader, keys []slhdsa.TrustedKey) (*Reader, error) {
+	fixed := make([]byte, len(magic)+1)
+
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, ErrMalformed
+	}
+
+	if string(fixed[:len(magic)]) != magic {
+		return nil, ErrMalformed
+	}
+
+	rest := make([]byte, int(fixed[len(magic)])+16+sha256.Size)
+
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, ErrMalformed
+	}
+
+	paramSet := string(rest[:len(rest)-16-sha256.Size])
+	keyID := hex.EncodeToString(rest[len(rest)-sha256.Size:])
+
+	for _, k := range keys {
+		if k.ParameterSet != paramSet || slhdsa.KeyID(k.PublicKey) != keyID {
+			continue
+		}
+
+		if _, err := slhdsa.New(paramSet); err != nil {
+			return nil, err
+		}
+
+		return &Reader{r: r, key: k, chain: newChain(append(fixed, rest...))}, nil
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// Read verified stream data
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.ready) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		if r.done {
+			return 0, io.EOF
+		}
+
+		r.err = r.next()
+	}
+
+	n := copy(p, r.ready[0])
+
+	if r.ready[0] = r.ready[0][n:]; len(r.ready[0]) == 0 {
+		r.ready = r.ready[1:]
+	}
+
+	return n, nil
+}
+
+// Process one frame
+func (r *Reader) next() error {
+	var hdr [13]byte
+
+	if _, err := io.ReadFull(r.r, hdr[:1]); err != nil {
+		if err == io.EOF {
+			return ErrTruncated
+		}
+
+		return err
+	}
+
+	if _, err := io.ReadFull(r.r, hdr[1:]); err != nil {
+		return ErrTruncated
+	}
+
+	seq := binary.BigEndian.Uint64(hdr[1:])
+	n := binary.BigEndian.Uint32(hdr[9:])
+
+	switch hdr[0] {
+	case frameData, frameSign, frameFinal:
+	default:
+		return ErrMalformed
+	}
+
+	if seq < r.chain.count {
+		return ErrReordered
+	}
+
+	if seq > r.chain.count {
+		return ErrGap
+	}
+
+	if n > MaxChunkSize {
+		return ErrMalformed
+	}
+
+	maxPending := r.MaxPending
+	if maxPending <= 0 {
+		maxPending = 64 << 20
+	}
+
+	if hdr[0] == frameData && r.pendingN+int(n) > maxPending {
+		return ErrPendingLimit
+	}
+
+	body := make([]byte, n)
+
+	if _, err := io.ReadFull(r.r, body); err != nil {
+		return ErrTruncated
+	}
+
+	if hdr[0] == frameData {
+		if n == 0 {
+			return ErrMalformed
+		}
+
+		r.chain.add(body)
+		r.pending = append(r.pending, body)
+		r.pendingN += len(body)
+
+		return nil
+	}
+
+	ctx, err := slhdsa.New(r.key.ParameterSet)
+
+	if err != nil {
+		return err
+	}
+
+	ok, err := ctx.VerifySignature(r.key.PublicKey, r.chain.message(hdr[0]), body, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil || !ok {
+		return ErrInvalidSignature
+	}
+
+	r.ready = append(r.ready, r.pending...)
+	r.pending, r.pendingN = nil, 0
+
+	if hdr[0] == frameFinal {
+		r.done = true
+
+		// Nothing may follow the final signature
+		if n, _ := r.r.Read(hdr[:1]); n != 0 {
+			return ErrMalformed
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package sigstream_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+	"github.com/skuuzie/go-slhdsa/sigstream"
+)
+
+const paramSet = "SLH-DSA-SHA2-128f"
+
+func newKey(t *testing.T) (slhdsa.PrivateKey, slhdsa.TrustedKey) {
+	sk, pk := testkey.New(t, paramSet)
+
+	return sk, slhdsa.TrustedKey{ParameterSet: paramSet, PublicKey: pk}
+}
+
+// Stream of ten 4-byte chunks signed every 4 chunks, and its frames
+func writeStream(t *testing.T, sk slhdsa.PrivateKey) ([]byte, [][]byte) {
+	var buf bytes.Buffer
+
+	w, err := sigstream.NewWriter(&buf, sigstream.Options{ParameterSet: paramSet, PrivateKey: sk, ChunkSize: 4, SignEvery: 4})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 10 {
+		if _, err := io.WriteString(w, strings.Repeat(string(rune('a'+i)), 4)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte("x")); !errors.Is(err, sigstream.ErrClosed) {
+		t.Errorf("write after close: %v", err)
+	}
+
+	return buf.Bytes(), splitFrames(buf.Bytes())
+}
+
+// Header and frames of a stream
+func splitFrames(data []byte) [][]byte {
+	n := 5 + 1 + int(data[5]) + 16 + 32
+	frames := [][]byte{data[:n]}
+
+	for rest := data[n:]; len(rest) > 0; {
+		size := 13 + int(binary.BigEndian.Uint32(rest[9:]))
+		frames = append(frames, rest[:size])
+		rest = rest[size:]
+	}
+
+	return frames
+}
+
+func readAll(t *testing.T, data []byte, key slhdsa.TrustedKey) (string, error) {
+	r, err := sigstream.NewReader(bytes.NewReader(data), []slhdsa.TrustedKey{key})
+
+	if err != nil {
+		return "", err
+	}
+
+	out, err := io.ReadAll(r)
+
+	return string(out), err
+}
+
+func TestRoundTrip(t *testing.T) {
+	sk, key := newKey(t)
+	data, frames := writeStream(t, sk)
+
+	// Header, 10 data frames, signatures after chunks 4 and 8, final signature
+	if len(frames) != 14 || frames[5][0] != 'S' || frames[10][0] != 'S' || frames[13][0] != 'F' {
+		t.Fatalf("unexpected frame layout, %d frames", len(frames))
+	}
+
+	out, err := readAll(t, data, key)
+
+	if err != nil || out != "aaaabbbbccccddddeeeeffffgggghhhhiiiijjjj" {
+		t.Errorf("read %q %v", out, err)
+	}
+
+	_, other := newKey(t)
+
+	if _, err := readAll(t, data, other); !errors.Is(err, sigstream.ErrUnknownKey) {
+		t.Errorf("untrusted key: %v", err)
+	}
+}
+
+func TestTampering(t *testing.T) {
+	sk, key := newKey(t)
+	data, frames := writeStream(t, sk)
+
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	flipped := bytes.Clone(data)
+	flipped[len(frames[0])+13] ^= 1
+
+	for _, tc := range []struct {
+		name   string
+		stream []byte
+		want   error
+		signed string
+	}{
+		// Only the chunks before the last signature are released
+		{"truncated", join(frames[:9]...), sigstream.ErrTruncated, "aaaabbbbccccdddd"},
+		{"truncated mid-frame", data[:len(data)-10], sigstream.ErrTruncated, "aaaabbbbccccddddeeeeffffgggghhhh"},
+		{"reordered", join(frames[0], frames[2], frames[1]), sigstream.ErrGap, ""},
+		{"replayed", join(frames[0], frames[1], frames[2], frames[2]), sigstream.ErrReordered, ""},
+		{"gap", join(frames[0], frames[1], frames[3]), sigstream.ErrGap, ""},
+		{"modified", flipped, sigstream.ErrInvalidSignature, ""},
+		{"trailing data", append(bytes.Clone(data), frames[1]...), sigstream.ErrMalformed, "aaaabbbbccccddddeeeeffffgggghhhhiiiijjjj"},
+	} {
+		out, err := readAll(t, tc.stream, key)
+
+		if !errors.Is(err, tc.want) || out != tc.signed {
+			t.Errorf("%s: read %q, %v", tc.name, out, err)
+		}
+	}
+
+	// Dropping whole signed sections breaks the chain
+	if out, err := readAll(t, join(append(frames[:1:1], frames[6:]...)...), key); !errors.Is(err, sigstream.ErrGap) || out != "" {
+		t.Errorf("dropped section: read %q, %v", out, err)
+	}
+}
+
+// Buffer safe for the writer's timer goroutine
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return bytes.Clone(b.buf.Bytes())
+}
+
+func TestSignInterval(t *testing.T) {
+	sk, key := newKey(t)
+
+	var buf syncBuffer
+
+	w, err := sigstream.NewWriter(&buf, sigstream.Options{ParameterSet: paramSet, PrivateKey: sk, SignInterval: 20 * time.Millisecond})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	io.WriteString(w, "audit record\n")
+
+	// The partial chunk is emitted and signed without further writes
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		frames := splitFrames(buf.Bytes())
+
+		if len(frames) == 3 && frames[2][0] == 'S' {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("no signature after the interval")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	out, err := readAll(t, buf.Bytes(), key)
+
+	if !errors.Is(err, sigstream.ErrTruncated) || out != "audit record\n" {
+		t.Errorf("read %q, %v", out, err)
+	}
+
+	io.WriteString(w, "second record\n")
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := readAll(t, buf.Bytes(), key); err != nil || out != "audit record\nsecond record\n" {
+		t.Errorf("read %q, %v", out, err)
+	}
+}
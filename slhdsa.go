@@ -1,6 +1,8 @@
 package slhdsa
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"reflect"
 
@@ -40,6 +42,12 @@ var PreHashAlgorithm = slhdsa.PreHashAlgorithms{
 	SHAKE256:   "SHAKE-256",
 }
 
+// SLH-DSA Public Key
+type PublicKey = slhdsa.PublicKey
+
+// SLH-DSA Private/Secret Key
+type PrivateKey = slhdsa.PrivateKey
+
 type iSLHDSA interface {
 	// Generate crypto-secure random SLH-DSA Private and Public key
 	//
@@ -89,3 +97,31 @@ func New(paramSet string) (iSLHDSA, error) {
 
 	return slhdsa.NewSlhDsa(paramSet)
 }
+
+// Extract the SLH-DSA public key embedded in a private key
+func PublicKeyFromPrivateKey(paramSet string, sk PrivateKey) (PublicKey, error) {
+	ctx, err := New(paramSet)
+
+	if err != nil {
+		return PublicKey{}, err
+	}
+
+	if _, err := ctx.GetPrivateKeyFromBytes(sk.KeyBytes); err != nil {
+		return PublicKey{}, err
+	}
+
+	return ctx.GetPublicKeyFromBytes(sk.KeyBytes[len(sk.KeyBytes)/2:])
+}
+
+// Public key and the parameter set it belongs to
+type TrustedKey struct {
+	ParameterSet string
+	PublicKey    PublicKey
+}
+
+// Key ID of a public key, hex SHA-256 of the key bytes
+func KeyID(pk PublicKey) string {
+	h := sha256.Sum256(pk.KeyBytes)
+
+	return hex.EncodeToString(h[:])
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
 
 	slhdsa "github.com/skuuzie/go-slhdsa"
@@ -310,3 +311,53 @@ func TestSigVer(t *testing.T) {
 
 	sigVerPassed = true
 }
+
+func TestPublicKeyFromPrivateKey(t *testing.T) {
+	val := reflect.ValueOf(slhdsa.ParameterSet)
+
+	for i := range val.NumField() {
+		paramSet := val.Field(i).String()
+		ctx, err := slhdsa.New(paramSet)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sk, pk, err := ctx.GenerateKeyPair()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := slhdsa.PublicKeyFromPrivateKey(paramSet, sk)
+
+		if err != nil || !bytes.Equal(got.KeyBytes, pk.KeyBytes) {
+			t.Errorf("%v: expected %x, got %x (%v)", paramSet, pk.KeyBytes, got.KeyBytes, err)
+		}
+
+		short := slhdsa.PrivateKey{KeyBytes: sk.KeyBytes[:len(sk.KeyBytes)-1]}
+
+		if _, err := slhdsa.PublicKeyFromPrivateKey(paramSet, short); err == nil {
+			t.Errorf("%v: accepted a truncated private key", paramSet)
+		}
+
+		long := slhdsa.PrivateKey{KeyBytes: append(bytes.Clone(sk.KeyBytes), 0)}
+
+		if _, err := slhdsa.PublicKeyFromPrivateKey(paramSet, long); err == nil {
+			t.Errorf("%v: accepted an oversized private key", paramSet)
+		}
+	}
+
+	if _, err := slhdsa.PublicKeyFromPrivateKey("SLH-DSA-SHA2-512s", slhdsa.PrivateKey{}); err == nil {
+		t.Error("accepted an unknown parameter set")
+	}
+}
+
+func TestKeyID(t *testing.T) {
+	pk := slhdsa.PublicKey{KeyBytes: []byte("abc")}
+
+	// SHA-256 of "abc"
+	if id := slhdsa.KeyID(pk); id != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("unexpected key ID %s", id)
+	}
+}
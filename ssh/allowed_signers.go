@@ -0,0 +1,201 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrSignerNotAllowed = errors.New("ssh: signer is not in allowed_signers")
+
+// Entry of an allowed_signers file (ssh-keygen(1) ALLOWED SIGNERS)
+type AllowedSigner struct {
+	// Principal patterns, may contain * and ? wildcards
+	Principals []string
+
+	// Namespaces the key may sign for, any namespace when empty
+	Namespaces []string
+
+	// Validity window, unbounded when zero
+	ValidAfter  time.Time
+	ValidBefore time.Time
+
+	// Key is a certificate authority for the principals
+	CertAuthority bool
+
+	Key *PublicKey
+}
+
+// Parsed allowed_signers file
+type AllowedSigners []AllowedSigner
+
+// Parse an allowed_signers file, lines with key types other than SLH-DSA are skipped
+func ParseAllowedSigners(data []byte) (AllowedSigners, error) {
+	var out AllowedSigners
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := splitFields(line)
+		if len(fields) < 3 {
+			return nil, ErrMalformed
+		}
+
+		entry := AllowedSigner{Principals: strings.Split(strings.Trim(fields[0], `"`), ",")}
+
+		key, _, options, err := ParseAuthorizedKey([]byte(strings.Join(fields[1:], " ")))
+
+		if err == ErrUnsupportedKeyType {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Key = key
+
+		if err := entry.parseOptions(options); err != nil {
+			return nil, err
+		}
+
+		out = append(out, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+func (e *AllowedSigner) parseOptions(options string) error {
+	if options == "" {
+		return nil
+	}
+
+	for _, opt := range splitOptions(options) {
+		name, value, _ := strings.Cut(opt, "=")
+		value = strings.Trim(value, `"`)
+
+		var err error
+
+		switch strings.ToLower(name) {
+		case "cert-authority":
+			e.CertAuthority = true
+		case "namespaces":
+			e.Namespaces = strings.Split(value, ",")
+		case "valid-after":
+			e.ValidAfter, err = parseSSHTime(value)
+		case "valid-before":
+			e.ValidBefore, err = parseSSHTime(value)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Split comma separated options outside double quotes
+func splitOptions(s string) []string {
+	var out []string
+	start, quoted := 0, false
+
+	for i, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(out, s[start:])
+}
+
+// Time in the YYYYMMDD[HHMM[SS]][Z] format of ssh-keygen
+func parseSSHTime(s string) (time.Time, error) {
+	loc := time.Local
+
+	if strings.HasSuffix(s, "Z") {
+		s, loc = strings.TrimSuffix(s, "Z"), time.UTC
+	}
+
+	for _, layout := range []string{"20060102150405", "200601021504", "20060102"} {
+		if len(s) == len(layout) {
+			return time.ParseInLocation(layout, s, loc)
+		}
+	}
+
+	return time.Time{}, ErrMalformed
+}
+
+func (e *AllowedSigner) allows(principal, namespace string, now time.Time) bool {
+	if len(e.Namespaces) != 0 && !slices.ContainsFunc(e.Namespaces, func(p string) bool {
+		ok, _ := path.Match(p, namespace)
+		return ok
+	}) {
+		return false
+	}
+
+	if !e.ValidAfter.IsZero() && now.Before(e.ValidAfter) {
+		return false
+	}
+
+	if !e.ValidBefore.IsZero() && !now.Before(e.ValidBefore) {
+		return false
+	}
+
+	return slices.ContainsFunc(e.Principals, func(p string) bool {
+		ok, _ := path.Match(p, principal)
+		return ok
+	})
+}
+
+// Verify a signature as `ssh-keygen -Y verify -I principal -n namespace` does
+func (a AllowedSigners) Verify(principal, namespace string, message []byte, sig *Signature) error {
+	if err := sig.Verify(namespace, message); err != nil {
+		return err
+	}
+
+	fp := sig.PublicKey.Fingerprint()
+	now := time.Now()
+
+	for _, e := range a {
+		if !e.CertAuthority && e.Key.Fingerprint() == fp && e.allows(principal, namespace, now) {
+			return nil
+		}
+	}
+
+	return ErrSignerNotAllowed
+}
+
+// Principals whose allowed_signers entries accept the signature's key, as
+// `ssh-keygen -Y find-principals` reports
+func (a AllowedSigners) FindPrincipals(sig *Signature) []string {
+	var out []string
+	fp := sig.PublicKey.Fingerprint()
+
+	for _, e := range a {
+		if !e.CertAuthority && e.Key.Fingerprint() == fp {
+			out = append(out, e.Principals...)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,343 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Certificate types (OpenSSH PROTOCOL.certkeys)
+const (
+	UserCert = 1
+	HostCert = 2
+)
+
+const certSuffix = "-cert-v01@openssh.com"
+
+// Validity bound meaning "forever"
+const CertTimeInfinity = 1<<64 - 1
+
+var (
+	ErrUntrustedCA         = errors.New("ssh: certificate is not signed by a trusted CA")
+	ErrCertExpired         = errors.New("ssh: certificate is expired")
+	ErrCertNotYetValid     = errors.New("ssh: certificate is not valid yet")
+	ErrPrincipalNotAllowed = errors.New("ssh: principal is not listed in the certificate")
+	ErrWrongCertType       = errors.New("ssh: unexpected certificate type")
+)
+
+// Number of key-specific public fields of the subject key types a certificate may carry
+var subjectKeyFields = map[string]int{
+	"ssh-ed25519":         1,
+	"ecdsa-sha2-nistp256": 2,
+	"ecdsa-sha2-nistp384": 2,
+	"ecdsa-sha2-nistp521": 2,
+	"ssh-rsa":             2,
+}
+
+func keyFieldCount(keyType string) (int, bool) {
+	if _, err := ParameterSet(keyType); err == nil {
+		return 1, true
+	}
+
+	n, ok := subjectKeyFields[keyType]
+
+	return n, ok
+}
+
+// OpenSSH certificate signed by an SLH-DSA CA
+type Certificate struct {
+	Nonce []byte
+
+	// SSH wire encoding of the certified public key, of any supported key type
+	Key []byte
+
+	Serial          uint64
+	CertType        uint32
+	KeyID           string
+	ValidPrincipals []string
+	ValidAfter      uint64
+	ValidBefore     uint64
+
+	// Critical options and extensions, values are the raw option data
+	CriticalOptions map[string]string
+	Extensions      map[string]string
+
+	SignatureKey *PublicKey
+	Signature    []byte
+}
+
+// Key type name of the certificate, e.g. ssh-ed25519-cert-v01@openssh.com
+func (c *Certificate) Type() string {
+	r := reader{data: c.Key}
+	return string(r.string()) + certSuffix
+}
+
+// Pack options as name/data string pairs in lexical order, non-empty data is wrapped in a string
+func marshalOptions(opts map[string]string) []byte {
+	names := make([]string, 0, len(opts))
+	for name := range opts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b []byte
+
+	for _, name := range names {
+		b = appendString(b, []byte(name))
+
+		if v := opts[name]; v != "" {
+			b = appendString(b, appendString(nil, []byte(v)))
+		} else {
+			b = appendString(b, nil)
+		}
+	}
+
+	return b
+}
+
+func parseOptions(data []byte) (map[string]string, error) {
+	opts := map[string]string{}
+	r := reader{data: data}
+	prev := ""
+
+	for len(r.data) > 0 {
+		name := string(r.string())
+		value := r.string()
+
+		if r.err != nil || name <= prev && prev != "" {
+			return nil, ErrMalformed
+		}
+
+		if len(value) > 0 {
+			vr := reader{data: value}
+			inner := vr.string()
+
+			if vr.err != nil || len(vr.data) != 0 {
+				return nil, ErrMalformed
+			}
+
+			value = inner
+		}
+
+		opts[name] = string(value)
+		prev = name
+	}
+
+	return opts, nil
+}
+
+// Certificate body up to and including the signature key
+func (c *Certificate) bytesForSigning() []byte {
+	var b []byte
+
+	kr := reader{data: c.Key}
+	kr.string()
+
+	var principals []byte
+	for _, p := range c.ValidPrincipals {
+		principals = appendString(principals, []byte(p))
+	}
+
+	b = appendString(b, []byte(c.Type()))
+	b = appendString(b, c.Nonce)
+	b = append(b, kr.data...)
+	b = binary.BigEndian.AppendUint64(b, c.Serial)
+	b = appendUint32(b, c.CertType)
+	b = appendString(b, []byte(c.KeyID))
+	b = appendString(b, principals)
+	b = binary.BigEndian.AppendUint64(b, c.ValidAfter)
+	b = binary.BigEndian.AppendUint64(b, c.ValidBefore)
+	b = appendString(b, marshalOptions(c.CriticalOptions))
+	b = appendString(b, marshalOptions(c.Extensions))
+	b = appendString(b, nil)
+	b = appendString(b, c.SignatureKey.Marshal())
+
+	return b
+}
+
+// Sign the certificate with an SLH-DSA CA key, a random nonce is generated if unset
+func (c *Certificate) SignCert(ca *PrivateKey) error {
+	if _, ok := keyFieldCount(strings.TrimSuffix(c.Type(), certSuffix)); !ok {
+		return ErrUnsupportedKeyType
+	}
+
+	caPub, err := ca.Public()
+
+	if err != nil {
+		return err
+	}
+
+	if c.Nonce == nil {
+		c.Nonce = make([]byte, 32)
+		rand.Read(c.Nonce)
+	}
+
+	c.SignatureKey = caPub
+	c.Signature, err = ca.Sign(c.bytesForSigning())
+
+	return err
+}
+
+// SSH wire encoding of the signed certificate
+func (c *Certificate) Marshal() []byte {
+	return appendString(c.bytesForSigning(), c.Signature)
+}
+
+// Format the certificate as a line of an id-cert.pub or known_hosts style file
+func (c *Certificate) MarshalAuthorized(comment string) []byte {
+	line := c.Type() + " " + base64.StdEncoding.EncodeToString(c.Marshal())
+
+	if comment != "" {
+		line += " " + comment
+	}
+
+	return []byte(line + "\n")
+}
+
+// Decode the SSH wire encoding of a certificate signed by an SLH-DSA CA
+func ParseCertificate(blob []byte) (*Certificate, error) {
+	r := reader{data: blob}
+
+	certType := string(r.string())
+	keyType, ok := strings.CutSuffix(certType, certSuffix)
+
+	if !ok {
+		return nil, ErrMalformed
+	}
+
+	fields, ok := keyFieldCount(keyType)
+
+	if !ok {
+		return nil, ErrUnsupportedKeyType
+	}
+
+	c := &Certificate{Nonce: r.string()}
+
+	key := appendString(nil, []byte(keyType))
+	for range fields {
+		key = appendString(key, r.string())
+	}
+	c.Key = key
+
+	c.Serial = r.uint64()
+	c.CertType = r.uint32()
+	c.KeyID = string(r.string())
+	principals := r.string()
+	c.ValidAfter = r.uint64()
+	c.ValidBefore = r.uint64()
+	critical := r.string()
+	extensions := r.string()
+	r.string()
+	caBlob := r.string()
+	c.Signature = r.string()
+
+	if r.err != nil || len(r.data) != 0 {
+		return nil, ErrMalformed
+	}
+
+	pr := reader{data: principals}
+	for len(pr.data) > 0 {
+		p := pr.string()
+
+		if pr.err != nil {
+			return nil, ErrMalformed
+		}
+
+		c.ValidPrincipals = append(c.ValidPrincipals, string(p))
+	}
+
+	var err error
+
+	if c.CriticalOptions, err = parseOptions(critical); err != nil {
+		return nil, err
+	}
+
+	if c.Extensions, err = parseOptions(extensions); err != nil {
+		return nil, err
+	}
+
+	if c.SignatureKey, err = ParsePublicKey(caBlob); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Certificate validation against a set of trusted SLH-DSA CA keys
+type CertChecker struct {
+	TrustedCAs []*PublicKey
+
+	// Expected certificate type, UserCert or HostCert
+	CertType uint32
+
+	// Critical options the caller enforces, certificates with any other critical option are rejected
+	SupportedCriticalOptions []string
+
+	// Validation time, defaults to the current time
+	Now time.Time
+}
+
+// Check the CA signature, type, validity window, principal and critical options of a certificate
+func (cc *CertChecker) CheckCert(principal string, c *Certificate) error {
+	trusted := false
+	fp := c.SignatureKey.Fingerprint()
+
+	for _, ca := range cc.TrustedCAs {
+		if ca.Fingerprint() == fp {
+			trusted = true
+			break
+		}
+	}
+
+	if !trusted {
+		return ErrUntrustedCA
+	}
+
+	if err := c.SignatureKey.Verify(c.bytesForSigning(), c.Signature); err != nil {
+		return err
+	}
+
+	if c.CertType != cc.CertType {
+		return ErrWrongCertType
+	}
+
+	now := cc.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	unix := uint64(now.Unix())
+
+	if unix < c.ValidAfter {
+		return ErrCertNotYetValid
+	}
+
+	if c.ValidBefore != CertTimeInfinity && unix >= c.ValidBefore {
+		return ErrCertExpired
+	}
+
+	// An empty principal list is valid for any principal
+	if len(c.ValidPrincipals) != 0 && !slices.Contains(c.ValidPrincipals, principal) {
+		return ErrPrincipalNotAllowed
+	}
+
+	for name := range c.CriticalOptions {
+		if !slices.Contains(cc.SupportedCriticalOptions, name) {
+			return fmt.Errorf("ssh: unsupported critical option %q", name)
+		}
+	}
+
+	return nil
+}
+
+// Whether the certified key matches the wire encoding of `key`
+func (c *Certificate) CertifiesKey(key []byte) bool {
+	return bytes.Equal(c.Key, key)
+}
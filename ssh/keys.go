@@ -0,0 +1,194 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"strings"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+const privateKeyMagic = "openssh-key-v1\x00"
+
+// Format a public key as an authorized_keys line
+func MarshalAuthorizedKey(k *PublicKey, comment string) []byte {
+	line := k.Type() + " " + base64.StdEncoding.EncodeToString(k.Marshal())
+
+	if comment != "" {
+		line += " " + comment
+	}
+
+	return []byte(line + "\n")
+}
+
+// Parse an authorized_keys line: [options] key-type base64-key [comment]
+//
+// Returns the key, its comment and the raw options field.
+func ParseAuthorizedKey(line []byte) (*PublicKey, string, string, error) {
+	fields := splitFields(strings.TrimSpace(string(line)))
+
+	for i := 0; i+1 < len(fields); i++ {
+		if _, err := ParameterSet(fields[i]); err != nil {
+			continue
+		}
+
+		blob, err := base64.StdEncoding.DecodeString(fields[i+1])
+
+		if err != nil {
+			return nil, "", "", ErrMalformed
+		}
+
+		k, err := ParsePublicKey(blob)
+
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		if k.Type() != fields[i] {
+			return nil, "", "", ErrMalformed
+		}
+
+		return k, strings.Join(fields[i+2:], " "), strings.Join(fields[:i], " "), nil
+	}
+
+	return nil, "", "", ErrUnsupportedKeyType
+}
+
+// Split on whitespace outside double quotes
+func splitFields(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	quoted := false
+
+	for _, c := range s {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			cur.WriteRune(c)
+		case (c == ' ' || c == '\t') && !quoted:
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(c)
+		}
+	}
+
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+
+	return fields
+}
+
+// Encode an unencrypted openssh-key-v1 private key file (OpenSSH PROTOCOL.key)
+func MarshalPrivateKey(k *PrivateKey) ([]byte, error) {
+	pub, err := k.Public()
+
+	if err != nil {
+		return nil, err
+	}
+
+	check := make([]byte, 4)
+	rand.Read(check)
+
+	var priv []byte
+	priv = append(priv, check...)
+	priv = append(priv, check...)
+	priv = appendString(priv, []byte(pub.Type()))
+	priv = appendString(priv, pub.Key.KeyBytes)
+	priv = appendString(priv, k.Key.KeyBytes)
+	priv = appendString(priv, []byte(k.Comment))
+
+	for i := byte(1); len(priv)%8 != 0; i++ {
+		priv = append(priv, i)
+	}
+
+	var b []byte
+	b = append(b, privateKeyMagic...)
+	b = appendString(b, []byte("none"))
+	b = appendString(b, []byte("none"))
+	b = appendString(b, nil)
+	b = appendUint32(b, 1)
+	b = appendString(b, pub.Marshal())
+	b = appendString(b, priv)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: b}), nil
+}
+
+// Decode an unencrypted openssh-key-v1 private key file
+func ParsePrivateKey(data []byte) (*PrivateKey, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil || block.Type != "OPENSSH PRIVATE KEY" {
+		return nil, ErrMalformed
+	}
+
+	if !bytes.HasPrefix(block.Bytes, []byte(privateKeyMagic)) {
+		return nil, ErrMalformed
+	}
+
+	r := reader{data: block.Bytes[len(privateKeyMagic):]}
+
+	cipher, kdf := string(r.string()), string(r.string())
+	r.string()
+	n := r.uint32()
+	pubBlob := r.string()
+	priv := r.string()
+
+	if r.err != nil {
+		return nil, ErrMalformed
+	}
+
+	if cipher != "none" || kdf != "none" {
+		return nil, errors.New("ssh: encrypted private keys are not supported")
+	}
+
+	if n != 1 {
+		return nil, errors.New("ssh: private key files with multiple keys are not supported")
+	}
+
+	pub, err := ParsePublicKey(pubBlob)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pr := reader{data: priv}
+	check1, check2 := pr.uint32(), pr.uint32()
+	keyType := string(pr.string())
+	pkBytes := pr.string()
+	skBytes := pr.string()
+	comment := string(pr.string())
+
+	if pr.err != nil || check1 != check2 {
+		return nil, ErrMalformed
+	}
+
+	for i, b := range pr.data {
+		if b != byte(i+1) {
+			return nil, ErrMalformed
+		}
+	}
+
+	if keyType != pub.Type() || !bytes.Equal(pkBytes, pub.Key.KeyBytes) {
+		return nil, ErrMalformed
+	}
+
+	ctx, _ := slhdsa.New(pub.ParameterSet)
+	sk, err := ctx.GetPrivateKeyFromBytes(skBytes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(skBytes[len(skBytes)/2:], pkBytes) {
+		return nil, errors.New("ssh: private key does not match public key")
+	}
+
+	return &PrivateKey{ParameterSet: pub.ParameterSet, Key: sk, Comment: comment}, nil
+}
@@ -0,0 +1,236 @@
+// Package ssh implements OpenSSH key formats, sshsig file signatures and
+// certificates for SLH-DSA keys.
+package ssh
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+var (
+	ErrUnsupportedKeyType = errors.New("ssh: unsupported key type")
+	ErrMalformed          = errors.New("ssh: malformed data")
+	ErrInvalidSignature   = errors.New("ssh: invalid signature")
+)
+
+// SSH key type names of the SLH-DSA parameter sets
+//
+// Names follow the SSH PQ signature drafts: "ssh-" followed by the lower-cased
+// parameter set name.
+var keyTypes = map[string]string{
+	slhdsa.ParameterSet.SLHDSA_SHA2_128s:  "ssh-slh-dsa-sha2-128s",
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128s: "ssh-slh-dsa-shake-128s",
+	slhdsa.ParameterSet.SLHDSA_SHA2_128f:  "ssh-slh-dsa-sha2-128f",
+	slhdsa.ParameterSet.SLHDSA_SHAKE_128f: "ssh-slh-dsa-shake-128f",
+	slhdsa.ParameterSet.SLHDSA_SHA2_192s:  "ssh-slh-dsa-sha2-192s",
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192s: "ssh-slh-dsa-shake-192s",
+	slhdsa.ParameterSet.SLHDSA_SHA2_192f:  "ssh-slh-dsa-sha2-192f",
+	slhdsa.ParameterSet.SLHDSA_SHAKE_192f: "ssh-slh-dsa-shake-192f",
+	slhdsa.ParameterSet.SLHDSA_SHA2_256s:  "ssh-slh-dsa-sha2-256s",
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256s: "ssh-slh-dsa-shake-256s",
+	slhdsa.ParameterSet.SLHDSA_SHA2_256f:  "ssh-slh-dsa-sha2-256f",
+	slhdsa.ParameterSet.SLHDSA_SHAKE_256f: "ssh-slh-dsa-shake-256f",
+}
+
+// SSH key type name of an SLH-DSA parameter set
+func KeyType(paramSet string) (string, error) {
+	name, ok := keyTypes[paramSet]
+
+	if !ok {
+		return "", ErrUnsupportedKeyType
+	}
+
+	return name, nil
+}
+
+// SLH-DSA parameter set of an SSH key type name
+func ParameterSet(keyType string) (string, error) {
+	for paramSet, name := range keyTypes {
+		if name == keyType {
+			return paramSet, nil
+		}
+	}
+
+	return "", ErrUnsupportedKeyType
+}
+
+// SLH-DSA public key with its SSH key type
+type PublicKey struct {
+	ParameterSet string
+	Key          slhdsa.PublicKey
+}
+
+// SLH-DSA private key with its SSH key type
+type PrivateKey struct {
+	ParameterSet string
+	Key          slhdsa.PrivateKey
+	Comment      string
+}
+
+// Public half of the private key
+func (k *PrivateKey) Public() (*PublicKey, error) {
+	pk, err := slhdsa.PublicKeyFromPrivateKey(k.ParameterSet, k.Key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublicKey{ParameterSet: k.ParameterSet, Key: pk}, nil
+}
+
+// SSH key type name
+func (k *PublicKey) Type() string {
+	return keyTypes[k.ParameterSet]
+}
+
+// SSH wire encoding of the public key: string key type, string key bytes
+func (k *PublicKey) Marshal() []byte {
+	var b []byte
+
+	b = appendString(b, []byte(k.Type()))
+	b = appendString(b, k.Key.KeyBytes)
+
+	return b
+}
+
+// SHA256 fingerprint in the format printed by ssh-keygen
+func (k *PublicKey) Fingerprint() string {
+	h := sha256.Sum256(k.Marshal())
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(h[:])
+}
+
+// Decode the SSH wire encoding of a public key
+func ParsePublicKey(blob []byte) (*PublicKey, error) {
+	r := reader{data: blob}
+
+	keyType := r.string()
+	raw := r.string()
+
+	if r.err != nil || len(r.data) != 0 {
+		return nil, ErrMalformed
+	}
+
+	return newPublicKey(string(keyType), raw)
+}
+
+func newPublicKey(keyType string, raw []byte) (*PublicKey, error) {
+	paramSet, err := ParameterSet(keyType)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, _ := slhdsa.New(paramSet)
+	pk, err := ctx.GetPublicKeyFromBytes(raw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PublicKey{ParameterSet: paramSet, Key: pk}, nil
+}
+
+// Produce an SSH signature blob: string key type, string signature
+func (k *PrivateKey) Sign(data []byte) ([]byte, error) {
+	ctx, err := slhdsa.New(k.ParameterSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := ctx.GenerateSignature(k.Key, data, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var b []byte
+	b = appendString(b, []byte(keyTypes[k.ParameterSet]))
+	b = appendString(b, sig)
+
+	return b, nil
+}
+
+// Verify an SSH signature blob over `data`
+func (k *PublicKey) Verify(data, blob []byte) error {
+	r := reader{data: blob}
+
+	keyType := r.string()
+	sig := r.string()
+
+	if r.err != nil || len(r.data) != 0 {
+		return ErrMalformed
+	}
+
+	if string(keyType) != k.Type() {
+		return ErrInvalidSignature
+	}
+
+	ctx, _ := slhdsa.New(k.ParameterSet)
+	ok, err := ctx.VerifySignature(k.Key, data, sig, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	if err != nil || !ok {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// SSH wire format helpers (RFC 4251 Section 5)
+
+func appendUint32(b []byte, v uint32) []byte {
+	return binary.BigEndian.AppendUint32(b, v)
+}
+
+func appendString(b, s []byte) []byte {
+	b = appendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// Sequential reader that records the first error
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) uint32() uint32 {
+	if r.err != nil || len(r.data) < 4 {
+		r.err = ErrMalformed
+		return 0
+	}
+
+	v := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+
+	return v
+}
+
+func (r *reader) uint64() uint64 {
+	if r.err != nil || len(r.data) < 8 {
+		r.err = ErrMalformed
+		return 0
+	}
+
+	v := binary.BigEndian.Uint64(r.data)
+	r.data = r.data[8:]
+
+	return v
+}
+
+func (r *reader) string() []byte {
+	n := r.uint32()
+
+	if r.err != nil || uint64(n) > uint64(len(r.data)) {
+		r.err = ErrMalformed
+		return nil
+	}
+
+	s := r.data[:n]
+	r.data = r.data[n:]
+
+	return s
+}
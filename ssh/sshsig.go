@@ -0,0 +1,139 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+)
+
+const (
+	sshsigMagic   = "SSHSIG"
+	sshsigVersion = 1
+	sshsigPEMType = "SSH SIGNATURE"
+)
+
+var ErrNamespaceMismatch = errors.New("ssh: signature namespace mismatch")
+
+// Parsed sshsig signature (OpenSSH PROTOCOL.sshsig)
+type Signature struct {
+	PublicKey     *PublicKey
+	Namespace     string
+	HashAlgorithm string
+
+	// SSH signature blob over the signed data
+	Blob []byte
+}
+
+// Data covered by the signature
+func sshsigSignedData(namespace, hashAlg string, message []byte) ([]byte, error) {
+	var digest []byte
+
+	switch hashAlg {
+	case "sha512":
+		h := sha512.Sum512(message)
+		digest = h[:]
+	case "sha256":
+		h := sha256.Sum256(message)
+		digest = h[:]
+	default:
+		return nil, errors.New("ssh: unsupported sshsig hash algorithm")
+	}
+
+	var b []byte
+	b = append(b, sshsigMagic...)
+	b = appendString(b, []byte(namespace))
+	b = appendString(b, nil)
+	b = appendString(b, []byte(hashAlg))
+	b = appendString(b, digest)
+
+	return b, nil
+}
+
+// Sign a message as `ssh-keygen -Y sign -n namespace` does, returns the armored signature
+func SignFile(k *PrivateKey, namespace string, message []byte) ([]byte, error) {
+	if namespace == "" {
+		return nil, errors.New("ssh: namespace is required")
+	}
+
+	pub, err := k.Public()
+
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := sshsigSignedData(namespace, "sha512", message)
+
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := k.Sign(signed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var b []byte
+	b = append(b, sshsigMagic...)
+	b = appendUint32(b, sshsigVersion)
+	b = appendString(b, pub.Marshal())
+	b = appendString(b, []byte(namespace))
+	b = appendString(b, nil)
+	b = appendString(b, []byte("sha512"))
+	b = appendString(b, blob)
+
+	return pem.EncodeToMemory(&pem.Block{Type: sshsigPEMType, Bytes: b}), nil
+}
+
+// Parse an armored sshsig signature
+func ParseSignature(armored []byte) (*Signature, error) {
+	block, _ := pem.Decode(armored)
+
+	if block == nil || block.Type != sshsigPEMType || !bytes.HasPrefix(block.Bytes, []byte(sshsigMagic)) {
+		return nil, ErrMalformed
+	}
+
+	r := reader{data: block.Bytes[len(sshsigMagic):]}
+
+	version := r.uint32()
+	pubBlob := r.string()
+	namespace := string(r.string())
+	r.string()
+	hashAlg := string(r.string())
+	blob := r.string()
+
+	if r.err != nil || len(r.data) != 0 {
+		return nil, ErrMalformed
+	}
+
+	if version != sshsigVersion {
+		return nil, errors.New("ssh: unsupported sshsig version")
+	}
+
+	pub, err := ParsePublicKey(pubBlob)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signature{PublicKey: pub, Namespace: namespace, HashAlgorithm: hashAlg, Blob: blob}, nil
+}
+
+// Check the signature over `message` with the embedded public key
+//
+// The caller still has to decide whether the embedded key is trusted, see AllowedSigners.
+func (s *Signature) Verify(namespace string, message []byte) error {
+	if s.Namespace != namespace {
+		return ErrNamespaceMismatch
+	}
+
+	signed, err := sshsigSignedData(s.Namespace, s.HashAlgorithm, message)
+
+	if err != nil {
+		return err
+	}
+
+	return s.PublicKey.Verify(signed, s.Blob)
+}
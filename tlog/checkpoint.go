@@ -0,0 +1,91 @@
+package tlog
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/skuuzie/go-slhdsa/note"
+)
+
+var (
+	ErrMalformedCheckpoint = errors.New("tlog: malformed checkpoint")
+	ErrWrongOrigin         = errors.New("tlog: checkpoint for another log")
+	ErrSplitView           = errors.New("tlog: checkpoints of the same size disagree")
+)
+
+// Signed statement of the log size and root (C2SP tlog-checkpoint)
+type Checkpoint struct {
+	// Unique name of the log
+	Origin string
+
+	Size uint64
+	Root Hash
+}
+
+// Note text of the checkpoint
+func (c *Checkpoint) String() string {
+	return fmt.Sprintf("%s\n%d\n%s\n", c.Origin, c.Size, base64.StdEncoding.EncodeToString(c.Root[:]))
+}
+
+// Parse checkpoint note text, extension lines after the root are ignored
+func ParseCheckpoint(text string) (*Checkpoint, error) {
+	lines := strings.SplitN(text, "\n", 4)
+
+	if len(lines) < 4 || lines[0] == "" {
+		return nil, ErrMalformedCheckpoint
+	}
+
+	size, err := strconv.ParseUint(lines[1], 10, 64)
+
+	if err != nil || strconv.FormatUint(size, 10) != lines[1] {
+		return nil, ErrMalformedCheckpoint
+	}
+
+	root, err := base64.StdEncoding.DecodeString(lines[2])
+
+	if err != nil || len(root) != len(Hash{}) {
+		return nil, ErrMalformedCheckpoint
+	}
+
+	return &Checkpoint{Origin: lines[0], Size: size, Root: Hash(root)}, nil
+}
+
+// Open a signed checkpoint of the named log, verified against the trusted keys
+func OpenCheckpoint(msg []byte, origin string, known note.Verifiers) (*Checkpoint, error) {
+	n, err := note.Open(msg, known)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := ParseCheckpoint(n.Text)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Origin != origin {
+		return nil, ErrWrongOrigin
+	}
+
+	return c, nil
+}
+
+// Check that a newer checkpoint extends an older one of the same log
+//
+// Two checkpoints of the same size with different roots are evidence of a
+// split view and fail with ErrSplitView.
+func CheckConsistency(older, newer *Checkpoint, proof []Hash) error {
+	if older.Origin != newer.Origin {
+		return ErrWrongOrigin
+	}
+
+	if older.Size == newer.Size && older.Root != newer.Root {
+		return ErrSplitView
+	}
+
+	return VerifyConsistency(older.Size, newer.Size, older.Root, newer.Root, proof)
+}
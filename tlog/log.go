@@ -0,0 +1,337 @@
+// Package tlog implements a local append-only transparency log: an RFC 6962
+// Merkle tree over file-backed entries, inclusion and consistency proofs, and
+// checkpoints signed as notes with SLH-DSA keys.
+package tlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/skuuzie/go-slhdsa/note"
+)
+
+// Files of a log directory
+const (
+	entriesFile    = "entries"
+	checkpointFile = "checkpoint"
+)
+
+// Largest accepted entry
+const maxEntrySize = 1 << 24
+
+var (
+	ErrEntryTooLarge = errors.New("tlog: entry too large")
+	ErrNoCheckpoint  = errors.New("tlog: no checkpoint signed yet")
+	ErrCorrupt       = errors.New("tlog: entries file corrupt or shorter than the latest checkpoint")
+)
+
+// File-backed transparency log
+type Log struct {
+	origin string
+	dir    string
+
+	// Held while signing and storing a checkpoint, so a slower call never
+	// replaces a newer checkpoint with an older one
+	checkpointMu sync.Mutex
+
+	mu      sync.Mutex
+	entries *os.File
+	offsets []int64
+	end     int64
+	tree    tree
+}
+
+// Open or create the log stored in dir
+//
+// Entries are stored as a uint32 length followed by the data. A record cut
+// short by a crash during Append is truncated away, any other damage, or
+// entries that no longer match the latest checkpoint, fails with ErrCorrupt.
+func Open(dir, origin string) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, entriesFile), os.O_RDWR|os.O_CREATE, 0o644)
+
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Log{origin: origin, dir: dir, entries: f}
+
+	if err := l.load(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *Log) load() error {
+	var hdr [4]byte
+
+	for {
+		if _, err := l.entries.ReadAt(hdr[:], l.end); err != nil {
+			if isShortRead(err) {
+				break
+			}
+
+			return err
+		}
+
+		n := binary.BigEndian.Uint32(hdr[:])
+		if n > maxEntrySize {
+			return ErrCorrupt
+		}
+
+		data := make([]byte, n)
+
+		if _, err := l.entries.ReadAt(data, l.end+4); err != nil {
+			if isShortRead(err) {
+				break
+			}
+
+			return err
+		}
+
+		l.offsets = append(l.offsets, l.end)
+		l.tree.append(LeafHash(data))
+		l.end += 4 + int64(n)
+	}
+
+	// Entries covered by a signed checkpoint are never truncated away, so a
+	// short read is only a torn Append past them, and they must still hash
+	// to the signed root
+	c, err := l.latestCheckpoint()
+
+	if err != nil && err != ErrNoCheckpoint {
+		return err
+	}
+
+	if c != nil && (c.Size > l.tree.size() || l.tree.root(c.Size) != c.Root) {
+		return ErrCorrupt
+	}
+
+	return l.entries.Truncate(l.end)
+}
+
+// Read cut short by the end of the file, only a crash during Append leaves
+// the last record incomplete
+func isShortRead(err error) bool {
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// Latest stored checkpoint, its signatures are not verified
+func (l *Log) latestCheckpoint() (*Checkpoint, error) {
+	msg, err := l.LatestCheckpoint()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = note.Open(msg, note.VerifierList())
+
+	var unverified *note.UnverifiedNoteError
+	if !errors.As(err, &unverified) {
+		return nil, ErrMalformedCheckpoint
+	}
+
+	return ParseCheckpoint(unverified.Note.Text)
+}
+
+func (l *Log) Close() error {
+	return l.entries.Close()
+}
+
+func (l *Log) Origin() string {
+	return l.origin
+}
+
+// Number of entries
+func (l *Log) Size() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.tree.size()
+}
+
+// Append an entry and return its index, the entry is synced to disk before returning
+func (l *Log) Append(data []byte) (uint64, error) {
+	if len(data) > maxEntrySize {
+		return 0, ErrEntryTooLarge
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record := append(binary.BigEndian.AppendUint32(nil, uint32(len(data))), data...)
+
+	if _, err := l.entries.WriteAt(record, l.end); err != nil {
+		return 0, err
+	}
+
+	if err := l.entries.Sync(); err != nil {
+		return 0, err
+	}
+
+	index := l.tree.size()
+	l.offsets = append(l.offsets, l.end)
+	l.tree.append(LeafHash(data))
+	l.end += int64(len(record))
+
+	return index, nil
+}
+
+// Entry data at index
+func (l *Log) Entry(index uint64) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if index >= l.tree.size() {
+		return nil, ErrRange
+	}
+
+	var hdr [4]byte
+	off := l.offsets[index]
+
+	if _, err := l.entries.ReadAt(hdr[:], off); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, binary.BigEndian.Uint32(hdr[:]))
+
+	if _, err := l.entries.ReadAt(data, off+4); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Root of the tree over the first size entries
+func (l *Log) RootAt(size uint64) (Hash, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if size > l.tree.size() {
+		return Hash{}, ErrRange
+	}
+
+	return l.tree.root(size), nil
+}
+
+// Inclusion proof of the entry at index in the tree of the given size
+func (l *Log) InclusionProof(index, size uint64) ([]Hash, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if index >= size || size > l.tree.size() {
+		return nil, ErrRange
+	}
+
+	return l.tree.path(index, 0, size), nil
+}
+
+// Consistency proof between the trees of the two sizes
+func (l *Log) ConsistencyProof(oldSize, newSize uint64) ([]Hash, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if oldSize > newSize || newSize > l.tree.size() {
+		return nil, ErrRange
+	}
+
+	if oldSize == 0 || oldSize == newSize {
+		return nil, nil
+	}
+
+	return l.tree.subproof(oldSize, 0, newSize, true), nil
+}
+
+// Sign a checkpoint of the current tree and store it as the latest checkpoint
+func (l *Log) Checkpoint(signers ...note.Signer) ([]byte, error) {
+	l.checkpointMu.Lock()
+	defer l.checkpointMu.Unlock()
+
+	l.mu.Lock()
+	size := l.tree.size()
+	c := &Checkpoint{Origin: l.origin, Size: size, Root: l.tree.root(size)}
+	l.mu.Unlock()
+
+	msg, err := note.Sign(&note.Note{Text: c.String()}, signers...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace atomically so readers never see a partial checkpoint, synced
+	// first so a crash cannot leave one behind either
+	tmp := filepath.Join(l.dir, checkpointFile+".tmp")
+
+	if err := writeSynced(tmp, msg); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp, filepath.Join(l.dir, checkpointFile)); err != nil {
+		return nil, err
+	}
+
+	if err := syncDir(l.dir); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+func writeSynced(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Persist a rename within dir
+func syncDir(dir string) error {
+	// Windows cannot sync a directory handle
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+
+	if err != nil {
+		return err
+	}
+
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// Latest stored signed checkpoint
+func (l *Log) LatestCheckpoint() ([]byte, error) {
+	msg, err := os.ReadFile(filepath.Join(l.dir, checkpointFile))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoCheckpoint
+	}
+
+	return msg, err
+}
@@ -0,0 +1,280 @@
+package tlog_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/note"
+	"github.com/skuuzie/go-slhdsa/tlog"
+)
+
+const origin = "build.example.com/log"
+
+func entry(i int) []byte {
+	return fmt.Appendf(nil, "artifact %d", i)
+}
+
+// Reference root computed directly from RFC 6962 Section 2.1
+func referenceRoot(n int) tlog.Hash {
+	if n == 1 {
+		return tlog.LeafHash(entry(0))
+	}
+
+	var rec func(lo, hi int) tlog.Hash
+	rec = func(lo, hi int) tlog.Hash {
+		if hi-lo == 1 {
+			return tlog.LeafHash(entry(lo))
+		}
+
+		k := 1
+		for k*2 < hi-lo {
+			k *= 2
+		}
+
+		return tlog.NodeHash(rec(lo, lo+k), rec(lo+k, hi))
+	}
+
+	return rec(0, n)
+}
+
+func newLog(t *testing.T, dir string, n int) *tlog.Log {
+	l, err := tlog.Open(dir, origin)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	for i := range n {
+		if idx, err := l.Append(entry(i)); err != nil || idx != uint64(i) {
+			t.Fatalf("append %d: %d %v", i, idx, err)
+		}
+	}
+
+	return l
+}
+
+func TestProofs(t *testing.T) {
+	const n = 21
+	l := newLog(t, t.TempDir(), n)
+
+	for size := uint64(1); size <= n; size++ {
+		root, err := l.RootAt(size)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if root != referenceRoot(int(size)) {
+			t.Fatalf("size %d: root mismatch", size)
+		}
+
+		for i := range size {
+			proof, err := l.InclusionProof(i, size)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tlog.VerifyInclusion(tlog.LeafHash(entry(int(i))), i, size, proof, root); err != nil {
+				t.Errorf("inclusion of %d in %d: %v", i, size, err)
+			}
+
+			if err := tlog.VerifyInclusion(tlog.LeafHash([]byte("forged")), i, size, proof, root); err == nil {
+				t.Errorf("forged inclusion of %d in %d", i, size)
+			}
+		}
+
+		for old := uint64(0); old <= size; old++ {
+			oldRoot, _ := l.RootAt(old)
+			proof, err := l.ConsistencyProof(old, size)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tlog.VerifyConsistency(old, size, oldRoot, root, proof); err != nil {
+				t.Errorf("consistency of %d with %d: %v", old, size, err)
+			}
+
+			if old > 0 && old < size {
+				if err := tlog.VerifyConsistency(old, size, tlog.LeafHash([]byte("forged")), root, proof); err == nil {
+					t.Errorf("forged consistency of %d with %d", old, size)
+				}
+			}
+		}
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	dir := t.TempDir()
+	l := newLog(t, dir, 5)
+	root, _ := l.RootAt(5)
+	l.Close()
+
+	// Simulate a crash in the middle of writing a record
+	f, _ := os.OpenFile(filepath.Join(dir, "entries"), os.O_APPEND|os.O_WRONLY, 0)
+	f.Write([]byte{0, 0, 0, 9, 'x'})
+	f.Close()
+
+	l = newLog(t, dir, 0)
+
+	if l.Size() != 5 {
+		t.Fatalf("unexpected size %d after reopening", l.Size())
+	}
+
+	if r, _ := l.RootAt(5); r != root {
+		t.Error("root changed after reopening")
+	}
+
+	if _, err := l.Append(entry(5)); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := l.Entry(5); err != nil || string(data) != string(entry(5)) {
+		t.Errorf("unexpected entry %q %v", data, err)
+	}
+
+	l.Close()
+
+	// A damaged length header before the tail is not a torn write
+	path := filepath.Join(dir, "entries")
+	damaged, _ := os.ReadFile(path)
+	copy(damaged[4+len(entry(0)):], []byte{0xff, 0xff, 0xff, 0xff})
+	os.WriteFile(path, damaged, 0o644)
+
+	if _, err := tlog.Open(dir, origin); !errors.Is(err, tlog.ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, damaged) {
+		t.Error("damaged entries file truncated")
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	skey, vkey, err := note.GenerateKey("log.example.com", slhdsa.ParameterSet.SLHDSA_SHA2_128f)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, _ := note.NewSigner(skey)
+	verifier, _ := note.NewVerifier(vkey)
+	known := note.VerifierList(verifier)
+
+	dir := t.TempDir()
+	l := newLog(t, dir, 3)
+
+	if _, err := l.LatestCheckpoint(); !errors.Is(err, tlog.ErrNoCheckpoint) {
+		t.Errorf("expected ErrNoCheckpoint, got %v", err)
+	}
+
+	msg1, err := l.Checkpoint(signer)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 3; i < 8; i++ {
+		l.Append(entry(i))
+	}
+
+	if _, err := l.Checkpoint(signer); err != nil {
+		t.Fatal(err)
+	}
+
+	msg2, err := l.LatestCheckpoint()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c1, err := tlog.OpenCheckpoint(msg1, origin, known)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c2, err := tlog.OpenCheckpoint(msg2, origin, known)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c1.Size != 3 || c2.Size != 8 {
+		t.Errorf("unexpected sizes %d %d", c1.Size, c2.Size)
+	}
+
+	proof, _ := l.ConsistencyProof(c1.Size, c2.Size)
+
+	if err := tlog.CheckConsistency(c1, c2, proof); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := tlog.OpenCheckpoint(msg1, "other.example.com/log", known); !errors.Is(err, tlog.ErrWrongOrigin) {
+		t.Errorf("expected ErrWrongOrigin, got %v", err)
+	}
+
+	// A second log with the same origin and size but other entries is a split view
+	fork := newLog(t, t.TempDir(), 2)
+	fork.Append([]byte("rewritten"))
+
+	forkMsg, _ := fork.Checkpoint(signer)
+	forked, err := tlog.OpenCheckpoint(forkMsg, origin, known)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tlog.CheckConsistency(c1, forked, nil); !errors.Is(err, tlog.ErrSplitView) {
+		t.Errorf("expected ErrSplitView, got %v", err)
+	}
+
+	if err := tlog.CheckConsistency(forked, c2, proof); err == nil {
+		t.Error("forked checkpoint consistent with the log")
+	}
+
+	// Losing the tail record is not recovered once a checkpoint covers it
+	l.Close()
+
+	path := filepath.Join(dir, "entries")
+	original, _ := os.ReadFile(path)
+	os.Truncate(path, int64(len(original))-1)
+
+	if _, err := tlog.Open(dir, origin); !errors.Is(err, tlog.ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+
+	// Nor is a header inside the checkpointed entries that runs past the end
+	damaged := bytes.Clone(original)
+	copy(damaged[4+len(entry(0)):], []byte{0, 0, 0xff, 0xff})
+	os.WriteFile(path, damaged, 0o644)
+
+	if _, err := tlog.Open(dir, origin); !errors.Is(err, tlog.ErrCorrupt) {
+		t.Errorf("long header: expected ErrCorrupt, got %v", err)
+	}
+
+	// Checkpointed entries must still hash to the signed root
+	flipped := bytes.Clone(original)
+	flipped[4] ^= 1
+	os.WriteFile(path, flipped, 0o644)
+
+	if _, err := tlog.Open(dir, origin); !errors.Is(err, tlog.ErrCorrupt) {
+		t.Errorf("flipped entry: expected ErrCorrupt, got %v", err)
+	}
+
+	os.WriteFile(path, original, 0o644)
+
+	if l, err := tlog.Open(dir, origin); err != nil {
+		t.Errorf("undamaged log: %v", err)
+	} else {
+		l.Close()
+	}
+}
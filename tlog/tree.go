@@ -0,0 +1,196 @@
+package tlog
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/bits"
+
+	"github.com/skuuzie/go-slhdsa/internal/merkle"
+)
+
+// SHA-256 tree hash
+type Hash = merkle.Hash
+
+var (
+	ErrInvalidProof = errors.New("tlog: invalid proof")
+	ErrRange        = errors.New("tlog: index or size out of range")
+)
+
+// Hash of a log entry (RFC 6962 Section 2.1)
+func LeafHash(data []byte) Hash {
+	return merkle.LeafHash(data)
+}
+
+// Hash of an interior node (RFC 6962 Section 2.1)
+func NodeHash(left, right Hash) Hash {
+	return merkle.NodeHash(left, right)
+}
+
+// Root of an empty tree, the hash of the empty string
+var emptyRoot = Hash(sha256.Sum256(nil))
+
+// Hashes of every complete subtree, levels[l][i] covers leaves [i<<l, (i+1)<<l)
+//
+// Complete subtrees never change once the log grows past them, so appending
+// a leaf only adds hashes and every range hash needs O(log² n) lookups.
+type tree struct {
+	levels [][]Hash
+}
+
+func (t *tree) size() uint64 {
+	if len(t.levels) == 0 {
+		return 0
+	}
+
+	return uint64(len(t.levels[0]))
+}
+
+func (t *tree) append(leaf Hash) {
+	h := leaf
+
+	for l := 0; ; l++ {
+		if l == len(t.levels) {
+			t.levels = append(t.levels, nil)
+		}
+
+		t.levels[l] = append(t.levels[l], h)
+
+		// Completed a right child, carry the parent up
+		n := len(t.levels[l])
+		if n%2 == 1 {
+			return
+		}
+
+		h = NodeHash(t.levels[l][n-2], t.levels[l][n-1])
+	}
+}
+
+// Hash of the subtree over leaves [lo, hi), hi > lo
+func (t *tree) hash(lo, hi uint64) Hash {
+	n := hi - lo
+
+	if n&(n-1) == 0 && lo%n == 0 {
+		l := bits.TrailingZeros64(n)
+		return t.levels[l][lo>>l]
+	}
+
+	k := merkle.Split(n)
+
+	return NodeHash(t.hash(lo, lo+k), t.hash(lo+k, hi))
+}
+
+func (t *tree) root(size uint64) Hash {
+	if size == 0 {
+		return emptyRoot
+	}
+
+	return t.hash(0, size)
+}
+
+// Audit path of leaf m in the subtree over leaves [lo, hi) (RFC 6962 Section 2.1.1)
+func (t *tree) path(m, lo, hi uint64) []Hash {
+	if hi-lo == 1 {
+		return nil
+	}
+
+	k := merkle.Split(hi - lo)
+
+	if m < lo+k {
+		return append(t.path(m, lo, lo+k), t.hash(lo+k, hi))
+	}
+
+	return append(t.path(m, lo+k, hi), t.hash(lo, lo+k))
+}
+
+// Consistency proof of the first m leaves of the subtree over [lo, hi) (RFC 6962 Section 2.1.2)
+func (t *tree) subproof(m, lo, hi uint64, complete bool) []Hash {
+	n := hi - lo
+
+	if m == n {
+		if complete {
+			return nil
+		}
+
+		return []Hash{t.hash(lo, hi)}
+	}
+
+	k := merkle.Split(n)
+
+	if m <= k {
+		return append(t.subproof(m, lo, lo+k, complete), t.hash(lo+k, hi))
+	}
+
+	return append(t.subproof(m-k, lo+k, hi, false), t.hash(lo, lo+k))
+}
+
+// Check an inclusion proof of a leaf in a tree of the given size and root (RFC 9162 Section 2.1.3.2)
+func VerifyInclusion(leaf Hash, index, size uint64, proof []Hash, root Hash) error {
+	if index >= size {
+		return ErrRange
+	}
+
+	if r, ok := merkle.RootFromInclusionProof(leaf, index, size, proof); !ok || r != root {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
+
+// Check that the tree of size oldSize is a prefix of the tree of size newSize (RFC 9162 Section 2.1.4.2)
+func VerifyConsistency(oldSize, newSize uint64, oldRoot, newRoot Hash, proof []Hash) error {
+	if oldSize > newSize {
+		return ErrRange
+	}
+
+	if oldSize == newSize || oldSize == 0 {
+		if len(proof) != 0 || (oldSize == newSize && oldRoot != newRoot) {
+			return ErrInvalidProof
+		}
+
+		return nil
+	}
+
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([]Hash{oldRoot}, proof...)
+	}
+
+	if len(proof) == 0 {
+		return ErrInvalidProof
+	}
+
+	fn, sn := oldSize-1, newSize-1
+
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 || fr != oldRoot || sr != newRoot {
+		return ErrInvalidProof
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package tsa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/cms"
+)
+
+// Media types of the HTTP transport (RFC 3161 Section 3.4)
+const (
+	ContentTypeQuery = "application/timestamp-query"
+	ContentTypeReply = "application/timestamp-reply"
+)
+
+// Largest accepted request body
+const maxRequestSize = 1 << 16
+
+// Time-stamp authority signing tokens with an SLH-DSA key
+type Authority struct {
+	ParameterSet string
+	PrivateKey   slhdsa.PrivateKey
+
+	// Policy stated in every token, requests for other policies are rejected
+	Policy asn1.ObjectIdentifier
+
+	// Optional DER encoded authority certificate, named by the
+	// signing-certificate-v2 attribute and included on request
+	Certificate []byte
+
+	// Accuracy of the clock, not stated when zero
+	Accuracy time.Duration
+
+	// Clock, time.Now when nil
+	Now func() time.Time
+}
+
+func reject(failInfo FailureInfo, text string) *Response {
+	return &Response{Status: StatusRejection, StatusString: text, FailInfo: failInfo}
+}
+
+// Answer a request, rejections are returned as responses and the error is
+// set only for signing failures
+func (a *Authority) Respond(req *Request) (*Response, error) {
+	if a.Policy == nil {
+		return reject(FailSystemFailure, "no policy configured"), errors.New("tsa: authority has no policy")
+	}
+
+	if req.Policy != nil && !req.Policy.Equal(a.Policy) {
+		return reject(FailUnacceptedPolicy, "unaccepted policy"), nil
+	}
+
+	if n := hashSize(req.HashAlgorithm); n == 0 || n != len(req.HashedMessage) {
+		return reject(FailBadAlg, "unsupported hash algorithm"), nil
+	}
+
+	now := time.Now
+	if a.Now != nil {
+		now = a.Now
+	}
+
+	// Random 128-bit serial numbers are unique without persistent state
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+
+	if err != nil {
+		return reject(FailSystemFailure, "serial number unavailable"), err
+	}
+
+	genTime := now().UTC().Truncate(time.Second)
+	info := &Info{
+		Policy:        a.Policy,
+		HashAlgorithm: req.HashAlgorithm,
+		HashedMessage: req.HashedMessage,
+		SerialNumber:  serial,
+		GenTime:       genTime,
+		Accuracy:      a.Accuracy,
+		Nonce:         req.Nonce,
+	}
+
+	content, err := info.marshal()
+
+	if err != nil {
+		return reject(FailSystemFailure, "encoding failure"), err
+	}
+
+	opts := &cms.SignOptions{ContentType: OIDTSTInfo, SigningTime: genTime}
+
+	if a.Certificate != nil {
+		h := sha256.Sum256(a.Certificate)
+		attr, err := asn1.Marshal(signingCertificateV2{Certs: []essCertIDv2{{CertHash: h[:]}}})
+
+		if err != nil {
+			return reject(FailSystemFailure, "encoding failure"), err
+		}
+
+		opts.Attributes = []cms.Attribute{{Type: OIDSigningCertificateV2, Value: attr}}
+
+		if req.CertReq {
+			opts.Certificates = [][]byte{a.Certificate}
+		}
+	}
+
+	token, err := cms.Sign(content, []cms.Signer{{ParameterSet: a.ParameterSet, PrivateKey: a.PrivateKey}}, opts)
+
+	if err != nil {
+		return reject(FailSystemFailure, "signing failure"), err
+	}
+
+	return &Response{Status: StatusGranted, Token: token}, nil
+}
+
+// Serve time-stamp queries POSTed over HTTP
+func (a *Authority) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.Header.Get("Content-Type") != ContentTypeQuery {
+		http.Error(w, "unsupported media type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize+1))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var resp *Response
+	req, err := ParseRequest(body)
+
+	var reqErr *requestError
+
+	switch {
+	case len(body) > maxRequestSize:
+		resp = reject(FailBadDataFormat, "request too large")
+	case errors.As(err, &reqErr):
+		resp = reject(reqErr.failInfo, reqErr.Error())
+	default:
+		// Signing failures are reported to the client as systemFailure
+		resp, _ = a.Respond(req)
+	}
+
+	der, err := resp.Marshal()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeReply)
+	w.Write(der)
+}
@@ -0,0 +1,364 @@
+// Package tsa implements the RFC 3161 Time-Stamp Protocol with time-stamp
+// tokens signed as CMS SignedData by SLH-DSA keys.
+//
+// Tokens identify the authority by subject key identifier. When the
+// authority has a certificate it is carried in the token and named by the
+// ESS signing-certificate-v2 attribute (RFC 5816).
+package tsa
+
+import (
+	"crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/skuuzie/go-slhdsa/cms"
+)
+
+var (
+	// Content type of TSTInfo (RFC 3161 Section 2.4.2)
+	OIDTSTInfo = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+
+	// Signed attribute naming the authority certificate (RFC 5035)
+	OIDSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+
+	OIDSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+)
+
+// PKIStatus values
+const (
+	StatusGranted                = 0
+	StatusGrantedWithMods        = 1
+	StatusRejection              = 2
+	StatusWaiting                = 3
+	StatusRevocationWarning      = 4
+	StatusRevocationNotification = 5
+)
+
+// PKIFailureInfo bits
+type FailureInfo uint32
+
+const (
+	FailBadAlg              FailureInfo = 1 << 0
+	FailBadRequest          FailureInfo = 1 << 2
+	FailBadDataFormat       FailureInfo = 1 << 5
+	FailTimeNotAvailable    FailureInfo = 1 << 14
+	FailUnacceptedPolicy    FailureInfo = 1 << 15
+	FailUnacceptedExtension FailureInfo = 1 << 16
+	FailAddInfoNotAvailable FailureInfo = 1 << 17
+	FailSystemFailure       FailureInfo = 1 << 25
+)
+
+var (
+	ErrMalformed           = errors.New("tsa: malformed message")
+	ErrUnsupportedHash     = errors.New("tsa: unsupported hash algorithm")
+	ErrNotTSTInfo          = errors.New("tsa: token does not carry a TSTInfo")
+	ErrImprintMismatch     = errors.New("tsa: message imprint does not match the document")
+	ErrNonceMismatch       = errors.New("tsa: nonce does not match the request")
+	ErrPolicyMismatch      = errors.New("tsa: policy does not match the request")
+	ErrCertificateMismatch = errors.New("tsa: signing certificate attribute does not match")
+	ErrMissingToken        = errors.New("tsa: granted response without a token")
+)
+
+// Error of a response that was not granted
+type RejectedError struct {
+	Status       int
+	StatusString string
+	FailInfo     FailureInfo
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("tsa: request rejected with status %d, failure info %#x: %s", e.Status, uint32(e.FailInfo), e.StatusString)
+}
+
+// Digest sizes of the accepted hash algorithms
+func hashSize(alg asn1.ObjectIdentifier) int {
+	switch {
+	case alg.Equal(cms.OIDSHA256):
+		return 32
+	case alg.Equal(OIDSHA384):
+		return 48
+	case alg.Equal(cms.OIDSHA512):
+		return 64
+	}
+
+	return 0
+}
+
+// ASN.1 structures (RFC 3161 Section 2.4)
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type messageImprint struct {
+	HashAlgorithm algorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	ReqPolicy      asn1.ObjectIdentifier `asn1:"optional"`
+	Nonce          *big.Int              `asn1:"optional"`
+	CertReq        bool                  `asn1:"optional"`
+	Extensions     asn1.RawValue         `asn1:"optional,tag:0"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time     `asn1:"generalized"`
+	Accuracy       accuracy      `asn1:"optional"`
+	Ordering       bool          `asn1:"optional"`
+	Nonce          *big.Int      `asn1:"optional"`
+	TSA            asn1.RawValue `asn1:"optional,explicit,tag:0"`
+	Extensions     asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []asn1.RawValue `asn1:"optional"`
+	FailInfo     asn1.BitString  `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// Time-stamp request
+type Request struct {
+	HashAlgorithm asn1.ObjectIdentifier
+	HashedMessage []byte
+
+	// Requested policy, any when nil
+	Policy asn1.ObjectIdentifier
+
+	Nonce *big.Int
+
+	// Ask for the authority certificate in the token
+	CertReq bool
+}
+
+// Request for a document digest with a random 64-bit nonce
+func NewRequest(hashAlg asn1.ObjectIdentifier, digest []byte) (*Request, error) {
+	if n := hashSize(hashAlg); n == 0 || n != len(digest) {
+		return nil, ErrUnsupportedHash
+	}
+
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Request{HashAlgorithm: hashAlg, HashedMessage: digest, Nonce: nonce, CertReq: true}, nil
+}
+
+// DER encoding of the request
+func (r *Request) Marshal() ([]byte, error) {
+	return asn1.Marshal(timeStampReq{
+		Version:        1,
+		MessageImprint: messageImprint{HashAlgorithm: algorithmIdentifier{Algorithm: r.HashAlgorithm}, HashedMessage: r.HashedMessage},
+		ReqPolicy:      r.Policy,
+		Nonce:          r.Nonce,
+		CertReq:        r.CertReq,
+	})
+}
+
+// Error of a request the authority cannot serve, carrying the failure info
+type requestError struct {
+	failInfo FailureInfo
+	err      error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// Parse a DER encoded request
+//
+// Requests with extensions are rejected, none are supported.
+func ParseRequest(der []byte) (*Request, error) {
+	var req timeStampReq
+
+	rest, err := asn1.Unmarshal(der, &req)
+
+	if err != nil || len(rest) != 0 || req.Version != 1 {
+		return nil, &requestError{FailBadDataFormat, ErrMalformed}
+	}
+
+	alg := req.MessageImprint.HashAlgorithm.Algorithm
+
+	if n := hashSize(alg); n == 0 || n != len(req.MessageImprint.HashedMessage) {
+		return nil, &requestError{FailBadAlg, ErrUnsupportedHash}
+	}
+
+	if len(req.Extensions.FullBytes) != 0 {
+		return nil, &requestError{FailUnacceptedExtension, ErrMalformed}
+	}
+
+	return &Request{
+		HashAlgorithm: alg,
+		HashedMessage: req.MessageImprint.HashedMessage,
+		Policy:        req.ReqPolicy,
+		Nonce:         req.Nonce,
+		CertReq:       req.CertReq,
+	}, nil
+}
+
+// Time-stamp token contents
+type Info struct {
+	Policy        asn1.ObjectIdentifier
+	HashAlgorithm asn1.ObjectIdentifier
+	HashedMessage []byte
+	SerialNumber  *big.Int
+	GenTime       time.Time
+
+	// Accuracy of GenTime, zero when not stated
+	Accuracy time.Duration
+
+	Ordering bool
+	Nonce    *big.Int
+}
+
+func (i *Info) marshal() ([]byte, error) {
+	acc := i.Accuracy.Round(time.Microsecond)
+
+	return asn1.Marshal(tstInfo{
+		Version:        1,
+		Policy:         i.Policy,
+		MessageImprint: messageImprint{HashAlgorithm: algorithmIdentifier{Algorithm: i.HashAlgorithm}, HashedMessage: i.HashedMessage},
+		SerialNumber:   i.SerialNumber,
+		GenTime:        i.GenTime.UTC(),
+		Accuracy: accuracy{
+			Seconds: int(acc / time.Second),
+			Millis:  int(acc % time.Second / time.Millisecond),
+			Micros:  int(acc % time.Millisecond / time.Microsecond),
+		},
+		Ordering: i.Ordering,
+		Nonce:    i.Nonce,
+	})
+}
+
+func parseInfo(der []byte) (*Info, error) {
+	var t tstInfo
+
+	rest, err := asn1.Unmarshal(der, &t)
+
+	if err != nil || len(rest) != 0 || t.Version != 1 {
+		return nil, ErrMalformed
+	}
+
+	return &Info{
+		Policy:        t.Policy,
+		HashAlgorithm: t.MessageImprint.HashAlgorithm.Algorithm,
+		HashedMessage: t.MessageImprint.HashedMessage,
+		SerialNumber:  t.SerialNumber,
+		GenTime:       t.GenTime,
+		Accuracy:      time.Duration(t.Accuracy.Seconds)*time.Second + time.Duration(t.Accuracy.Millis)*time.Millisecond + time.Duration(t.Accuracy.Micros)*time.Microsecond,
+		Ordering:      t.Ordering,
+		Nonce:         t.Nonce,
+	}, nil
+}
+
+// Time-stamp response
+type Response struct {
+	Status       int
+	StatusString string
+	FailInfo     FailureInfo
+
+	// DER encoded ContentInfo of the token, nil unless granted
+	Token []byte
+}
+
+// DER encoding of the response
+func (r *Response) Marshal() ([]byte, error) {
+	resp := timeStampResp{Status: pkiStatusInfo{Status: r.Status}}
+
+	if r.StatusString != "" {
+		resp.Status.StatusString = []asn1.RawValue{{Tag: asn1.TagUTF8String, Bytes: []byte(r.StatusString)}}
+	}
+
+	if r.FailInfo != 0 {
+		var bits asn1.BitString
+
+		for i := range 32 {
+			if r.FailInfo&(1<<i) != 0 {
+				bits.BitLength = i + 1
+			}
+		}
+
+		bits.Bytes = make([]byte, (bits.BitLength+7)/8)
+		for i := range bits.BitLength {
+			if r.FailInfo&(1<<i) != 0 {
+				bits.Bytes[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+
+		resp.Status.FailInfo = bits
+	}
+
+	if r.Token != nil {
+		resp.TimeStampToken = asn1.RawValue{FullBytes: r.Token}
+	}
+
+	return asn1.Marshal(resp)
+}
+
+// Parse a DER encoded response
+func ParseResponse(der []byte) (*Response, error) {
+	var resp timeStampResp
+
+	rest, err := asn1.Unmarshal(der, &resp)
+
+	if err != nil || len(rest) != 0 {
+		return nil, ErrMalformed
+	}
+
+	r := &Response{Status: resp.Status.Status}
+
+	for i, s := range resp.Status.StatusString {
+		if i > 0 {
+			r.StatusString += "; "
+		}
+
+		r.StatusString += string(s.Bytes)
+	}
+
+	for i := range min(resp.Status.FailInfo.BitLength, 32) {
+		if resp.Status.FailInfo.At(i) == 1 {
+			r.FailInfo |= 1 << i
+		}
+	}
+
+	if len(resp.TimeStampToken.FullBytes) != 0 {
+		r.Token = resp.TimeStampToken.FullBytes
+	}
+
+	return r, nil
+}
@@ -0,0 +1,144 @@
+package tsa_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/cms"
+	"github.com/skuuzie/go-slhdsa/internal/testkey"
+	"github.com/skuuzie/go-slhdsa/tsa"
+)
+
+var policy = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}
+
+func newAuthority(t *testing.T) (*tsa.Authority, []cms.VerifierKey) {
+	paramSet := slhdsa.ParameterSet.SLHDSA_SHA2_128f
+	sk, pk := testkey.New(t, paramSet)
+	a := &tsa.Authority{ParameterSet: paramSet, PrivateKey: sk, Policy: policy, Accuracy: 1500 * time.Millisecond}
+
+	return a, []cms.VerifierKey{{ParameterSet: paramSet, PublicKey: pk}}
+}
+
+func TestTimestampHTTP(t *testing.T) {
+	a, keys := newAuthority(t)
+	// Stand-in certificate, only its hash is checked
+	a.Certificate, _ = asn1.Marshal([]string{"tsa certificate"})
+
+	srv := httptest.NewServer(a)
+	defer srv.Close()
+
+	doc := []byte("archived record 2024-0001")
+	digest := sha256.Sum256(doc)
+
+	req, err := tsa.NewRequest(cms.OIDSHA256, digest[:])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := tsa.Timestamp(nil, srv.URL, req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := req.Verify(resp, keys)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !info.Policy.Equal(policy) || info.Accuracy != 1500*time.Millisecond || time.Since(info.GenTime) > time.Minute {
+		t.Errorf("unexpected info %+v", info)
+	}
+
+	if _, err := tsa.VerifyToken(resp.Token, cms.OIDSHA256, digest[:], keys); err != nil {
+		t.Error(err)
+	}
+
+	other := sha256.Sum256([]byte("archived record 2024-0002"))
+
+	if _, err := tsa.VerifyToken(resp.Token, cms.OIDSHA256, other[:], keys); !errors.Is(err, tsa.ErrImprintMismatch) {
+		t.Errorf("expected ErrImprintMismatch, got %v", err)
+	}
+
+	_, otherKeys := newAuthority(t)
+
+	if _, err := tsa.VerifyToken(resp.Token, cms.OIDSHA256, digest[:], otherKeys); !errors.Is(err, cms.ErrNoSignature) {
+		t.Errorf("expected cms.ErrNoSignature, got %v", err)
+	}
+
+	req.Nonce = new(big.Int).Add(req.Nonce, big.NewInt(1))
+
+	if _, err := req.Verify(resp, keys); !errors.Is(err, tsa.ErrNonceMismatch) {
+		t.Errorf("expected ErrNonceMismatch, got %v", err)
+	}
+
+	// Requests for another policy are rejected
+	req.Policy = asn1.ObjectIdentifier{1, 2, 3}
+	resp, err = tsa.Timestamp(srv.Client(), srv.URL, req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rejected *tsa.RejectedError
+
+	if _, err := req.Verify(resp, keys); !errors.As(err, &rejected) || rejected.FailInfo != tsa.FailUnacceptedPolicy {
+		t.Errorf("expected unaccepted policy rejection, got %v", err)
+	}
+
+	httpResp, err := http.Post(srv.URL, tsa.ContentTypeQuery, bytes.NewReader([]byte{0x30, 0x00}))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer httpResp.Body.Close()
+
+	var buf bytes.Buffer
+	buf.ReadFrom(httpResp.Body)
+	parsed, err := tsa.ParseResponse(buf.Bytes())
+
+	if err != nil || parsed.Status != tsa.StatusRejection || parsed.FailInfo != tsa.FailBadDataFormat {
+		t.Errorf("unexpected response %+v (%v)", parsed, err)
+	}
+}
+
+func TestRequestResponseEncoding(t *testing.T) {
+	digest := sha256.Sum256([]byte("x"))
+	req, _ := tsa.NewRequest(cms.OIDSHA256, digest[:])
+	der, _ := req.Marshal()
+	parsed, err := tsa.ParseRequest(der)
+
+	if err != nil || parsed.Nonce.Cmp(req.Nonce) != 0 || !parsed.CertReq || !bytes.Equal(parsed.HashedMessage, digest[:]) {
+		t.Errorf("unexpected request %+v (%v)", parsed, err)
+	}
+
+	if _, err := tsa.NewRequest(cms.OIDSHA512, digest[:]); !errors.Is(err, tsa.ErrUnsupportedHash) {
+		t.Errorf("expected ErrUnsupportedHash, got %v", err)
+	}
+
+	resp := &tsa.Response{Status: tsa.StatusRejection, StatusString: "busy", FailInfo: tsa.FailTimeNotAvailable | tsa.FailBadAlg}
+	der, _ = resp.Marshal()
+	back, err := tsa.ParseResponse(der)
+
+	if err != nil || back.StatusString != "busy" || back.FailInfo != resp.FailInfo || back.Token != nil {
+		t.Errorf("unexpected response %+v (%v)", back, err)
+	}
+
+	a, _ := newAuthority(t)
+	a.Policy = nil
+
+	if _, err := a.Respond(req); err == nil {
+		t.Error("responded without a policy")
+	}
+}
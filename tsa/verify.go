@@ -0,0 +1,174 @@
+package tsa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/skuuzie/go-slhdsa/cms"
+)
+
+// Verify a time-stamp token over a document digest against trusted
+// authority keys, returns the token contents
+func VerifyToken(token []byte, hashAlg asn1.ObjectIdentifier, digest []byte, keys []cms.VerifierKey) (*Info, error) {
+	sd, err := cms.Parse(token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !sd.ContentType.Equal(OIDTSTInfo) || sd.Content == nil {
+		return nil, ErrNotTSTInfo
+	}
+
+	signers, err := sd.Verify(nil, keys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSigningCertificate(sd, signers[0]); err != nil {
+		return nil, err
+	}
+
+	info, err := parseInfo(sd.Content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.HashAlgorithm.Equal(hashAlg) || subtle.ConstantTimeCompare(info.HashedMessage, digest) != 1 {
+		return nil, ErrImprintMismatch
+	}
+
+	return info, nil
+}
+
+// Certificates in the [0] IMPLICIT CertificateSet of a SignedData
+func certificates(raw []byte) ([][]byte, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	var set asn1.RawValue
+
+	if _, err := asn1.Unmarshal(raw, &set); err != nil {
+		return nil, err
+	}
+
+	var certs [][]byte
+
+	for rest := set.Bytes; len(rest) > 0; {
+		var cert asn1.RawValue
+		var err error
+
+		if rest, err = asn1.Unmarshal(rest, &cert); err != nil {
+			return nil, err
+		}
+
+		certs = append(certs, cert.FullBytes)
+	}
+
+	return certs, nil
+}
+
+// Carried certificates must match the signing-certificate-v2 attribute
+func checkSigningCertificate(sd *cms.SignedData, si cms.SignerInfo) error {
+	certs, err := certificates(sd.Certificates)
+
+	if err != nil {
+		return err
+	}
+
+	for _, attr := range si.Attributes {
+		if !attr.Type.Equal(OIDSigningCertificateV2) {
+			continue
+		}
+
+		var sc signingCertificateV2
+
+		if _, err := asn1.Unmarshal(attr.Value, &sc); err != nil || len(sc.Certs) == 0 {
+			return ErrMalformed
+		}
+
+		for _, c := range certs {
+			h := sha256.Sum256(c)
+
+			if bytes.Equal(h[:], sc.Certs[0].CertHash) {
+				return nil
+			}
+		}
+
+		if len(certs) != 0 {
+			return ErrCertificateMismatch
+		}
+	}
+
+	return nil
+}
+
+// Verify the response to this request, checking status, nonce, policy and
+// the message imprint
+func (r *Request) Verify(resp *Response, keys []cms.VerifierKey) (*Info, error) {
+	if resp.Status != StatusGranted && resp.Status != StatusGrantedWithMods {
+		return nil, &RejectedError{Status: resp.Status, StatusString: resp.StatusString, FailInfo: resp.FailInfo}
+	}
+
+	if resp.Token == nil {
+		return nil, ErrMissingToken
+	}
+
+	info, err := VerifyToken(resp.Token, r.HashAlgorithm, r.HashedMessage, keys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if (r.Nonce == nil) != (info.Nonce == nil) || (r.Nonce != nil && r.Nonce.Cmp(info.Nonce) != 0) {
+		return nil, ErrNonceMismatch
+	}
+
+	if r.Policy != nil && !r.Policy.Equal(info.Policy) {
+		return nil, ErrPolicyMismatch
+	}
+
+	return info, nil
+}
+
+// Send the request to a time-stamp authority over HTTP, http.DefaultClient
+// is used when client is nil
+func Timestamp(client *http.Client, url string, req *Request) (*Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	der, err := req.Marshal()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Post(url, ContentTypeQuery, bytes.NewReader(der))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tsa: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseResponse(body)
+}
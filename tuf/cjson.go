@@ -0,0 +1,107 @@
+package tuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var ErrNotCanonical = errors.New("tuf: value has no canonical JSON form")
+
+// Canonical JSON encoding (OLPC canonical JSON as used by TUF)
+//
+// Objects have sorted keys and no whitespace, strings escape only '"' and
+// '\', and numbers must be integers.
+func CanonicalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var generic any
+
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if err := writeCanonical(&buf, generic); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		n, err := strconv.ParseInt(v.String(), 10, 64)
+
+		if err != nil {
+			return ErrNotCanonical
+		}
+
+		buf.WriteString(strconv.FormatInt(n, 10))
+	case string:
+		writeString(buf, v)
+	case []any:
+		buf.WriteByte('[')
+
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := writeCanonical(buf, e); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+
+		slices.Sort(keys)
+		buf.WriteByte('{')
+
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			writeString(buf, k)
+			buf.WriteByte(':')
+
+			if err := writeCanonical(buf, v[k]); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+	default:
+		return ErrNotCanonical
+	}
+
+	return nil
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	buf.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s))
+	buf.WriteByte('"')
+}
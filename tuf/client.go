@@ -0,0 +1,384 @@
+package tuf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Download limits for metadata without a known length
+const (
+	maxRootSize      = 1 << 20
+	maxTimestampSize = 1 << 20
+	maxMetadataSize  = 16 << 20
+)
+
+// Most root versions fetched in one update (TUF specification 5.3.3)
+const maxRootRotations = 256
+
+var errNotFound = errors.New("tuf: not found")
+
+// TUF client following the detailed client workflow of the specification
+type Client struct {
+	// Repository base URL, metadata at the root and targets under "targets/"
+	BaseURL string
+
+	// HTTP client used for downloads, defaults to http.DefaultClient
+	HTTPClient *http.Client
+
+	// Clock used for expiry checks, defaults to time.Now
+	Now func() time.Time
+
+	root      *Root
+	timestamp *Timestamp
+	snapshot  *Snapshot
+	targets   *Targets
+}
+
+// Create a client trusting the given root metadata file
+//
+// The initial root is trusted on first use but must still be signed by a
+// threshold of its own root keys.
+func NewClient(trustedRoot []byte, baseURL string) (*Client, error) {
+	md, err := ParseMetadata(trustedRoot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var root Root
+
+	if err := md.Decode(RoleRoot, &root); err != nil {
+		return nil, err
+	}
+
+	if err := md.VerifyRole(&root, RoleRoot); err != nil {
+		return nil, err
+	}
+
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), root: &root}, nil
+}
+
+// Currently trusted root
+func (c *Client) Root() *Root {
+	return c.root
+}
+
+func (c *Client) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+
+	return time.Now()
+}
+
+func (c *Client) fetch(name string, limit int64) ([]byte, error) {
+	u, err := url.JoinPath(c.BaseURL, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	resp, err := hc.Get(u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, errNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tuf: fetching %s: %s", name, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > limit {
+		return nil, ErrLengthMismatch
+	}
+
+	return data, nil
+}
+
+// Refresh the top-level metadata: root, timestamp, snapshot, then targets
+func (c *Client) Update() error {
+	if err := c.updateRoot(); err != nil {
+		return fmt.Errorf("root: %w", err)
+	}
+
+	if err := c.updateTimestamp(); err != nil {
+		return fmt.Errorf("timestamp: %w", err)
+	}
+
+	if err := c.updateSnapshot(); err != nil {
+		return fmt.Errorf("snapshot: %w", err)
+	}
+
+	if err := c.updateTargets(); err != nil {
+		return fmt.Errorf("targets: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) updateRoot() error {
+	for range maxRootRotations {
+		data, err := c.fetch(fmt.Sprintf("%d.root.json", c.root.Version+1), maxRootSize)
+
+		if errors.Is(err, errNotFound) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		md, err := ParseMetadata(data)
+
+		if err != nil {
+			return err
+		}
+
+		root, err := VerifyRootUpdate(c.root, md)
+
+		if err != nil {
+			return err
+		}
+
+		// Rotated timestamp or snapshot keys invalidate the cached metadata
+		if !sameRole(c.root, root, RoleTimestamp) || !sameRole(c.root, root, RoleSnapshot) {
+			c.timestamp, c.snapshot = nil, nil
+		}
+
+		c.root = root
+	}
+
+	if !c.now().Before(c.root.Expires) {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+func sameRole(a, b *Root, role string) bool {
+	ra, rb := a.Roles[role], b.Roles[role]
+
+	if ra == nil || rb == nil || ra.Threshold != rb.Threshold || len(ra.KeyIDs) != len(rb.KeyIDs) {
+		return false
+	}
+
+	for _, id := range ra.KeyIDs {
+		if !contains(rb.KeyIDs, id) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Fetch, verify and decode role metadata
+func (c *Client) load(name, role string, limit int64, check func([]byte) error, v any) error {
+	data, err := c.fetch(name, limit)
+
+	if err != nil {
+		return err
+	}
+
+	if check != nil {
+		if err := check(data); err != nil {
+			return err
+		}
+	}
+
+	md, err := ParseMetadata(data)
+
+	if err != nil {
+		return err
+	}
+
+	if err := md.VerifyRole(c.root, role); err != nil {
+		return err
+	}
+
+	return md.Decode(role, v)
+}
+
+func (c *Client) updateTimestamp() error {
+	var ts Timestamp
+
+	if err := c.load("timestamp.json", RoleTimestamp, maxTimestampSize, nil, &ts); err != nil {
+		return err
+	}
+
+	snap, ok := ts.Meta["snapshot.json"]
+
+	if !ok {
+		return ErrMalformedMetadata
+	}
+
+	if c.timestamp != nil {
+		if ts.Version < c.timestamp.Version {
+			return ErrRollback
+		}
+
+		if snap.Version < c.timestamp.Meta["snapshot.json"].Version {
+			return ErrRollback
+		}
+	}
+
+	if !c.now().Before(ts.Expires) {
+		return ErrExpired
+	}
+
+	c.timestamp = &ts
+
+	return nil
+}
+
+func (c *Client) metaName(role string, version int64) string {
+	if c.root.ConsistentSnapshot {
+		return fmt.Sprintf("%d.%s.json", version, role)
+	}
+
+	return role + ".json"
+}
+
+func (c *Client) updateSnapshot() error {
+	want := c.timestamp.Meta["snapshot.json"]
+
+	limit := int64(maxMetadataSize)
+	if want.Length > 0 {
+		limit = want.Length
+	}
+
+	var snap Snapshot
+
+	check := func(data []byte) error { return checkFile(data, want.Length, want.Hashes, true) }
+
+	if err := c.load(c.metaName(RoleSnapshot, want.Version), RoleSnapshot, limit, check, &snap); err != nil {
+		return err
+	}
+
+	if snap.Version != want.Version {
+		return ErrVersionMismatch
+	}
+
+	// No metadata listed in the trusted snapshot may disappear or roll back
+	if c.snapshot != nil {
+		for name, old := range c.snapshot.Meta {
+			cur, ok := snap.Meta[name]
+
+			if !ok || cur.Version < old.Version {
+				return ErrRollback
+			}
+		}
+	}
+
+	if !c.now().Before(snap.Expires) {
+		return ErrExpired
+	}
+
+	c.snapshot = &snap
+
+	return nil
+}
+
+func (c *Client) updateTargets() error {
+	want, ok := c.snapshot.Meta["targets.json"]
+
+	if !ok {
+		return ErrMalformedMetadata
+	}
+
+	limit := int64(maxMetadataSize)
+	if want.Length > 0 {
+		limit = want.Length
+	}
+
+	var targets Targets
+
+	check := func(data []byte) error { return checkFile(data, want.Length, want.Hashes, true) }
+
+	if err := c.load(c.metaName(RoleTargets, want.Version), RoleTargets, limit, check, &targets); err != nil {
+		return err
+	}
+
+	if targets.Version != want.Version {
+		return ErrVersionMismatch
+	}
+
+	if !c.now().Before(targets.Expires) {
+		return ErrExpired
+	}
+
+	c.targets = &targets
+
+	return nil
+}
+
+// Trusted description of a target, Update must have succeeded first
+func (c *Client) Target(name string) (TargetFile, error) {
+	if c.targets == nil {
+		return TargetFile{}, ErrTargetNotFound
+	}
+
+	t, ok := c.targets.Targets[name]
+
+	if !ok {
+		return TargetFile{}, ErrTargetNotFound
+	}
+
+	return t, nil
+}
+
+// Download a target and check it against the trusted targets metadata
+func (c *Client) Download(name string) ([]byte, error) {
+	t, err := c.Target(name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file := name
+
+	if c.root.ConsistentSnapshot {
+		h, err := preferredHash(t.Hashes)
+
+		if err != nil {
+			return nil, err
+		}
+
+		dir, base := path.Split(name)
+		file = dir + h + "." + base
+	}
+
+	data, err := c.fetch("targets/"+file, t.Length)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkFile(data, t.Length, t.Hashes, false); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
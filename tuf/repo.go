@@ -0,0 +1,199 @@
+package tuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File-based TUF repository writer
+//
+// Metadata is written to Dir and target files to Dir/targets, ready to be
+// served as static files.
+type Repository struct {
+	Dir string
+
+	Root      *Root
+	Targets   *Targets
+	Snapshot  *Snapshot
+	Timestamp *Timestamp
+
+	// Signing keys of each top-level role
+	Signers map[string][]*Signer
+}
+
+// Expiry in the second-precision UTC form required by the specification
+func expiry(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// Create a repository whose roles each use the given signers and threshold,
+// writing version 1 of the root
+func NewRepository(dir string, signers map[string][]*Signer, threshold int, expires time.Time, consistentSnapshot bool) (*Repository, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "targets"), 0o755); err != nil {
+		return nil, err
+	}
+
+	root := &Root{
+		Type:               RoleRoot,
+		SpecVersion:        SpecVersion,
+		Version:            1,
+		Expires:            expiry(expires),
+		ConsistentSnapshot: consistentSnapshot,
+	}
+
+	for _, role := range []string{RoleRoot, RoleTargets, RoleSnapshot, RoleTimestamp} {
+		var keys []*Key
+		for _, s := range signers[role] {
+			keys = append(keys, s.Key)
+		}
+
+		root.SetRole(role, threshold, keys...)
+	}
+
+	r := &Repository{
+		Dir:       dir,
+		Root:      root,
+		Targets:   &Targets{Type: RoleTargets, SpecVersion: SpecVersion, Targets: map[string]TargetFile{}},
+		Snapshot:  &Snapshot{Type: RoleSnapshot, SpecVersion: SpecVersion},
+		Timestamp: &Timestamp{Type: RoleTimestamp, SpecVersion: SpecVersion},
+		Signers:   signers,
+	}
+
+	if err := r.writeRoot(signers[RoleRoot]); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Repository) write(name string, md *Metadata) ([]byte, error) {
+	data, err := json.Marshal(md)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return data, os.WriteFile(filepath.Join(r.Dir, name), data, 0o644)
+}
+
+// Write the root as <version>.root.json and root.json
+func (r *Repository) writeRoot(signers []*Signer) error {
+	md, err := Sign(r.Root, signers...)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.write(fmt.Sprintf("%d.root.json", r.Root.Version), md); err != nil {
+		return err
+	}
+
+	_, err = r.write("root.json", md)
+
+	return err
+}
+
+// Replace the root role and top-level role keys, signing the new root with
+// both the previous and the new root keys
+func (r *Repository) RotateRoot(signers map[string][]*Signer, threshold int, expires time.Time) error {
+	previous := r.Signers[RoleRoot]
+	root := *r.Root
+	root.Version++
+	root.Expires = expiry(expires)
+	root.Keys = nil
+	root.Roles = nil
+
+	for _, role := range []string{RoleRoot, RoleTargets, RoleSnapshot, RoleTimestamp} {
+		var keys []*Key
+		for _, s := range signers[role] {
+			keys = append(keys, s.Key)
+		}
+
+		root.SetRole(role, threshold, keys...)
+	}
+
+	r.Root = &root
+	r.Signers = signers
+
+	return r.writeRoot(append(append([]*Signer{}, previous...), signers[RoleRoot]...))
+}
+
+// Store a target file and list it in the targets metadata
+func (r *Repository) AddTarget(name string, data []byte) error {
+	length, hashes := fileInfo(data)
+	path := name
+
+	if r.Root.ConsistentSnapshot {
+		dir, file := filepath.Split(name)
+		path = filepath.Join(dir, hashes["sha256"]+"."+file)
+	}
+
+	full := filepath.Join(r.Dir, "targets", filepath.FromSlash(path))
+
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(full, data, 0o644); err != nil {
+		return err
+	}
+
+	r.Targets.Targets[name] = TargetFile{Length: length, Hashes: hashes}
+
+	return nil
+}
+
+// Sign and write new versions of the targets, snapshot and timestamp metadata
+func (r *Repository) Publish(expires time.Time) error {
+	r.Targets.Version++
+	r.Targets.Expires = expiry(expires)
+
+	md, err := Sign(r.Targets, r.Signers[RoleTargets]...)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.write(r.metaName(RoleTargets, r.Targets.Version), md); err != nil {
+		return err
+	}
+
+	r.Snapshot.Version++
+	r.Snapshot.Expires = expiry(expires)
+	r.Snapshot.Meta = map[string]MetaFile{"targets.json": {Version: r.Targets.Version}}
+
+	if md, err = Sign(r.Snapshot, r.Signers[RoleSnapshot]...); err != nil {
+		return err
+	}
+
+	snapshot, err := r.write(r.metaName(RoleSnapshot, r.Snapshot.Version), md)
+
+	if err != nil {
+		return err
+	}
+
+	length, hashes := fileInfo(snapshot)
+	r.Timestamp.Version++
+	r.Timestamp.Expires = expiry(expires)
+	r.Timestamp.Meta = map[string]MetaFile{"snapshot.json": {Version: r.Snapshot.Version, Length: length, Hashes: hashes}}
+
+	if md, err = Sign(r.Timestamp, r.Signers[RoleTimestamp]...); err != nil {
+		return err
+	}
+
+	_, err = r.write("timestamp.json", md)
+
+	return err
+}
+
+// File name of role metadata, version prefixed under consistent snapshots
+func (r *Repository) metaName(role string, version int64) string {
+	if r.Root.ConsistentSnapshot {
+		return fmt.Sprintf("%d.%s.json", version, role)
+	}
+
+	return role + ".json"
+}
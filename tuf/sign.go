@@ -0,0 +1,207 @@
+package tuf
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Signed metadata file
+type Metadata struct {
+	Signed     json.RawMessage `json:"signed"`
+	Signatures []Signature     `json:"signatures"`
+}
+
+type Signature struct {
+	KeyID string `json:"keyid"`
+
+	// Hex encoded signature over the canonical JSON of the signed part
+	Sig string `json:"sig"`
+}
+
+// SLH-DSA signing key of a role
+type Signer struct {
+	Key *Key
+
+	paramSet string
+	sk       slhdsa.PrivateKey
+}
+
+func NewSigner(paramSet string, sk slhdsa.PrivateKey) (*Signer, error) {
+	pk, err := slhdsa.PublicKeyFromPrivateKey(paramSet, sk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := NewKey(paramSet, pk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{Key: k, paramSet: paramSet, sk: sk}, nil
+}
+
+// Generate a fresh SLH-DSA signing key
+func GenerateSigner(paramSet string) (*Signer, error) {
+	ctx, err := slhdsa.New(paramSet)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sk, _, err := ctx.GenerateKeyPair()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigner(paramSet, sk)
+}
+
+// Sign the canonical JSON of the signed part with every signer
+func Sign(signed any, signers ...*Signer) (*Metadata, error) {
+	data, err := CanonicalJSON(signed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	md := &Metadata{Signed: data, Signatures: []Signature{}}
+
+	for _, s := range signers {
+		ctx, _ := slhdsa.New(s.paramSet)
+		sig, err := ctx.GenerateSignature(s.sk, data, nil, true, slhdsa.PreHashAlgorithm.Pure)
+
+		if err != nil {
+			return nil, err
+		}
+
+		md.Signatures = append(md.Signatures, Signature{KeyID: s.Key.ID(), Sig: hex.EncodeToString(sig)})
+	}
+
+	return md, nil
+}
+
+// Parse a metadata file
+func ParseMetadata(data []byte) (*Metadata, error) {
+	var md Metadata
+
+	if err := json.Unmarshal(data, &md); err != nil || len(md.Signed) == 0 {
+		return nil, ErrMalformedMetadata
+	}
+
+	return &md, nil
+}
+
+// Decode the signed part, checking its _type
+func (md *Metadata) Decode(role string, v any) error {
+	var c common
+
+	if err := json.Unmarshal(md.Signed, &c); err != nil {
+		return ErrMalformedMetadata
+	}
+
+	if c.Type != role {
+		return ErrWrongType
+	}
+
+	if err := json.Unmarshal(md.Signed, v); err != nil {
+		return ErrMalformedMetadata
+	}
+
+	return nil
+}
+
+// Check that the metadata carries valid signatures from a threshold of the
+// role's keys as defined by the root
+//
+// Each key counts once no matter how many signatures carry its ID.
+func (md *Metadata) VerifyRole(root *Root, role string) error {
+	r, ok := root.Roles[role]
+
+	if !ok || r.Threshold < 1 {
+		return ErrUnknownRole
+	}
+
+	// Signatures are over the canonical form, whatever the file formatting
+	var generic any
+
+	if err := json.Unmarshal(md.Signed, &generic); err != nil {
+		return ErrMalformedMetadata
+	}
+
+	data, err := CanonicalJSON(generic)
+
+	if err != nil {
+		return err
+	}
+
+	valid := make(map[string]bool)
+
+	for _, sig := range md.Signatures {
+		if valid[sig.KeyID] || !contains(r.KeyIDs, sig.KeyID) {
+			continue
+		}
+
+		k, ok := root.Keys[sig.KeyID]
+
+		if !ok || k.ID() != sig.KeyID {
+			continue
+		}
+
+		raw, err := hex.DecodeString(sig.Sig)
+
+		if err != nil {
+			continue
+		}
+
+		if k.verify(data, raw) {
+			valid[sig.KeyID] = true
+		}
+	}
+
+	if len(valid) < r.Threshold {
+		return ErrThreshold
+	}
+
+	return nil
+}
+
+func contains(ids []string, id string) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Verify a new root against the trusted one (TUF specification 5.3)
+//
+// The new root must be signed by a threshold of the trusted root's root keys
+// and of its own root keys, and its version must be exactly one higher.
+func VerifyRootUpdate(trusted *Root, md *Metadata) (*Root, error) {
+	if err := md.VerifyRole(trusted, RoleRoot); err != nil {
+		return nil, err
+	}
+
+	var root Root
+
+	if err := md.Decode(RoleRoot, &root); err != nil {
+		return nil, err
+	}
+
+	if err := md.VerifyRole(&root, RoleRoot); err != nil {
+		return nil, err
+	}
+
+	if root.Version != trusted.Version+1 {
+		return nil, ErrRollback
+	}
+
+	return &root, nil
+}
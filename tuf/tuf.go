@@ -0,0 +1,250 @@
+// Package tuf implements The Update Framework (TUF) metadata with SLH-DSA
+// keys: canonical JSON signing of root, targets, snapshot and timestamp
+// roles, per-role thresholds, root rotation, a file-based repository writer
+// and a client running the TUF update workflow over HTTP.
+package tuf
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+)
+
+// Top-level role names
+const (
+	RoleRoot      = "root"
+	RoleTargets   = "targets"
+	RoleSnapshot  = "snapshot"
+	RoleTimestamp = "timestamp"
+)
+
+const (
+	SpecVersion = "1.0.31"
+
+	// Key type of SLH-DSA keys, the scheme is the parameter set name
+	KeyTypeSLHDSA = "slh-dsa"
+)
+
+var (
+	ErrUnsupportedKey    = errors.New("tuf: unsupported key type or scheme")
+	ErrUnknownRole       = errors.New("tuf: role not defined in root")
+	ErrThreshold         = errors.New("tuf: signature threshold not met")
+	ErrWrongType         = errors.New("tuf: metadata has the wrong type")
+	ErrExpired           = errors.New("tuf: metadata has expired")
+	ErrRollback          = errors.New("tuf: metadata version rolled back")
+	ErrVersionMismatch   = errors.New("tuf: metadata version does not match the referencing role")
+	ErrLengthMismatch    = errors.New("tuf: length does not match metadata")
+	ErrHashMismatch      = errors.New("tuf: hash does not match metadata")
+	ErrNoSupportedHash   = errors.New("tuf: no supported hash in metadata")
+	ErrTargetNotFound    = errors.New("tuf: target not found")
+	ErrMalformedMetadata = errors.New("tuf: malformed metadata")
+)
+
+// Public key in TUF metadata
+type Key struct {
+	KeyType string `json:"keytype"`
+	Scheme  string `json:"scheme"`
+	KeyVal  KeyVal `json:"keyval"`
+}
+
+type KeyVal struct {
+	// Hex encoded SLH-DSA public key
+	Public string `json:"public"`
+}
+
+// TUF key of an SLH-DSA public key
+func NewKey(paramSet string, pk slhdsa.PublicKey) (*Key, error) {
+	if _, err := slhdsa.New(paramSet); err != nil {
+		return nil, ErrUnsupportedKey
+	}
+
+	return &Key{KeyType: KeyTypeSLHDSA, Scheme: paramSet, KeyVal: KeyVal{Public: hex.EncodeToString(pk.KeyBytes)}}, nil
+}
+
+// Key ID, hex SHA-256 of the canonical JSON of the key
+func (k *Key) ID() string {
+	data, _ := CanonicalJSON(k)
+	h := sha256.Sum256(data)
+
+	return hex.EncodeToString(h[:])
+}
+
+func (k *Key) verify(data, sig []byte) bool {
+	if k.KeyType != KeyTypeSLHDSA {
+		return false
+	}
+
+	ctx, err := slhdsa.New(k.Scheme)
+
+	if err != nil {
+		return false
+	}
+
+	raw, err := hex.DecodeString(k.KeyVal.Public)
+
+	if err != nil {
+		return false
+	}
+
+	pk, err := ctx.GetPublicKeyFromBytes(raw)
+
+	if err != nil {
+		return false
+	}
+
+	ok, err := ctx.VerifySignature(pk, data, sig, nil, slhdsa.PreHashAlgorithm.Pure)
+
+	return err == nil && ok
+}
+
+// Keys and threshold of a role
+type Role struct {
+	KeyIDs    []string `json:"keyids"`
+	Threshold int      `json:"threshold"`
+}
+
+// Fields shared by every role's signed metadata
+type common struct {
+	Type        string    `json:"_type"`
+	SpecVersion string    `json:"spec_version"`
+	Version     int64     `json:"version"`
+	Expires     time.Time `json:"expires"`
+}
+
+type Root struct {
+	Type               string           `json:"_type"`
+	SpecVersion        string           `json:"spec_version"`
+	Version            int64            `json:"version"`
+	Expires            time.Time        `json:"expires"`
+	ConsistentSnapshot bool             `json:"consistent_snapshot"`
+	Keys               map[string]*Key  `json:"keys"`
+	Roles              map[string]*Role `json:"roles"`
+}
+
+// Replace the keys of a role, adding the keys to the root
+func (r *Root) SetRole(name string, threshold int, keys ...*Key) {
+	if r.Keys == nil {
+		r.Keys = make(map[string]*Key)
+	}
+
+	if r.Roles == nil {
+		r.Roles = make(map[string]*Role)
+	}
+
+	role := &Role{Threshold: threshold, KeyIDs: []string{}}
+
+	for _, k := range keys {
+		id := k.ID()
+		r.Keys[id] = k
+		role.KeyIDs = append(role.KeyIDs, id)
+	}
+
+	r.Roles[name] = role
+}
+
+// Length and hashes of a target file or metadata file
+type TargetFile struct {
+	Length int64             `json:"length"`
+	Hashes map[string]string `json:"hashes"`
+	Custom json.RawMessage   `json:"custom,omitempty"`
+}
+
+type Targets struct {
+	Type        string                `json:"_type"`
+	SpecVersion string                `json:"spec_version"`
+	Version     int64                 `json:"version"`
+	Expires     time.Time             `json:"expires"`
+	Targets     map[string]TargetFile `json:"targets"`
+}
+
+// Version of a metadata file, with optional length and hashes
+type MetaFile struct {
+	Version int64             `json:"version"`
+	Length  int64             `json:"length,omitempty"`
+	Hashes  map[string]string `json:"hashes,omitempty"`
+}
+
+type Snapshot struct {
+	Type        string              `json:"_type"`
+	SpecVersion string              `json:"spec_version"`
+	Version     int64               `json:"version"`
+	Expires     time.Time           `json:"expires"`
+	Meta        map[string]MetaFile `json:"meta"`
+}
+
+type Timestamp struct {
+	Type        string              `json:"_type"`
+	SpecVersion string              `json:"spec_version"`
+	Version     int64               `json:"version"`
+	Expires     time.Time           `json:"expires"`
+	Meta        map[string]MetaFile `json:"meta"`
+}
+
+// Hash functions accepted in target and metadata file descriptions, in
+// order of preference for consistent snapshot file names
+var hashFuncs = []struct {
+	name string
+	sum  func([]byte) []byte
+}{
+	{"sha256", func(b []byte) []byte { h := sha256.Sum256(b); return h[:] }},
+	{"sha512", func(b []byte) []byte { h := sha512.Sum512(b); return h[:] }},
+}
+
+// Check length and hashes of data against a target or metadata file description
+//
+// Every supported hash listed must match, and at least one must be listed
+// unless `optional` is set for metadata files pinned by version alone.
+func checkFile(data []byte, length int64, hashes map[string]string, optional bool) error {
+	if length != 0 && int64(len(data)) != length {
+		return ErrLengthMismatch
+	}
+
+	if optional && len(hashes) == 0 {
+		return nil
+	}
+
+	checked := false
+
+	for _, f := range hashFuncs {
+		want, ok := hashes[f.name]
+
+		if !ok {
+			continue
+		}
+
+		if hex.EncodeToString(f.sum(data)) != want {
+			return ErrHashMismatch
+		}
+
+		checked = true
+	}
+
+	if !checked {
+		return ErrNoSupportedHash
+	}
+
+	return nil
+}
+
+// Preferred supported hash of a file description, names consistent snapshot
+// target files
+func preferredHash(hashes map[string]string) (string, error) {
+	for _, f := range hashFuncs {
+		if h, ok := hashes[f.name]; ok {
+			return h, nil
+		}
+	}
+
+	return "", ErrNoSupportedHash
+}
+
+func fileInfo(data []byte) (int64, map[string]string) {
+	h := sha256.Sum256(data)
+
+	return int64(len(data)), map[string]string{"sha256": hex.EncodeToString(h[:])}
+}
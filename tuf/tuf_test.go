@@ -0,0 +1,282 @@
+package tuf_test
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	slhdsa "github.com/skuuzie/go-slhdsa"
+	"github.com/skuuzie/go-slhdsa/tuf"
+)
+
+var paramSet = slhdsa.ParameterSet.SLHDSA_SHA2_128f
+
+func newSigner(t *testing.T) *tuf.Signer {
+	s, err := tuf.GenerateSigner(paramSet)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func roleSigners(t *testing.T) map[string][]*tuf.Signer {
+	return map[string][]*tuf.Signer{
+		tuf.RoleRoot:      {newSigner(t)},
+		tuf.RoleTargets:   {newSigner(t)},
+		tuf.RoleSnapshot:  {newSigner(t)},
+		tuf.RoleTimestamp: {newSigner(t)},
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	got, err := tuf.CanonicalJSON(map[string]any{
+		"b": []any{1, true, nil},
+		"a": "quote \" and \\ and\nnewline",
+		"c": map[string]int{"z": 1, "y": -2},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "{\"a\":\"quote \\\" and \\\\ and\nnewline\",\"b\":[1,true,null],\"c\":{\"y\":-2,\"z\":1}}"
+
+	if string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if _, err := tuf.CanonicalJSON(1.5); !errors.Is(err, tuf.ErrNotCanonical) {
+		t.Errorf("expected ErrNotCanonical for a float, got %v", err)
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	s1, s2, s3 := newSigner(t), newSigner(t), newSigner(t)
+	root := &tuf.Root{Type: tuf.RoleRoot, Version: 1}
+	root.SetRole(tuf.RoleTargets, 2, s1.Key, s2.Key)
+
+	targets := &tuf.Targets{Type: tuf.RoleTargets, Version: 1, Targets: map[string]tuf.TargetFile{}}
+
+	for _, tc := range []struct {
+		signers []*tuf.Signer
+		ok      bool
+	}{
+		{[]*tuf.Signer{s1}, false},
+		{[]*tuf.Signer{s1, s1}, false},
+		{[]*tuf.Signer{s1, s3}, false},
+		{[]*tuf.Signer{s1, s2}, true},
+	} {
+		md, err := tuf.Sign(targets, tc.signers...)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = md.VerifyRole(root, tuf.RoleTargets)
+
+		if tc.ok != (err == nil) {
+			t.Errorf("%d signers: unexpected result %v", len(tc.signers), err)
+		}
+	}
+
+	md, _ := tuf.Sign(targets, s1, s2)
+
+	if err := md.VerifyRole(root, tuf.RoleSnapshot); !errors.Is(err, tuf.ErrUnknownRole) {
+		t.Errorf("expected ErrUnknownRole, got %v", err)
+	}
+
+	var snap tuf.Snapshot
+
+	if err := md.Decode(tuf.RoleSnapshot, &snap); !errors.Is(err, tuf.ErrWrongType) {
+		t.Errorf("expected ErrWrongType, got %v", err)
+	}
+}
+
+func TestUpdateCycle(t *testing.T) {
+	dir := t.TempDir()
+	expires := time.Now().Add(24 * time.Hour)
+
+	repo, err := tuf.NewRepository(dir, roleSigners(t), 1, expires, true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo.AddTarget("app/v1.bin", []byte("version 1"))
+
+	if err := repo.Publish(expires); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+
+	trusted, _ := os.ReadFile(filepath.Join(dir, "root.json"))
+	client, err := tuf.NewClient(trusted, srv.URL)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := client.Download("app/v1.bin"); err != nil || string(data) != "version 1" {
+		t.Fatalf("unexpected target %q %v", data, err)
+	}
+
+	// New release with rotated root, targets and timestamp keys
+	repo.AddTarget("app/v2.bin", []byte("version 2"))
+
+	if err := repo.RotateRoot(roleSigners(t), 1, expires); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Publish(expires); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	if client.Root().Version != 2 {
+		t.Errorf("root not rotated, version %d", client.Root().Version)
+	}
+
+	if data, err := client.Download("app/v2.bin"); err != nil || string(data) != "version 2" {
+		t.Fatalf("unexpected target %q %v", data, err)
+	}
+
+	if _, err := client.Download("app/v3.bin"); !errors.Is(err, tuf.ErrTargetNotFound) {
+		t.Errorf("expected ErrTargetNotFound, got %v", err)
+	}
+
+	// Tampered target content
+	tf, _ := client.Target("app/v1.bin")
+	os.WriteFile(filepath.Join(dir, "targets", "app", tf.Hashes["sha256"]+".v1.bin"), []byte("version X"), 0o644)
+
+	if _, err := client.Download("app/v1.bin"); !errors.Is(err, tuf.ErrHashMismatch) {
+		t.Errorf("expected ErrHashMismatch, got %v", err)
+	}
+
+	// Replaying an older timestamp is a rollback
+	old, _ := os.ReadFile(filepath.Join(dir, "timestamp.json"))
+
+	if err := repo.Publish(expires); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "timestamp.json"), old, 0o644)
+
+	if err := client.Update(); !errors.Is(err, tuf.ErrRollback) {
+		t.Errorf("expected ErrRollback, got %v", err)
+	}
+
+	// Metadata past its expiry is refused
+	client.Now = func() time.Time { return expires.Add(time.Hour) }
+
+	if err := client.Update(); !errors.Is(err, tuf.ErrExpired) {
+		t.Errorf("expected ErrExpired, got %v", err)
+	}
+}
+
+func TestTargetHashes(t *testing.T) {
+	dir := t.TempDir()
+	expires := time.Now().Add(24 * time.Hour)
+
+	repo, err := tuf.NewRepository(dir, roleSigners(t), 1, expires, true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+
+	trusted, _ := os.ReadFile(filepath.Join(dir, "root.json"))
+	client, err := tuf.NewClient(trusted, srv.URL)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha512.Sum512([]byte("version 1"))
+	sha512Hex := hex.EncodeToString(sum[:])
+
+	for _, tc := range []struct {
+		name    string
+		hashes  map[string]string
+		content string
+		want    error
+	}{
+		{"sha512 only", map[string]string{"sha512": sha512Hex}, "version 1", nil},
+		{"sha512 mismatch", map[string]string{"sha512": sha512Hex}, "version X", tuf.ErrHashMismatch},
+		{"unsupported hash", map[string]string{"md5": "d41d8cd98f00b204e9800998ecf8427e"}, "version 1", tuf.ErrNoSupportedHash},
+		{"no hashes", nil, "version 1", tuf.ErrNoSupportedHash},
+	} {
+		repo.Targets.Targets["app/v1.bin"] = tuf.TargetFile{Length: 9, Hashes: tc.hashes}
+		os.MkdirAll(filepath.Join(dir, "targets", "app"), 0o755)
+		os.WriteFile(filepath.Join(dir, "targets", "app", sha512Hex+".v1.bin"), []byte(tc.content), 0o644)
+
+		if err := repo.Publish(expires); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := client.Update(); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := client.Download("app/v1.bin"); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, err)
+		}
+	}
+}
+
+func TestRootRotationRequiresOldKeys(t *testing.T) {
+	dir := t.TempDir()
+	expires := time.Now().Add(time.Hour)
+	repo, err := tuf.NewRepository(dir, roleSigners(t), 1, expires, false)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trusted, _ := os.ReadFile(filepath.Join(dir, "root.json"))
+
+	// A root signed only by the new keys must not be accepted
+	signers := roleSigners(t)
+	repo.Signers[tuf.RoleRoot] = nil
+
+	if err := repo.RotateRoot(signers, 1, expires); err != nil {
+		t.Fatal(err)
+	}
+
+	repo.Publish(expires)
+
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+
+	client, err := tuf.NewClient(trusted, srv.URL)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Update(); !errors.Is(err, tuf.ErrThreshold) {
+		t.Errorf("expected ErrThreshold, got %v", err)
+	}
+}